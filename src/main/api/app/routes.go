@@ -3,6 +3,7 @@ package app
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/jopitnow/go-jopit-toolkit/goauth"
+	"github.com/jopitnow/jopit-api-etl/src/main/api/config"
 	"github.com/jopitnow/jopit-api-etl/src/main/api/dependencies"
 )
 
@@ -15,14 +16,57 @@ func RouterMapper(router *gin.Engine, h dependencies.HandlersStruct) {
 	router.PUT("/etl/company-layout", goauth.AuthWithFirebase(), h.CompanyLayout.Update)
 	router.DELETE("/etl/company-layout", goauth.AuthWithFirebase(), h.CompanyLayout.Delete)
 
+	// API and CSV ETL
+	router.POST("/etl/api/load", goauth.AuthWithFirebase(), h.Etl.LoadApi)
+	router.POST("/etl/csv/load", goauth.AuthWithFirebase(), h.Etl.LoadCsv)
+	router.DELETE("/etl/batch/:id", goauth.AuthWithFirebase(), h.Etl.Delete)
+	router.POST("/etl/batch/:id/rollback", goauth.AuthWithFirebase(), h.Etl.Rollback)
+
 	// MercadoLibre Credentials
 	router.GET("/etl/mercadolibre/oauth", goauth.AuthWithFirebase(), h.MercadoLibreCredentials.GetOAuthURL)
 	router.POST("/etl/mercadolibre/oauth", goauth.AuthWithFirebase(), h.MercadoLibreCredentials.CreateOAuthCredentials)
 	//router.GET("/etl/mercadolibre/credentials", goauth.AuthWithFirebase(), h.MercadoLibreCredentials.GetCredentials)
 	//router.DELETE("/etl/mercadolibre/credentials", goauth.AuthWithFirebase(), h.MercadoLibreCredentials.DeleteCredentials)
 
+	// MercadoLibre Notifications, called by MercadoLibre so it is not behind Firebase auth
+	router.POST("/etl/mercadolibre/notifications", h.MercadoLibreNotifications.Receive)
+
 	// MercadoLibre ETL
 	router.POST("/etl/mercadolibre/load", goauth.AuthWithFirebase(), h.Etl.LoadMercadoLibre)
+	router.POST("/etl/mercadolibre/preview", goauth.AuthWithFirebase(), h.Etl.PreviewMercadoLibre)
 	router.GET("/etl/mercadolibre/item/:item_id", goauth.AuthWithFirebase(), h.Etl.GetMercadoLibreItem)
 	router.GET("/etl/mercadolibre/items", goauth.AuthWithFirebase(), h.Etl.GetMercadoLibreItems)
+
+	// ETL Jobs
+	router.GET("/etl/jobs", goauth.AuthWithFirebase(), h.Etl.GetJobs)
+	router.GET("/etl/jobs/:id", goauth.AuthWithFirebase(), h.Etl.GetJob)
+	router.GET("/etl/jobs/:id/events", goauth.AuthWithFirebase(), h.Etl.GetJobEvents)
+	router.DELETE("/etl/jobs/:id", goauth.AuthWithFirebase(), h.Etl.CancelJob)
+
+	// ETL Run Artifacts
+	router.GET("/etl/artifacts/:batch_id/:kind", goauth.AuthWithFirebase(), h.EtlArtifacts.Download)
+
+	// ETL Settings
+	router.GET("/etl/settings", goauth.AuthWithFirebase(), h.EtlSettings.Get)
+	router.PUT("/etl/settings", goauth.AuthWithFirebase(), h.EtlSettings.Update)
+
+	// Category Mappings, the global ones are shared by every shop and only managed by the admin
+	router.POST("/etl/category-mappings", goauth.AuthWithFirebase(), h.CategoryMappings.Create)
+	router.GET("/etl/category-mappings", goauth.AuthWithFirebase(), h.CategoryMappings.GetByUserID)
+	router.GET("/etl/category-mappings/suggestions", goauth.AuthWithFirebase(), h.CategoryMappings.Suggest)
+	router.PUT("/etl/category-mappings/:id", goauth.AuthWithFirebase(), h.CategoryMappings.Update)
+	router.DELETE("/etl/category-mappings/:id", goauth.AuthWithFirebase(), h.CategoryMappings.Delete)
+
+	admin := router.Group("/etl/admin", gin.BasicAuth(gin.Accounts{config.ConfMap.AdminUsername: config.ConfMap.AdminPassword}))
+	admin.POST("/category-mappings", h.CategoryMappings.CreateGlobal)
+	admin.GET("/category-mappings", h.CategoryMappings.GetGlobal)
+	admin.PUT("/category-mappings/:id", h.CategoryMappings.UpdateGlobal)
+	admin.DELETE("/category-mappings/:id", h.CategoryMappings.DeleteGlobal)
+
+	// ETL Schedules
+	router.POST("/etl/schedules", goauth.AuthWithFirebase(), h.EtlSchedules.Create)
+	router.GET("/etl/schedules", goauth.AuthWithFirebase(), h.EtlSchedules.GetByUserID)
+	router.GET("/etl/schedules/:id", goauth.AuthWithFirebase(), h.EtlSchedules.Get)
+	router.PUT("/etl/schedules/:id", goauth.AuthWithFirebase(), h.EtlSchedules.Update)
+	router.DELETE("/etl/schedules/:id", goauth.AuthWithFirebase(), h.EtlSchedules.Delete)
 }
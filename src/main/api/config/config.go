@@ -20,25 +20,45 @@ const (
 )
 
 var (
-	InternalBaseItemsClient = "http://jopit-api-items:8080"
-	InternalBaseShopsClient = "http://localhost:8081"
+	InternalBaseItemsClient  = "http://jopit-api-items:8080"
+	InternalBaseShopsClient  = "http://localhost:8081"
+	InternalBasePricesClient = "http://jopit-api-prices:8080"
 )
 
 // Configuration structure
 type Configuration struct {
-	APIRestServerHost        string `mapstructure:"jopit_api_host"`
-	APIRestServerPort        string `mapstructure:"jopit_api_port"`
-	APIRestUsername          string `mapstructure:"jopit_api_username"`
-	APIRestPassword          string `mapstructure:"jopit_api_password"`
-	APIBaseEndpoint          string `mapstructure:"jopit_api_base_endpoint"`
-	LoggingPath              string `mapstructure:"jopit_api_logpath"`
-	LoggingFile              string `mapstructure:"jopit_api_logfile"`
-	LoggingLevel             string `mapstructure:"jopit_api_loglevel"`
-	MongoConnectionString    string `mapstructure:"MONGODB_CONN_STRING"`
-	MercadolibreClientId     string `mapstructure:"MERCADOLIBRE_CLIENT_ID"`
-	MercadolibreClientSecret string `mapstructure:"MERCADOLIBRE_CLIENT_SECRET"`
-	AdminPassword            string
-	AdminUsername            string
+	APIRestServerHost           string  `mapstructure:"jopit_api_host"`
+	APIRestServerPort           string  `mapstructure:"jopit_api_port"`
+	APIRestUsername             string  `mapstructure:"jopit_api_username"`
+	APIRestPassword             string  `mapstructure:"jopit_api_password"`
+	APIBaseEndpoint             string  `mapstructure:"jopit_api_base_endpoint"`
+	LoggingPath                 string  `mapstructure:"jopit_api_logpath"`
+	LoggingFile                 string  `mapstructure:"jopit_api_logfile"`
+	LoggingLevel                string  `mapstructure:"jopit_api_loglevel"`
+	MongoConnectionString       string  `mapstructure:"MONGODB_CONN_STRING"`
+	MercadolibreClientId        string  `mapstructure:"MERCADOLIBRE_CLIENT_ID"`
+	MercadolibreClientSecret    string  `mapstructure:"MERCADOLIBRE_CLIENT_SECRET"`
+	MeliCallbackURL             string  `mapstructure:"jopit_meli_callback_url"`
+	EtlJobWorkers               int     `mapstructure:"jopit_etl_job_workers"`
+	EtlJobQueueSize             int     `mapstructure:"jopit_etl_job_queue_size"`
+	MeliNotificationWorkers     int     `mapstructure:"jopit_meli_notification_workers"`
+	MeliNotificationQueue       int     `mapstructure:"jopit_meli_notification_queue_size"`
+	EtlSchedulerPollSeconds     int     `mapstructure:"jopit_etl_scheduler_poll_seconds"`
+	EtlScheduleJitterSeconds    int     `mapstructure:"jopit_etl_schedule_jitter_seconds"`
+	EtlArtifactsPath            string  `mapstructure:"jopit_etl_artifacts_path"`
+	EtlArtifactsRetentionDays   int     `mapstructure:"jopit_etl_artifacts_retention_days"`
+	EtlFxRatesPath              string  `mapstructure:"jopit_etl_fx_rates_path"`
+	MeliDescriptionWorkers      int     `mapstructure:"jopit_meli_description_workers"`
+	MeliCategoryCacheHours      int     `mapstructure:"jopit_meli_category_cache_hours"`
+	MeliSizeChartCacheHours     int     `mapstructure:"jopit_meli_size_chart_cache_hours"`
+	MeliMultigetWorkers         int     `mapstructure:"jopit_meli_multiget_workers"`
+	MeliAppRequestsPerSecond    float64 `mapstructure:"jopit_meli_app_requests_per_second"`
+	MeliSellerRequestsPerSecond float64 `mapstructure:"jopit_meli_seller_requests_per_second"`
+	MeliMaxRetries              int     `mapstructure:"jopit_meli_max_retries"`
+	MeliRetryBaseMillis         int     `mapstructure:"jopit_meli_retry_base_millis"`
+	MeliRetryMaxSeconds         int     `mapstructure:"jopit_meli_retry_max_seconds"`
+	AdminPassword               string
+	AdminUsername               string
 }
 
 // ConfMap Config is package struct containing conf params
@@ -57,6 +77,42 @@ func Load() {
 	viper.SetDefault("jopit_api_logfile", "jopit_api.log")
 	viper.SetDefault("jopit_api_loglevel", "trace")
 
+	// ETL JOBS
+	viper.SetDefault("jopit_etl_job_workers", 4)
+	viper.SetDefault("jopit_etl_job_queue_size", 100)
+
+	// MERCADOLIBRE OAUTH, no callback url sends sellers back to the Jopit site of the environment
+	viper.SetDefault("jopit_meli_callback_url", "")
+
+	// MERCADOLIBRE NOTIFICATIONS
+	viper.SetDefault("jopit_meli_notification_workers", 2)
+	viper.SetDefault("jopit_meli_notification_queue_size", 500)
+
+	// ETL SCHEDULES
+	viper.SetDefault("jopit_etl_scheduler_poll_seconds", 30)
+	viper.SetDefault("jopit_etl_schedule_jitter_seconds", 300)
+
+	// ETL ARTIFACTS
+	viper.SetDefault("jopit_etl_artifacts_path", "/var/lib/jopit/etl-artifacts")
+	// Runs older than this can no longer be downloaded or rolled back, 0 keeps them forever
+	viper.SetDefault("jopit_etl_artifacts_retention_days", 30)
+
+	// ETL CURRENCIES, no rates file leaves imported prices in their own currency
+	viper.SetDefault("jopit_etl_fx_rates_path", "")
+
+	// MERCADOLIBRE EXTRACTION
+	viper.SetDefault("jopit_meli_description_workers", 8)
+	viper.SetDefault("jopit_meli_category_cache_hours", 720)
+	viper.SetDefault("jopit_meli_size_chart_cache_hours", 168)
+	viper.SetDefault("jopit_meli_multiget_workers", 4)
+
+	// MERCADOLIBRE RATE LIMITS
+	viper.SetDefault("jopit_meli_app_requests_per_second", 25)
+	viper.SetDefault("jopit_meli_seller_requests_per_second", 10)
+	viper.SetDefault("jopit_meli_max_retries", 4)
+	viper.SetDefault("jopit_meli_retry_base_millis", 500)
+	viper.SetDefault("jopit_meli_retry_max_seconds", 30)
+
 	// Read the config file
 	viper.AutomaticEnv()
 
@@ -1,15 +1,27 @@
 package dependencies
 
 import (
+	"time"
+
+	"github.com/jopitnow/jopit-api-etl/src/main/api/config"
 	"github.com/jopitnow/jopit-api-etl/src/main/domain/clients"
 	"github.com/jopitnow/jopit-api-etl/src/main/domain/handlers"
 	"github.com/jopitnow/jopit-api-etl/src/main/domain/repositories"
 	"github.com/jopitnow/jopit-api-etl/src/main/domain/services"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/utils"
 )
 
 type Dependencies interface {
 	CompanyLayoutRepository() repositories.CompanyLayoutRepository
 	MercadoLibreCredentialsRepository() repositories.MercadoLibreCredentialsRepository
+	EtlJobsRepository() repositories.EtlJobsRepository
+	SyncCursorsRepository() repositories.SyncCursorsRepository
+	EtlSchedulesRepository() repositories.EtlSchedulesRepository
+	EtlLocksRepository() repositories.EtlLocksRepository
+	EtlSettingsRepository() repositories.EtlSettingsRepository
+	CategoryMappingsRepository() repositories.CategoryMappingsRepository
+	MeliCategoriesRepository() repositories.MeliCategoriesRepository
+	MeliSizeChartsRepository() repositories.MeliSizeChartsRepository
 }
 
 func GetDependencyManager() Dependencies {
@@ -22,34 +34,78 @@ func BuildDependencies() (HandlersStruct, error) {
 
 	caompanyLayoutRepository := manager.CompanyLayoutRepository()
 	mercadoLibreCredentialsRepository := manager.MercadoLibreCredentialsRepository()
+	etlJobsRepository := manager.EtlJobsRepository()
+	syncCursorsRepository := manager.SyncCursorsRepository()
+	etlSchedulesRepository := manager.EtlSchedulesRepository()
+	etlLocksRepository := manager.EtlLocksRepository()
+	etlSettingsRepository := manager.EtlSettingsRepository()
+	categoryMappingsRepository := manager.CategoryMappingsRepository()
+	meliCategoriesRepository := manager.MeliCategoriesRepository()
+	meliSizeChartsRepository := manager.MeliSizeChartsRepository()
 
 	// External Clients
 	fetchApiClient := clients.FetchApiClientInstance
 	itemsClient := clients.ItemsClientInstance
+	pricesClient := clients.PricesClientInstance
 	shopsClient := clients.ShopsClientInstance
 	mercadoLibreAuthClient := clients.MercadoLibreAuthClientInstance
 	mercadoLibreClient := clients.MercadoLibreClientInstance
 
+	// Storage
+	artifactStore := repositories.NewLocalArtifactStore(config.ConfMap.EtlArtifactsPath)
+
+	// Exchange rates, prices are only converted when a rates file is configured
+	var fxRates utils.FxRateProvider
+	if config.ConfMap.EtlFxRatesPath != "" {
+		var err error
+		if fxRates, err = utils.NewStaticFxRateProvider(config.ConfMap.EtlFxRatesPath); err != nil {
+			return HandlersStruct{}, err
+		}
+	}
+
 	// Services
 	mercadoLibreCredentialsService := services.NewMercadoLibreCredentialsService(mercadoLibreCredentialsRepository, shopsClient, mercadoLibreAuthClient)
-	mercadoLibreService := services.NewMercadoLibreService(mercadoLibreClient, mercadoLibreCredentialsService)
-	etlService := services.NewEtlService(fetchApiClient, itemsClient, shopsClient, mercadoLibreService)
+	mercadoLibreService := services.NewMercadoLibreService(mercadoLibreClient, mercadoLibreCredentialsService, config.ConfMap.MeliDescriptionWorkers, config.ConfMap.MeliMultigetWorkers)
 	companyLayoutService := services.NewCompanyLayoutService(caompanyLayoutRepository, shopsClient)
+	etlArtifactsService := services.NewEtlArtifactsService(artifactStore, shopsClient, time.Duration(config.ConfMap.EtlArtifactsRetentionDays)*24*time.Hour)
+	etlSettingsService := services.NewEtlSettingsService(etlSettingsRepository, shopsClient)
+	meliCategoriesService := services.NewMeliCategoriesService(meliCategoriesRepository, mercadoLibreClient, time.Duration(config.ConfMap.MeliCategoryCacheHours)*time.Hour)
+	meliSizeChartsService := services.NewMeliSizeChartsService(meliSizeChartsRepository, mercadoLibreClient, time.Duration(config.ConfMap.MeliSizeChartCacheHours)*time.Hour)
+	categoryMappingsService := services.NewCategoryMappingsService(categoryMappingsRepository, shopsClient, itemsClient, meliCategoriesService)
+	etlService := services.NewEtlService(fetchApiClient, itemsClient, pricesClient, shopsClient, mercadoLibreService, companyLayoutService, syncCursorsRepository, etlArtifactsService, etlLocksRepository, etlSettingsService, categoryMappingsService, meliSizeChartsService, fxRates)
+	etlJobsService := services.NewEtlJobsService(etlJobsRepository, etlLocksRepository, etlService, shopsClient, config.ConfMap.EtlJobWorkers, config.ConfMap.EtlJobQueueSize)
+	etlSchedulesService := services.NewEtlSchedulesService(etlSchedulesRepository, etlJobsService, shopsClient, time.Duration(config.ConfMap.EtlSchedulerPollSeconds)*time.Second, time.Duration(config.ConfMap.EtlScheduleJitterSeconds)*time.Second)
+	mercadoLibreNotificationsService := services.NewMercadoLibreNotificationsService(mercadoLibreCredentialsService, etlService, config.ConfMap.MercadolibreClientId, config.ConfMap.MeliNotificationWorkers, config.ConfMap.MeliNotificationQueue)
 
 	// Handlers
-	etlHandler := handlers.NewEtlsHandler(etlService, mercadoLibreService)
+	etlHandler := handlers.NewEtlsHandler(etlService, etlJobsService, mercadoLibreService)
 	companyLayoutHandler := handlers.NewCompanyLayoutHandler(companyLayoutService)
 	mercadoLibreCredentialsHandler := handlers.NewMercadoLibreCredentialsHandler(mercadoLibreCredentialsService)
+	mercadoLibreNotificationsHandler := handlers.NewMercadoLibreNotificationsHandler(mercadoLibreNotificationsService)
+	etlSchedulesHandler := handlers.NewEtlSchedulesHandler(etlSchedulesService)
+	etlArtifactsHandler := handlers.NewEtlArtifactsHandler(etlArtifactsService)
+	etlSettingsHandler := handlers.NewEtlSettingsHandler(etlSettingsService)
+	categoryMappingsHandler := handlers.NewCategoryMappingsHandler(categoryMappingsService)
 
 	return HandlersStruct{
-		Etl:                     etlHandler,
-		CompanyLayout:           companyLayoutHandler,
-		MercadoLibreCredentials: mercadoLibreCredentialsHandler,
+		Etl:                       etlHandler,
+		CompanyLayout:             companyLayoutHandler,
+		MercadoLibreCredentials:   mercadoLibreCredentialsHandler,
+		MercadoLibreNotifications: mercadoLibreNotificationsHandler,
+		EtlSchedules:              etlSchedulesHandler,
+		EtlArtifacts:              etlArtifactsHandler,
+		EtlSettings:               etlSettingsHandler,
+		CategoryMappings:          categoryMappingsHandler,
 	}, nil
 }
 
 type HandlersStruct struct {
-	Etl                     handlers.EtlHandler
-	CompanyLayout           handlers.CompanyLayoutHandler
-	MercadoLibreCredentials handlers.MercadoLibreCredentialsHandler
+	Etl                       handlers.EtlHandler
+	CompanyLayout             handlers.CompanyLayoutHandler
+	MercadoLibreCredentials   handlers.MercadoLibreCredentialsHandler
+	MercadoLibreNotifications handlers.MercadoLibreNotificationsHandler
+	EtlSchedules              handlers.EtlSchedulesHandler
+	EtlArtifacts              handlers.EtlArtifactsHandler
+	EtlSettings               handlers.EtlSettingsHandler
+	CategoryMappings          handlers.CategoryMappingsHandler
 }
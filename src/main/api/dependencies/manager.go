@@ -1,6 +1,9 @@
 package dependencies
 
 import (
+	"time"
+
+	"github.com/jopitnow/jopit-api-etl/src/main/api/config"
 	"github.com/jopitnow/jopit-api-etl/src/main/api/platform/storage"
 	"github.com/jopitnow/jopit-api-etl/src/main/domain/repositories"
 
@@ -10,6 +13,14 @@ import (
 const (
 	KvsCompanyLayoutCollection = "company-layout"
 	KvsMercadoLibreCredentials = "mercadolibre-credentials"
+	KvsEtlJobsCollection       = "etl-jobs"
+	KvsSyncCursorsCollection   = "sync-cursors"
+	KvsEtlSchedulesCollection  = "etl-schedules"
+	KvsEtlLocksCollection      = "etl-locks"
+	KvsEtlSettingsCollection   = "etl-settings"
+	KvsCategoryMappings        = "category-mappings"
+	KvsMeliCategories          = "meli-categories"
+	KvsMeliSizeCharts          = "meli-size-charts"
 )
 
 type DependencyManager struct {
@@ -33,3 +44,41 @@ func (m DependencyManager) CompanyLayoutRepository() repositories.CompanyLayoutR
 func (m DependencyManager) MercadoLibreCredentialsRepository() repositories.MercadoLibreCredentialsRepository {
 	return repositories.NewMercadoLibreCredentialsRepository(m.NewCollection(KvsMercadoLibreCredentials))
 }
+
+func (m DependencyManager) EtlJobsRepository() repositories.EtlJobsRepository {
+	return repositories.NewEtlJobsRepository(m.NewCollection(KvsEtlJobsCollection))
+}
+
+func (m DependencyManager) SyncCursorsRepository() repositories.SyncCursorsRepository {
+	return repositories.NewSyncCursorsRepository(m.NewCollection(KvsSyncCursorsCollection))
+}
+
+func (m DependencyManager) EtlSchedulesRepository() repositories.EtlSchedulesRepository {
+	return repositories.NewEtlSchedulesRepository(m.NewCollection(KvsEtlSchedulesCollection))
+}
+
+func (m DependencyManager) EtlLocksRepository() repositories.EtlLocksRepository {
+	return repositories.NewEtlLocksRepository(m.NewCollection(KvsEtlLocksCollection))
+}
+
+func (m DependencyManager) EtlSettingsRepository() repositories.EtlSettingsRepository {
+	return repositories.NewEtlSettingsRepository(m.NewCollection(KvsEtlSettingsCollection))
+}
+
+func (m DependencyManager) CategoryMappingsRepository() repositories.CategoryMappingsRepository {
+	return repositories.NewCategoryMappingsRepository(m.NewCollection(KvsCategoryMappings))
+}
+
+func (m DependencyManager) MeliCategoriesRepository() repositories.MeliCategoriesRepository {
+	return repositories.NewMeliCategoriesRepository(m.NewCollection(KvsMeliCategories), meliCacheExpiry(config.ConfMap.MeliCategoryCacheHours))
+}
+
+func (m DependencyManager) MeliSizeChartsRepository() repositories.MeliSizeChartsRepository {
+	return repositories.NewMeliSizeChartsRepository(m.NewCollection(KvsMeliSizeCharts), meliCacheExpiry(config.ConfMap.MeliSizeChartCacheHours))
+}
+
+// meliCacheExpiry keeps cached MercadoLibre entries a few refresh periods past their ttl, the services fall back on
+// stale entries while MercadoLibre can't be reached
+func meliCacheExpiry(cacheHours int) time.Duration {
+	return 3 * time.Duration(cacheHours) * time.Hour
+}
@@ -23,7 +23,10 @@ var (
 )
 
 const (
-	GetIntegrity = "/items/list" //to-do
+	GetIntegrity        = "/items/list" //to-do
+	GetItemsByShopIDUrl = "/items/shop/%s"
+	GetItemBySourceUrl  = "/items/shop/%s/source/%s/%s"
+	GetCategoriesUrl    = "/items/categories"
 )
 
 type itemsClient struct {
@@ -33,7 +36,11 @@ type itemsClient struct {
 type ItemsClient interface {
 	BulkCreateItems(ctx context.Context, items []models.Item) apierrors.ApiError
 	BulkUpsertItems(ctx context.Context, items []models.Item) (*dto.BulkUpsertResponse, apierrors.ApiError)
-	BulkDeleteItems(ctx context.Context, batchID string) apierrors.ApiError
+	BulkDeleteItems(ctx context.Context, shopID string, batchID string) apierrors.ApiError
+	BulkDeleteItemsByID(ctx context.Context, shopID string, itemIDs []string) apierrors.ApiError
+	GetItemsByShopID(ctx context.Context, shopID string) ([]models.Item, apierrors.ApiError)
+	GetItemBySource(ctx context.Context, shopID string, sourceType string, externalID string) (*models.Item, apierrors.ApiError)
+	GetCategories(ctx context.Context) ([]models.ItemCategory, apierrors.ApiError)
 }
 
 func newItemsClient() *itemsClient {
@@ -114,8 +121,144 @@ func (c *itemsClient) BulkUpsertItems(ctx context.Context, items []models.Item)
 	return &upsertResponse, nil
 }
 
-func (c *itemsClient) BulkDeleteItems(ctx context.Context, batchID string) apierrors.ApiError {
+func (c *itemsClient) GetItemsByShopID(ctx context.Context, shopID string) ([]models.Item, apierrors.ApiError) {
+
+	ctx, span := tracerClientItems.Start(ctx, "GetItemsByShopID")
+	defer span.End()
+
+	headers := http.Header{}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(headers))
+
+	endpoint := fmt.Sprintf(GetItemsByShopIDUrl, shopID)
+	response := c.Client.Get(endpoint, rest.Context(ctx), rest.Headers(headers))
+
+	if response.Err != nil || response.Response == nil {
+		return nil, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprint("Unexpected error hitting items api, url: "+endpoint, "\nresponse: ", response), "error hitting Items Api", http.StatusInternalServerError, apierrors.CauseList{response}))
+	}
+
+	// A shop without items yet
+	if response.StatusCode == http.StatusNotFound {
+		return []models.Item{}, nil
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return nil, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprint("Unexpected error hitting items api, url: "+endpoint, "\nresponse: ", response), "error hitting Items Api", http.StatusInternalServerError, apierrors.CauseList{response}))
+	}
+
+	var items models.Items
+	if err := response.FillUp(&items); err != nil {
+		return nil, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError("error parsing response: "+err.Error(), "internal_error", http.StatusInternalServerError, apierrors.CauseList{}))
+	}
+
+	return items.Items, nil
+}
+
+// GetItemBySource returns the shop's item imported from the given source item, nil when there is none
+func (c *itemsClient) GetItemBySource(ctx context.Context, shopID string, sourceType string, externalID string) (*models.Item, apierrors.ApiError) {
+
+	ctx, span := tracerClientItems.Start(ctx, "GetItemBySource")
+	defer span.End()
+
+	headers := http.Header{}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(headers))
+
+	endpoint := fmt.Sprintf(GetItemBySourceUrl, shopID, sourceType, externalID)
+	response := c.Client.Get(endpoint, rest.Context(ctx), rest.Headers(headers))
+
+	if response.Err != nil || response.Response == nil {
+		return nil, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprint("Unexpected error hitting items api, url: "+endpoint, "\nresponse: ", response), "error hitting Items Api", http.StatusInternalServerError, apierrors.CauseList{response}))
+	}
+
+	// Not imported yet
+	if response.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return nil, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprint("Unexpected error hitting items api, url: "+endpoint, "\nresponse: ", response), "error hitting Items Api", http.StatusInternalServerError, apierrors.CauseList{response}))
+	}
+
+	var item models.Item
+	if err := response.FillUp(&item); err != nil {
+		return nil, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError("error parsing response: "+err.Error(), "internal_error", http.StatusInternalServerError, apierrors.CauseList{}))
+	}
+
+	return &item, nil
+}
+
+func (c *itemsClient) BulkDeleteItems(ctx context.Context, shopID string, batchID string) apierrors.ApiError {
+
+	ctx, span := tracerClientItems.Start(ctx, "BulkDeleteItems")
+	defer span.End()
+
+	headers := http.Header{}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(headers))
+
+	reqBody := dto.BulkDeleteItemsRequest{ShopID: shopID, BatchID: batchID}
+
+	endpoint := "/items/bulk-delete"
+	response := c.Client.Post(endpoint, reqBody, rest.Context(ctx), rest.Headers(headers))
+
+	if response.Err != nil || response.Response == nil {
+		return apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprint("Unexpected error hitting items api, url: "+endpoint, "\nresponse: ", response), "error hitting Items Api", http.StatusInternalServerError, apierrors.CauseList{response}))
+	}
+
+	if response.StatusCode == http.StatusNotFound {
+		return apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf("batch %s not found", batchID), "not_found", http.StatusNotFound, apierrors.CauseList{}))
+	}
+
+	if response.StatusCode != http.StatusOK && response.StatusCode != http.StatusNoContent {
+		return apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprint("Unexpected error hitting items api, url: "+endpoint, "\nresponse: ", response), "error hitting Items Api", http.StatusInternalServerError, apierrors.CauseList{response}))
+	}
 
 	return nil
+}
+
+// BulkDeleteItemsByID deletes items of a shop by id, ids that no longer exist are ignored
+func (c *itemsClient) BulkDeleteItemsByID(ctx context.Context, shopID string, itemIDs []string) apierrors.ApiError {
+
+	ctx, span := tracerClientItems.Start(ctx, "BulkDeleteItemsByID")
+	defer span.End()
+
+	headers := http.Header{}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(headers))
+
+	reqBody := dto.BulkDeleteItemsByIDRequest{ShopID: shopID, ItemsIDs: itemIDs}
+
+	endpoint := "/items/bulk-delete-by-id"
+	response := c.Client.Post(endpoint, reqBody, rest.Context(ctx), rest.Headers(headers))
+
+	if response.Err != nil || response.Response == nil {
+		return apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprint("Unexpected error hitting items api, url: "+endpoint, "\nresponse: ", response), "error hitting Items Api", http.StatusInternalServerError, apierrors.CauseList{response}))
+	}
+
+	if response.StatusCode != http.StatusOK && response.StatusCode != http.StatusNoContent {
+		return apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprint("Unexpected error hitting items api, url: "+endpoint, "\nresponse: ", response), "error hitting Items Api", http.StatusInternalServerError, apierrors.CauseList{response}))
+	}
+
+	return nil
+}
+
+// GetCategories returns the Jopit categories items can be published under
+func (c *itemsClient) GetCategories(ctx context.Context) ([]models.ItemCategory, apierrors.ApiError) {
+
+	ctx, span := tracerClientItems.Start(ctx, "GetCategories")
+	defer span.End()
+
+	headers := http.Header{}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(headers))
+
+	endpoint := GetCategoriesUrl
+	response := c.Client.Get(endpoint, rest.Context(ctx), rest.Headers(headers))
+
+	if response.Err != nil || response.Response == nil || response.StatusCode != http.StatusOK {
+		return nil, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprint("Unexpected error hitting items api, url: "+endpoint, "\nresponse: ", response), "error hitting Items Api", http.StatusInternalServerError, apierrors.CauseList{response}))
+	}
+
+	var categories dto.CategoriesDTO
+	if err := response.FillUp(&categories); err != nil {
+		return nil, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError("error parsing response: "+err.Error(), "internal_error", http.StatusInternalServerError, apierrors.CauseList{}))
+	}
 
+	return categories.CategoryDTO, nil
 }
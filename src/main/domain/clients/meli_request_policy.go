@@ -0,0 +1,233 @@
+package clients
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jopitnow/go-jopit-toolkit/rest"
+	"github.com/jopitnow/jopit-api-etl/src/main/api/config"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/utils"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+var (
+	meliPolicyOnce sync.Once
+	meliPolicy     *meliRequestPolicy
+)
+
+// meliRequests is the policy every MercadoLibre call goes through, so the app and seller limits hold
+// across clients, workers and runs. It is built on first use, once the config is loaded.
+func meliRequests() *meliRequestPolicy {
+	meliPolicyOnce.Do(func() {
+		meliPolicy = newMeliRequestPolicy(
+			config.ConfMap.MeliAppRequestsPerSecond,
+			config.ConfMap.MeliSellerRequestsPerSecond,
+			config.ConfMap.MeliMaxRetries,
+			time.Duration(config.ConfMap.MeliRetryBaseMillis)*time.Millisecond,
+			time.Duration(config.ConfMap.MeliRetryMaxSeconds)*time.Second,
+		)
+	})
+	return meliPolicy
+}
+
+// meliRequestPolicy rate limits MercadoLibre calls per app and per seller, and retries throttled and transient
+// failures with exponential backoff and jitter, waiting what Retry-After asks for when MercadoLibre sends it
+type meliRequestPolicy struct {
+	appLimiter  *utils.TokenBucket
+	sellerRate  float64
+	sellersLock sync.Mutex
+	sellers     map[string]*utils.TokenBucket
+	maxRetries  int
+	baseDelay   time.Duration
+	maxDelay    time.Duration
+	metrics     meliRequestMetrics
+}
+
+type meliRequestMetrics struct {
+	requests  metric.Int64Counter
+	throttled metric.Int64Counter
+	retries   metric.Int64Counter
+	wait      metric.Float64Histogram
+}
+
+func newMeliRequestPolicy(appRate float64, sellerRate float64, maxRetries int, baseDelay time.Duration, maxDelay time.Duration) *meliRequestPolicy {
+	meter := otel.Meter("mercadolibre-client")
+
+	// Instruments failing to register are no-ops, metrics never fail a call
+	requests, _ := meter.Int64Counter("meli.requests", metric.WithDescription("MercadoLibre calls by operation and status"))
+	throttled, _ := meter.Int64Counter("meli.throttled", metric.WithDescription("MercadoLibre calls answered with 429"))
+	retries, _ := meter.Int64Counter("meli.retries", metric.WithDescription("MercadoLibre calls retried by operation and reason"))
+	wait, _ := meter.Float64Histogram("meli.rate_limit.wait", metric.WithDescription("Time calls waited for the rate limiter"), metric.WithUnit("s"))
+
+	return &meliRequestPolicy{
+		appLimiter: utils.NewTokenBucket(appRate),
+		sellerRate: sellerRate,
+		sellers:    map[string]*utils.TokenBucket{},
+		maxRetries: max(maxRetries, 0),
+		baseDelay:  max(baseDelay, time.Millisecond),
+		maxDelay:   max(maxDelay, baseDelay),
+		metrics: meliRequestMetrics{
+			requests:  requests,
+			throttled: throttled,
+			retries:   retries,
+			wait:      wait,
+		},
+	}
+}
+
+// Do runs call within the app limit and, when there is an access token, the limit of its seller. Network
+// errors, 429 and 5xx responses are retried; the last response is returned as is once retries run out.
+func (p *meliRequestPolicy) Do(ctx context.Context, operation string, accessToken string, call func() *rest.Response) *rest.Response {
+	return p.do(ctx, operation, accessToken, p.maxRetries, call)
+}
+
+// DoOnce runs call within the same limits as Do but never retries it, for calls that must not be sent twice
+// like the OAuth token exchanges, whose authorization codes and refresh tokens are single-use
+func (p *meliRequestPolicy) DoOnce(ctx context.Context, operation string, accessToken string, call func() *rest.Response) *rest.Response {
+	return p.do(ctx, operation, accessToken, 0, call)
+}
+
+func (p *meliRequestPolicy) do(ctx context.Context, operation string, accessToken string, maxRetries int, call func() *rest.Response) *rest.Response {
+	seller := p.sellerLimiter(accessToken)
+	operationAttr := attribute.String("operation", operation)
+
+	for attempt := 0; ; attempt++ {
+		if err := p.wait(ctx, operationAttr, seller); err != nil {
+			return &rest.Response{Err: err}
+		}
+
+		response := call()
+		status := 0
+		if response != nil && response.Response != nil {
+			status = response.StatusCode
+		}
+		p.metrics.requests.Add(ctx, 1, metric.WithAttributes(operationAttr, attribute.Int("status", status)))
+
+		if status == http.StatusTooManyRequests {
+			p.metrics.throttled.Add(ctx, 1, metric.WithAttributes(operationAttr))
+		}
+
+		if !isRetryableMeliResponse(response) || attempt >= maxRetries {
+			return response
+		}
+
+		delay, ok := p.retryDelay(response, attempt)
+		if !ok {
+			return response
+		}
+
+		// A 429 pauses every call sharing the limit, not just this one
+		if status == http.StatusTooManyRequests {
+			p.appLimiter.Pause(delay)
+			if seller != nil {
+				seller.Pause(delay)
+			}
+		}
+
+		p.metrics.retries.Add(ctx, 1, metric.WithAttributes(operationAttr, attribute.String("reason", retryReason(status))))
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return response
+		case <-timer.C:
+		}
+	}
+}
+
+func (p *meliRequestPolicy) wait(ctx context.Context, operationAttr attribute.KeyValue, seller *utils.TokenBucket) error {
+	delay := p.appLimiter.Reserve()
+	if seller != nil {
+		delay = max(delay, seller.Reserve())
+	}
+
+	if delay > 0 {
+		p.metrics.wait.Record(ctx, delay.Seconds(), metric.WithAttributes(operationAttr))
+
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	return ctx.Err()
+}
+
+// sellerLimiter returns the limiter of the seller owning the token. MercadoLibre tokens end with the seller's
+// user id, so refreshed tokens share their seller's limit.
+func (p *meliRequestPolicy) sellerLimiter(accessToken string) *utils.TokenBucket {
+	if accessToken == "" {
+		return nil
+	}
+
+	key := accessToken
+	if index := strings.LastIndex(accessToken, "-"); index >= 0 {
+		if _, err := strconv.ParseInt(accessToken[index+1:], 10, 64); err == nil {
+			key = accessToken[index+1:]
+		}
+	}
+
+	p.sellersLock.Lock()
+	defer p.sellersLock.Unlock()
+
+	limiter, ok := p.sellers[key]
+	if !ok {
+		limiter = utils.NewTokenBucket(p.sellerRate)
+		p.sellers[key] = limiter
+	}
+	return limiter
+}
+
+// retryDelay is what Retry-After asks for or else a jittered exponential backoff. ok is false when MercadoLibre
+// asks to wait longer than maxDelay.
+func (p *meliRequestPolicy) retryDelay(response *rest.Response, attempt int) (delay time.Duration, ok bool) {
+	retryAfter := ""
+	if response != nil && response.Response != nil {
+		retryAfter = response.Header.Get("Retry-After")
+	}
+	return utils.RetryDelay(retryAfter, time.Now(), attempt, p.baseDelay, p.maxDelay)
+}
+
+func isRetryableMeliResponse(response *rest.Response) bool {
+	if response == nil || response.Response == nil {
+		return true
+	}
+	return utils.IsRetryableStatus(response.StatusCode)
+}
+
+func retryReason(status int) string {
+	switch {
+	case status == 0:
+		return "network_error"
+	case status == http.StatusTooManyRequests:
+		return "throttled"
+	default:
+		return "server_error"
+	}
+}
+
+// meliFailureCode is the error code of a MercadoLibre call that still failed after its retries. Throttling is
+// told apart from MercadoLibre failing so callers can back off instead of giving up.
+func meliFailureCode(status int) string {
+	if status == http.StatusTooManyRequests {
+		return "too_many_requests"
+	}
+	return "bad_gateway"
+}
+
+func meliFailureStatus(status int) int {
+	if status == http.StatusTooManyRequests {
+		return http.StatusTooManyRequests
+	}
+	return http.StatusBadGateway
+}
@@ -19,6 +19,8 @@ import (
 
 const (
 	meliAPIBaseURL = "https://api.mercadolibre.com"
+	// MeliMultigetMaxIDs is the most ids MercadoLibre's /items?ids= multiget takes per call
+	MeliMultigetMaxIDs = 20
 )
 
 var (
@@ -28,11 +30,15 @@ var (
 
 type MercadoLibreClient interface {
 	GetItem(ctx context.Context, meliItemID string, accessToken string) (dto.MeliItemResponse, apierrors.ApiError)
-	GetItems(ctx context.Context, meliItemIDs []string, accessToken string) ([]dto.MeliItemResponse, apierrors.ApiError)
+	GetItems(ctx context.Context, meliItemIDs []string, accessToken string) ([]dto.MeliMultigetResult, apierrors.ApiError)
 	GetUserItems(ctx context.Context, meliUserID int64, accessToken string) (dto.MeliUserItemsSearchResponse, apierrors.ApiError)
 	GetUserItemsWithPagination(ctx context.Context, meliUserID int64, accessToken string, offset int, limit int) (dto.MeliUserItemsSearchResponse, apierrors.ApiError)
+	ScanUserItems(ctx context.Context, meliUserID int64, accessToken string, scrollID string, limit int) (dto.MeliUserItemsSearchResponse, apierrors.ApiError)
 	SearchItems(ctx context.Context, filters dto.MercadoLibreSearchFilters, accessToken string) (dto.MeliSearchResponse, apierrors.ApiError)
 	GetSizeChart(ctx context.Context, chartID string, accessToken string) (dto.MeliSizeChartResponse, apierrors.ApiError)
+	GetItemDescription(ctx context.Context, meliItemID string, accessToken string) (dto.MeliItemDescription, apierrors.ApiError)
+	GetItemPrices(ctx context.Context, meliItemID string, accessToken string) (dto.MeliItemPricesResponse, apierrors.ApiError)
+	GetCategory(ctx context.Context, categoryID string) (dto.MeliCategoryResponse, apierrors.ApiError)
 }
 
 type mercadoLibreClient struct {
@@ -72,15 +78,18 @@ func (c *mercadoLibreClient) GetItem(ctx context.Context, meliItemID string, acc
 		headers.Add("Authorization", "Bearer "+accessToken)
 	}
 
-	endpoint := fmt.Sprintf("/items/%s", meliItemID)
-	response := c.Builder.Get(endpoint, rest.Context(ctx), rest.Headers(headers))
+	// Variation attributes, which carry the seller's SKU, are only returned on request
+	endpoint := fmt.Sprintf("/items/%s?include_attributes=all", meliItemID)
+	response := meliRequests().Do(ctx, "GetItem", accessToken, func() *rest.Response {
+		return c.Builder.Get(endpoint, rest.Context(ctx), rest.Headers(headers))
+	})
 
 	if response.Response == nil {
 		return dto.MeliItemResponse{}, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError("unexpected error calling MercadoLibre items endpoint", "internal_server_error", http.StatusInternalServerError, apierrors.CauseList{}))
 	}
 
 	if response.StatusCode != http.StatusOK {
-		return dto.MeliItemResponse{}, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf("unexpected response from MercadoLibre items endpoint, status: %d", response.StatusCode), "bad_gateway", http.StatusBadGateway, apierrors.CauseList{response}))
+		return dto.MeliItemResponse{}, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf("unexpected response from MercadoLibre items endpoint, status: %d", response.StatusCode), meliFailureCode(response.StatusCode), meliFailureStatus(response.StatusCode), apierrors.CauseList{response}))
 	}
 
 	var item dto.MeliItemResponse
@@ -91,7 +100,8 @@ func (c *mercadoLibreClient) GetItem(ctx context.Context, meliItemID string, acc
 	return item, nil
 }
 
-func (c *mercadoLibreClient) GetItems(ctx context.Context, meliItemIDs []string, accessToken string) ([]dto.MeliItemResponse, apierrors.ApiError) {
+// GetItems fetches up to MeliMultigetMaxIDs items in one call, returning the outcome of every id in request order
+func (c *mercadoLibreClient) GetItems(ctx context.Context, meliItemIDs []string, accessToken string) ([]dto.MeliMultigetResult, apierrors.ApiError) {
 	ctx, span := tracerMeliClient.Start(ctx, "GetItems")
 	defer span.End()
 
@@ -99,6 +109,10 @@ func (c *mercadoLibreClient) GetItems(ctx context.Context, meliItemIDs []string,
 		return nil, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError("meli item ids are required", "bad_request", http.StatusBadRequest, apierrors.CauseList{}))
 	}
 
+	if len(meliItemIDs) > MeliMultigetMaxIDs {
+		return nil, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf("at most %d meli item ids per call", MeliMultigetMaxIDs), "bad_request", http.StatusBadRequest, apierrors.CauseList{}))
+	}
+
 	headers := http.Header{}
 	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(headers))
 	if accessToken != "" {
@@ -107,21 +121,25 @@ func (c *mercadoLibreClient) GetItems(ctx context.Context, meliItemIDs []string,
 
 	query := url.Values{}
 	query.Set("ids", strings.Join(meliItemIDs, ","))
+	query.Set("include_attributes", "all")
 	endpoint := fmt.Sprintf("/items?%s", query.Encode())
 
-	response := c.Builder.Get(endpoint, rest.Context(ctx), rest.Headers(headers))
+	response := meliRequests().Do(ctx, "GetItems", accessToken, func() *rest.Response {
+		return c.Builder.Get(endpoint, rest.Context(ctx), rest.Headers(headers))
+	})
 	if response.Response == nil {
 		return nil, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError("unexpected error calling MercadoLibre batch items endpoint", "internal_server_error", http.StatusInternalServerError, apierrors.CauseList{}))
 	}
 
 	if response.StatusCode != http.StatusOK {
-		return nil, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf("unexpected response from MercadoLibre batch items endpoint, status: %d", response.StatusCode), "bad_gateway", http.StatusBadGateway, apierrors.CauseList{response}))
+		return nil, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf("unexpected response from MercadoLibre batch items endpoint, status: %d", response.StatusCode), meliFailureCode(response.StatusCode), meliFailureStatus(response.StatusCode), apierrors.CauseList{response}))
 	}
 
-	// MercadoLibre returns array of objects with code and body fields for batch requests
+	// MercadoLibre returns array of objects with code and body fields for batch requests, in request order.
+	// The body is the item, or an error when the code is not 200.
 	type batchResponse struct {
-		Code int                  `json:"code"`
-		Body dto.MeliItemResponse `json:"body"`
+		Code int             `json:"code"`
+		Body json.RawMessage `json:"body"`
 	}
 
 	var batchItems []batchResponse
@@ -129,14 +147,33 @@ func (c *mercadoLibreClient) GetItems(ctx context.Context, meliItemIDs []string,
 		return nil, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError("error decoding MercadoLibre batch items response", "internal_server_error", http.StatusInternalServerError, apierrors.CauseList{err}))
 	}
 
-	items := make([]dto.MeliItemResponse, 0, len(batchItems))
-	for _, batch := range batchItems {
-		if batch.Code == http.StatusOK {
-			items = append(items, batch.Body)
+	results := make([]dto.MeliMultigetResult, len(meliItemIDs))
+	for i, meliItemID := range meliItemIDs {
+		results[i] = dto.MeliMultigetResult{ID: meliItemID, Code: http.StatusBadGateway, Message: "missing from MercadoLibre batch items response"}
+		if i >= len(batchItems) {
+			continue
+		}
+
+		batch := batchItems[i]
+		results[i].Code = batch.Code
+		if batch.Code != http.StatusOK {
+			var body struct {
+				Message string `json:"message"`
+			}
+			_ = json.Unmarshal(batch.Body, &body)
+			results[i].Message = fmt.Sprintf("MercadoLibre answered %d: %s", batch.Code, body.Message)
+			continue
+		}
+
+		if err := json.Unmarshal(batch.Body, &results[i].Item); err != nil {
+			results[i].Code = http.StatusInternalServerError
+			results[i].Message = "error decoding MercadoLibre item: " + err.Error()
+			continue
 		}
+		results[i].Message = ""
 	}
 
-	return items, nil
+	return results, nil
 }
 
 func (c *mercadoLibreClient) GetUserItems(ctx context.Context, meliUserID int64, accessToken string) (dto.MeliUserItemsSearchResponse, apierrors.ApiError) {
@@ -154,14 +191,16 @@ func (c *mercadoLibreClient) GetUserItems(ctx context.Context, meliUserID int64,
 	}
 
 	endpoint := fmt.Sprintf("/users/%d/items/search", meliUserID)
-	response := c.Builder.Get(endpoint, rest.Context(ctx), rest.Headers(headers))
+	response := meliRequests().Do(ctx, "GetUserItems", accessToken, func() *rest.Response {
+		return c.Builder.Get(endpoint, rest.Context(ctx), rest.Headers(headers))
+	})
 
 	if response.Response == nil {
 		return dto.MeliUserItemsSearchResponse{}, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError("unexpected error calling MercadoLibre user items endpoint", "internal_server_error", http.StatusInternalServerError, apierrors.CauseList{}))
 	}
 
 	if response.StatusCode != http.StatusOK {
-		return dto.MeliUserItemsSearchResponse{}, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf("unexpected response from MercadoLibre user items endpoint, status: %d, body: %s", response.StatusCode, string(response.Bytes())), "bad_gateway", http.StatusBadGateway, apierrors.CauseList{response}))
+		return dto.MeliUserItemsSearchResponse{}, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf("unexpected response from MercadoLibre user items endpoint, status: %d, body: %s", response.StatusCode, string(response.Bytes())), meliFailureCode(response.StatusCode), meliFailureStatus(response.StatusCode), apierrors.CauseList{response}))
 	}
 
 	var searchResult dto.MeliUserItemsSearchResponse
@@ -192,7 +231,9 @@ func (c *mercadoLibreClient) GetUserItemsWithPagination(ctx context.Context, mel
 	query.Set("limit", fmt.Sprintf("%d", limit))
 
 	endpoint := fmt.Sprintf("/users/%d/items/search?%s", meliUserID, query.Encode())
-	response := c.Builder.Get(endpoint, rest.Context(ctx), rest.Headers(headers))
+	response := meliRequests().Do(ctx, "GetUserItemsWithPagination", accessToken, func() *rest.Response {
+		return c.Builder.Get(endpoint, rest.Context(ctx), rest.Headers(headers))
+	})
 
 	if response.Response == nil {
 		return dto.MeliUserItemsSearchResponse{}, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError("unexpected error calling MercadoLibre user items endpoint", "internal_server_error", http.StatusInternalServerError, apierrors.CauseList{}))
@@ -201,7 +242,7 @@ func (c *mercadoLibreClient) GetUserItemsWithPagination(ctx context.Context, mel
 	if response.StatusCode != http.StatusOK {
 		return dto.MeliUserItemsSearchResponse{}, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(
 			fmt.Sprintf("unexpected response from MercadoLibre user items endpoint, status: %d", response.StatusCode),
-			"bad_gateway", http.StatusBadGateway, apierrors.CauseList{response}))
+			meliFailureCode(response.StatusCode), meliFailureStatus(response.StatusCode), apierrors.CauseList{response}))
 	}
 
 	var searchResult dto.MeliUserItemsSearchResponse
@@ -212,6 +253,52 @@ func (c *mercadoLibreClient) GetUserItemsWithPagination(ctx context.Context, mel
 	return searchResult, nil
 }
 
+// ScanUserItems pages through a seller's items with a scan search, which has no offset limit. The first call
+// goes without scrollID, the next ones with the scroll_id of the previous response, until no results come back.
+func (c *mercadoLibreClient) ScanUserItems(ctx context.Context, meliUserID int64, accessToken string, scrollID string, limit int) (dto.MeliUserItemsSearchResponse, apierrors.ApiError) {
+	ctx, span := tracerMeliClient.Start(ctx, "ScanUserItems")
+	defer span.End()
+
+	if meliUserID == 0 {
+		return dto.MeliUserItemsSearchResponse{}, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError("meli_user_id is required", "bad_request", http.StatusBadRequest, apierrors.CauseList{}))
+	}
+
+	headers := http.Header{}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(headers))
+	if accessToken != "" {
+		headers.Add("Authorization", "Bearer "+accessToken)
+	}
+
+	query := url.Values{}
+	query.Set("search_type", "scan")
+	query.Set("limit", fmt.Sprintf("%d", limit))
+	if scrollID != "" {
+		query.Set("scroll_id", scrollID)
+	}
+
+	endpoint := fmt.Sprintf("/users/%d/items/search?%s", meliUserID, query.Encode())
+	response := meliRequests().Do(ctx, "ScanUserItems", accessToken, func() *rest.Response {
+		return c.Builder.Get(endpoint, rest.Context(ctx), rest.Headers(headers))
+	})
+
+	if response.Response == nil {
+		return dto.MeliUserItemsSearchResponse{}, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError("unexpected error calling MercadoLibre user items endpoint", "internal_server_error", http.StatusInternalServerError, apierrors.CauseList{}))
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return dto.MeliUserItemsSearchResponse{}, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(
+			fmt.Sprintf("unexpected response from MercadoLibre user items scan, status: %d", response.StatusCode),
+			meliFailureCode(response.StatusCode), meliFailureStatus(response.StatusCode), apierrors.CauseList{response}))
+	}
+
+	var searchResult dto.MeliUserItemsSearchResponse
+	if err := json.Unmarshal(response.Bytes(), &searchResult); err != nil {
+		return dto.MeliUserItemsSearchResponse{}, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError("error decoding MercadoLibre user items scan response", "internal_server_error", http.StatusInternalServerError, apierrors.CauseList{err}))
+	}
+
+	return searchResult, nil
+}
+
 func (c *mercadoLibreClient) SearchItems(ctx context.Context, filters dto.MercadoLibreSearchFilters, accessToken string) (dto.MeliSearchResponse, apierrors.ApiError) {
 	ctx, span := tracerMeliClient.Start(ctx, "SearchItems")
 	defer span.End()
@@ -241,14 +328,16 @@ func (c *mercadoLibreClient) SearchItems(ctx context.Context, filters dto.Mercad
 	}
 
 	endpoint := fmt.Sprintf("/sites/%s/search?%s", filters.SiteID, query.Encode())
-	response := c.Builder.Get(endpoint, rest.Context(ctx), rest.Headers(headers))
+	response := meliRequests().Do(ctx, "SearchItems", accessToken, func() *rest.Response {
+		return c.Builder.Get(endpoint, rest.Context(ctx), rest.Headers(headers))
+	})
 
 	if response.Response == nil {
 		return dto.MeliSearchResponse{}, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError("unexpected error calling MercadoLibre search endpoint", "internal_server_error", http.StatusInternalServerError, apierrors.CauseList{}))
 	}
 
 	if response.StatusCode != http.StatusOK {
-		return dto.MeliSearchResponse{}, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf("unexpected response from MercadoLibre search endpoint, status: %d", response.StatusCode), "bad_gateway", http.StatusBadGateway, apierrors.CauseList{response}))
+		return dto.MeliSearchResponse{}, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf("unexpected response from MercadoLibre search endpoint, status: %d", response.StatusCode), meliFailureCode(response.StatusCode), meliFailureStatus(response.StatusCode), apierrors.CauseList{response}))
 	}
 
 	var searchResult dto.MeliSearchResponse
@@ -274,14 +363,16 @@ func (c *mercadoLibreClient) GetSizeChart(ctx context.Context, chartID string, a
 	}
 
 	endpoint := fmt.Sprintf("/catalog/charts/%s", chartID)
-	response := c.Builder.Get(endpoint, rest.Context(ctx), rest.Headers(headers))
+	response := meliRequests().Do(ctx, "GetSizeChart", accessToken, func() *rest.Response {
+		return c.Builder.Get(endpoint, rest.Context(ctx), rest.Headers(headers))
+	})
 
 	if response.Response == nil {
 		return dto.MeliSizeChartResponse{}, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError("unexpected error calling MercadoLibre size chart endpoint", "internal_server_error", http.StatusInternalServerError, apierrors.CauseList{}))
 	}
 
 	if response.StatusCode != http.StatusOK {
-		return dto.MeliSizeChartResponse{}, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf("unexpected response from MercadoLibre size chart endpoint, status: %d", response.StatusCode), "bad_gateway", http.StatusBadGateway, apierrors.CauseList{response}))
+		return dto.MeliSizeChartResponse{}, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf("unexpected response from MercadoLibre size chart endpoint, status: %d", response.StatusCode), meliFailureCode(response.StatusCode), meliFailureStatus(response.StatusCode), apierrors.CauseList{response}))
 	}
 
 	var sizeChart dto.MeliSizeChartResponse
@@ -291,3 +382,116 @@ func (c *mercadoLibreClient) GetSizeChart(ctx context.Context, chartID string, a
 
 	return sizeChart, nil
 }
+
+// GetItemDescription returns the description of an item, items without one get an empty description
+func (c *mercadoLibreClient) GetItemDescription(ctx context.Context, meliItemID string, accessToken string) (dto.MeliItemDescription, apierrors.ApiError) {
+	ctx, span := tracerMeliClient.Start(ctx, "GetItemDescription")
+	defer span.End()
+
+	if strings.TrimSpace(meliItemID) == "" {
+		return dto.MeliItemDescription{}, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError("meli item id is required", "bad_request", http.StatusBadRequest, apierrors.CauseList{}))
+	}
+
+	headers := http.Header{}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(headers))
+	if accessToken != "" {
+		headers.Add("Authorization", "Bearer "+accessToken)
+	}
+
+	endpoint := fmt.Sprintf("/items/%s/description", meliItemID)
+	response := meliRequests().Do(ctx, "GetItemDescription", accessToken, func() *rest.Response {
+		return c.Builder.Get(endpoint, rest.Context(ctx), rest.Headers(headers))
+	})
+
+	if response.Response == nil {
+		return dto.MeliItemDescription{}, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError("unexpected error calling MercadoLibre item description endpoint", "internal_server_error", http.StatusInternalServerError, apierrors.CauseList{}))
+	}
+
+	if response.StatusCode == http.StatusNotFound {
+		return dto.MeliItemDescription{}, nil
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return dto.MeliItemDescription{}, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf("unexpected response from MercadoLibre item description endpoint, status: %d", response.StatusCode), meliFailureCode(response.StatusCode), meliFailureStatus(response.StatusCode), apierrors.CauseList{response}))
+	}
+
+	var description dto.MeliItemDescription
+	if err := json.Unmarshal(response.Bytes(), &description); err != nil {
+		return dto.MeliItemDescription{}, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError("error decoding MercadoLibre item description response", "internal_server_error", http.StatusInternalServerError, apierrors.CauseList{err}))
+	}
+
+	return description, nil
+}
+
+// GetItemPrices returns the prices of an item, with the window of the promotions running on it
+func (c *mercadoLibreClient) GetItemPrices(ctx context.Context, meliItemID string, accessToken string) (dto.MeliItemPricesResponse, apierrors.ApiError) {
+	ctx, span := tracerMeliClient.Start(ctx, "GetItemPrices")
+	defer span.End()
+
+	if strings.TrimSpace(meliItemID) == "" {
+		return dto.MeliItemPricesResponse{}, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError("meli item id is required", "bad_request", http.StatusBadRequest, apierrors.CauseList{}))
+	}
+
+	headers := http.Header{}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(headers))
+	if accessToken != "" {
+		headers.Add("Authorization", "Bearer "+accessToken)
+	}
+
+	endpoint := fmt.Sprintf("/items/%s/prices", meliItemID)
+	response := meliRequests().Do(ctx, "GetItemPrices", accessToken, func() *rest.Response {
+		return c.Builder.Get(endpoint, rest.Context(ctx), rest.Headers(headers))
+	})
+
+	if response.Response == nil {
+		return dto.MeliItemPricesResponse{}, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError("unexpected error calling MercadoLibre item prices endpoint", "internal_server_error", http.StatusInternalServerError, apierrors.CauseList{}))
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return dto.MeliItemPricesResponse{}, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf("unexpected response from MercadoLibre item prices endpoint, status: %d", response.StatusCode), meliFailureCode(response.StatusCode), meliFailureStatus(response.StatusCode), apierrors.CauseList{response}))
+	}
+
+	var prices dto.MeliItemPricesResponse
+	if err := json.Unmarshal(response.Bytes(), &prices); err != nil {
+		return dto.MeliItemPricesResponse{}, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError("error decoding MercadoLibre item prices response", "internal_server_error", http.StatusInternalServerError, apierrors.CauseList{err}))
+	}
+
+	return prices, nil
+}
+
+// GetCategory fetches a category and its path from the root. Categories are public, no token is needed.
+func (c *mercadoLibreClient) GetCategory(ctx context.Context, categoryID string) (dto.MeliCategoryResponse, apierrors.ApiError) {
+	ctx, span := tracerMeliClient.Start(ctx, "GetCategory")
+	defer span.End()
+
+	if strings.TrimSpace(categoryID) == "" {
+		return dto.MeliCategoryResponse{}, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError("meli category id is required", "bad_request", http.StatusBadRequest, apierrors.CauseList{}))
+	}
+
+	headers := http.Header{}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(headers))
+
+	endpoint := fmt.Sprintf("/categories/%s", url.PathEscape(categoryID))
+	response := meliRequests().Do(ctx, "GetCategory", "", func() *rest.Response {
+		return c.Builder.Get(endpoint, rest.Context(ctx), rest.Headers(headers))
+	})
+
+	if response.Response == nil {
+		return dto.MeliCategoryResponse{}, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError("unexpected error calling MercadoLibre categories endpoint", "internal_server_error", http.StatusInternalServerError, apierrors.CauseList{}))
+	}
+
+	if response.StatusCode == http.StatusNotFound {
+		return dto.MeliCategoryResponse{}, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf("meli category %s not found", categoryID), "not_found", http.StatusNotFound, apierrors.CauseList{}))
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return dto.MeliCategoryResponse{}, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf("unexpected response from MercadoLibre categories endpoint, status: %d", response.StatusCode), meliFailureCode(response.StatusCode), meliFailureStatus(response.StatusCode), apierrors.CauseList{response}))
+	}
+
+	var category dto.MeliCategoryResponse
+	if err := json.Unmarshal(response.Bytes(), &category); err != nil {
+		return dto.MeliCategoryResponse{}, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError("error decoding MercadoLibre category response", "internal_server_error", http.StatusInternalServerError, apierrors.CauseList{err}))
+	}
+
+	return category, nil
+}
@@ -20,10 +20,10 @@ import (
 )
 
 const (
-	// MercadoLibre OAuth URLs - Argentina
-	meliOAuthURL     = "https://auth.mercadolibre.com.ar/authorization"
+	meliOAuthPath    = "/authorization"
 	meliTokenBaseURL = "https://api.mercadolibre.com"
 	meliOAuthToken   = "/oauth/token"
+	meliCurrentUser  = "/users/me"
 )
 
 var (
@@ -31,10 +31,40 @@ var (
 	tracerMeliAuth                 = otel.Tracer("mercadolibre-auth-client")
 )
 
+// meliAuthDomains is where the sellers of each MercadoLibre site authorize the app, tokens are then issued by
+// the same API for every site
+var meliAuthDomains = map[string]string{
+	"MLA": "auth.mercadolibre.com.ar",
+	"MBO": "auth.mercadolibre.com.bo",
+	"MLB": "auth.mercadolivre.com.br",
+	"MLC": "auth.mercadolibre.cl",
+	"MCO": "auth.mercadolibre.com.co",
+	"MCR": "auth.mercadolibre.co.cr",
+	"MRD": "auth.mercadolibre.com.do",
+	"MEC": "auth.mercadolibre.com.ec",
+	"MGT": "auth.mercadolibre.com.gt",
+	"MHN": "auth.mercadolibre.com.hn",
+	"MLM": "auth.mercadolibre.com.mx",
+	"MNI": "auth.mercadolibre.com.ni",
+	"MPA": "auth.mercadolibre.com.pa",
+	"MPE": "auth.mercadolibre.com.pe",
+	"MPY": "auth.mercadolibre.com.py",
+	"MSV": "auth.mercadolibre.com.sv",
+	"MLU": "auth.mercadolibre.com.uy",
+	"MLV": "auth.mercadolibre.com.ve",
+}
+
+// IsSupportedMeliSite tells whether sellers of a MercadoLibre site can connect their account
+func IsSupportedMeliSite(siteID string) bool {
+	_, ok := meliAuthDomains[siteID]
+	return ok
+}
+
 type MercadoLibreAuthClient interface {
-	GetOAuthURL(ctx context.Context) (models.MercadoLibreURL, apierrors.ApiError)
+	GetOAuthURL(ctx context.Context, siteID string) (models.MercadoLibreURL, apierrors.ApiError)
 	GetOAuthCredentials(ctx context.Context, code string) (dto.MercadoLibreAuthResponse, apierrors.ApiError)
 	RefreshOAuthCredentials(ctx context.Context, refreshToken string) (dto.MercadoLibreAuthResponse, apierrors.ApiError)
+	GetCurrentUser(ctx context.Context, accessToken string) (dto.MercadoLibreUserResponse, apierrors.ApiError)
 }
 
 type mercadoLibreAuthClient struct {
@@ -60,14 +90,20 @@ func NewMercadoLibreAuthClient() MercadoLibreAuthClient {
 	return &mercadoLibreAuthClient{Builder: builder}
 }
 
-func (c *mercadoLibreAuthClient) GetOAuthURL(ctx context.Context) (models.MercadoLibreURL, apierrors.ApiError) {
+// GetOAuthURL builds the authorization URL of a site, the site comes back as the state of the redirect
+func (c *mercadoLibreAuthClient) GetOAuthURL(ctx context.Context, siteID string) (models.MercadoLibreURL, apierrors.ApiError) {
 	ctx, span := tracerMeliAuth.Start(ctx, "GetOAuthURL")
 	defer span.End()
 
+	authDomain, ok := meliAuthDomains[siteID]
+	if !ok {
+		return models.MercadoLibreURL{}, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf("MercadoLibre site %q is not supported", siteID), "bad_request", http.StatusBadRequest, apierrors.CauseList{}))
+	}
+
 	clientID := getMeliClientID()
 	redirectURI := getMeliCallbackURL()
 
-	u, err := url.Parse(meliOAuthURL)
+	u, err := url.Parse("https://" + authDomain + meliOAuthPath)
 	if err != nil {
 		return models.MercadoLibreURL{}, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf("error parsing OAuth URL: %s", err.Error()), "internal_server_error", http.StatusInternalServerError, apierrors.CauseList{err}))
 	}
@@ -77,6 +113,7 @@ func (c *mercadoLibreAuthClient) GetOAuthURL(ctx context.Context) (models.Mercad
 	params.Add("client_id", clientID)
 	// PKCE disabled for now; we can re-enable later if needed.
 	params.Add("redirect_uri", redirectURI)
+	params.Add("state", siteID)
 
 	u.RawQuery = params.Encode()
 
@@ -84,6 +121,10 @@ func (c *mercadoLibreAuthClient) GetOAuthURL(ctx context.Context) (models.Mercad
 }
 
 func getMeliCallbackURL() string {
+	if config.ConfMap.MeliCallbackURL != "" {
+		return config.ConfMap.MeliCallbackURL
+	}
+
 	env := os.Getenv("DEPLOY_ENVIRONMENT")
 	var base = "https://jopit.com.ar"
 
@@ -147,7 +188,11 @@ func (c *mercadoLibreAuthClient) GetOAuthCredentials(ctx context.Context, code s
 		RedirectURI:  &redirectURI,
 	}
 
-	response := c.Builder.Post(meliOAuthToken, request, rest.Context(ctx))
+	// Token calls carry no seller token, they only count against the app limit. They are never retried, a
+	// retry after MercadoLibre used the code would fail and hide the first answer.
+	response := meliRequests().DoOnce(ctx, "GetOAuthCredentials", "", func() *rest.Response {
+		return c.Builder.Post(meliOAuthToken, request, rest.Context(ctx))
+	})
 
 	if response.Err != nil || response.Response == nil || response.StatusCode != http.StatusOK {
 		return dto.MercadoLibreAuthResponse{}, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError("MercadoLibre credentials handshake failed.", "internal_server_error", http.StatusInternalServerError, apierrors.CauseList{response}))
@@ -173,7 +218,11 @@ func (c *mercadoLibreAuthClient) RefreshOAuthCredentials(ctx context.Context, re
 		RefreshToken: &refreshToken,
 	}
 
-	response := c.Builder.Post(meliOAuthToken, request, rest.Context(ctx))
+	// Token calls carry no seller token, they only count against the app limit. They are never retried, a
+	// retry after MercadoLibre used the refresh token would fail and hide the first answer.
+	response := meliRequests().DoOnce(ctx, "RefreshOAuthCredentials", "", func() *rest.Response {
+		return c.Builder.Post(meliOAuthToken, request, rest.Context(ctx))
+	})
 
 	if response.Err != nil || response.Response == nil || response.StatusCode != http.StatusOK {
 		return dto.MercadoLibreAuthResponse{}, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError("MercadoLibre credentials refresh failed.", "internal_server_error", http.StatusInternalServerError, apierrors.CauseList{response}))
@@ -185,3 +234,32 @@ func (c *mercadoLibreAuthClient) RefreshOAuthCredentials(ctx context.Context, re
 
 	return auth, nil
 }
+
+// GetCurrentUser returns the MercadoLibre account a token belongs to
+func (c *mercadoLibreAuthClient) GetCurrentUser(ctx context.Context, accessToken string) (dto.MercadoLibreUserResponse, apierrors.ApiError) {
+	var user dto.MercadoLibreUserResponse
+
+	ctx, span := tracerMeliAuth.Start(ctx, "GetCurrentUser")
+	defer span.End()
+
+	headers := http.Header{}
+	headers.Add("Authorization", "Bearer "+accessToken)
+
+	response := meliRequests().Do(ctx, "GetCurrentUser", accessToken, func() *rest.Response {
+		return c.Builder.Get(meliCurrentUser, rest.Context(ctx), rest.Headers(headers))
+	})
+
+	if response.Err != nil || response.Response == nil {
+		return dto.MercadoLibreUserResponse{}, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError("unexpected error getting MercadoLibre user", "internal_server_error", http.StatusInternalServerError, apierrors.CauseList{response}))
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return dto.MercadoLibreUserResponse{}, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf("unexpected response getting MercadoLibre user, status: %d", response.StatusCode), meliFailureCode(response.StatusCode), meliFailureStatus(response.StatusCode), apierrors.CauseList{response}))
+	}
+
+	if err := json.Unmarshal(response.Bytes(), &user); err != nil {
+		return dto.MercadoLibreUserResponse{}, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError("unexpected error unmarshalling MercadoLibre user. value: "+string(response.Bytes()), "internal_server_error", http.StatusInternalServerError, apierrors.CauseList{err}))
+	}
+
+	return user, nil
+}
@@ -0,0 +1,136 @@
+package clients
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/jopitnow/go-jopit-toolkit/goutils/apierrors"
+	"github.com/jopitnow/go-jopit-toolkit/rest"
+	"github.com/jopitnow/jopit-api-etl/src/main/api/config"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models/dto"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+const (
+	BulkUpsertPricesUrl = "/prices/bulk-upsert"
+	BulkDeletePricesUrl = "/prices/bulk-delete"
+	GetItemsPricesUrl   = "/prices/items"
+)
+
+var (
+	PricesClientInstance = NewPriceClient()
+	tracerClientPrices   = otel.Tracer("prices-client") // Tracer for this package
+)
+
+type PricesClient interface {
+	BulkUpsertPrices(ctx context.Context, prices []models.Price) apierrors.ApiError
+	BulkDeletePrices(ctx context.Context, itemIDs []string) apierrors.ApiError
+	GetItemsPrices(ctx context.Context, itemIDs []string) (models.Prices, apierrors.ApiError)
+}
+
+type pricesClient struct {
+	Builder *rest.RequestBuilder
+}
+
+func NewPriceClient() PricesClient {
+	httpClient := http.Client{}
+	httpClient.Transport = otelhttp.NewTransport(http.DefaultTransport)
+
+	builder := &rest.RequestBuilder{
+		BaseURL:        config.InternalBasePricesClient,
+		Timeout:        5 * time.Second,
+		ContentType:    rest.JSON,
+		EnableCache:    false,
+		DisableTimeout: false,
+		CustomPool:     &rest.CustomPool{MaxIdleConnsPerHost: 100},
+		FollowRedirect: true,
+		MetricsConfig:  rest.MetricsReportConfig{TargetId: "prices-api"},
+		Client:         &httpClient,
+	}
+
+	return &pricesClient{Builder: builder}
+}
+
+// BulkUpsertPrices creates or replaces the price of every item, matched by item id
+func (c *pricesClient) BulkUpsertPrices(ctx context.Context, prices []models.Price) apierrors.ApiError {
+	ctx, span := tracerClientPrices.Start(ctx, "BulkUpsertPrices")
+	defer span.End()
+
+	headers := http.Header{}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(headers))
+
+	reqBody := dto.BulkUpsertPricesRequest{Prices: prices}
+
+	response := c.Builder.Post(BulkUpsertPricesUrl, reqBody, rest.Context(ctx), rest.Headers(headers))
+
+	if response.Err != nil || response.Response == nil {
+		return apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprint("Unexpected error hitting prices api, url: "+BulkUpsertPricesUrl, "\nresponse: ", response), "error hitting Prices Api", http.StatusInternalServerError, apierrors.CauseList{response}))
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprint("Unexpected error hitting prices api, url: "+BulkUpsertPricesUrl, "\nresponse: ", response), "error hitting Prices Api", http.StatusInternalServerError, apierrors.CauseList{response}))
+	}
+
+	return nil
+}
+
+// BulkDeletePrices deletes the prices of the items, items without a price are ignored
+func (c *pricesClient) BulkDeletePrices(ctx context.Context, itemIDs []string) apierrors.ApiError {
+	ctx, span := tracerClientPrices.Start(ctx, "BulkDeletePrices")
+	defer span.End()
+
+	headers := http.Header{}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(headers))
+
+	reqBody := dto.RequestItemsList{ItemsIDs: itemIDs}
+
+	response := c.Builder.Post(BulkDeletePricesUrl, reqBody, rest.Context(ctx), rest.Headers(headers))
+
+	if response.Err != nil || response.Response == nil {
+		return apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprint("Unexpected error hitting prices api, url: "+BulkDeletePricesUrl, "\nresponse: ", response), "error hitting Prices Api", http.StatusInternalServerError, apierrors.CauseList{response}))
+	}
+
+	if response.StatusCode != http.StatusOK && response.StatusCode != http.StatusNoContent {
+		return apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprint("Unexpected error hitting prices api, url: "+BulkDeletePricesUrl, "\nresponse: ", response), "error hitting Prices Api", http.StatusInternalServerError, apierrors.CauseList{response}))
+	}
+
+	return nil
+}
+
+// GetItemsPrices returns the current prices of the items, items without a price are left out
+func (c *pricesClient) GetItemsPrices(ctx context.Context, itemIDs []string) (models.Prices, apierrors.ApiError) {
+	ctx, span := tracerClientPrices.Start(ctx, "GetItemsPrices")
+	defer span.End()
+
+	headers := http.Header{}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(headers))
+
+	reqBody := dto.RequestItemsList{ItemsIDs: itemIDs}
+
+	response := c.Builder.Post(GetItemsPricesUrl, reqBody, rest.Context(ctx), rest.Headers(headers))
+
+	if response.Err != nil || response.Response == nil {
+		return models.Prices{}, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprint("Unexpected error hitting prices api, url: "+GetItemsPricesUrl, "\nresponse: ", response), "error hitting Prices Api", http.StatusInternalServerError, apierrors.CauseList{response}))
+	}
+
+	// None of the items has a price yet
+	if response.StatusCode == http.StatusNotFound {
+		return models.Prices{Prices: []models.Price{}}, nil
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return models.Prices{}, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprint("Unexpected error hitting prices api, url: "+GetItemsPricesUrl, "\nresponse: ", response), "error hitting Prices Api", http.StatusInternalServerError, apierrors.CauseList{response}))
+	}
+
+	var prices models.Prices
+	if err := response.FillUp(&prices); err != nil {
+		return models.Prices{}, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError("error parsing response: "+err.Error(), "internal_error", http.StatusInternalServerError, apierrors.CauseList{}))
+	}
+
+	return prices, nil
+}
@@ -0,0 +1,338 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/jopitnow/go-jopit-toolkit/goauth"
+	"github.com/jopitnow/go-jopit-toolkit/goutils/apierrors"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models/dto"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/services"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/utils"
+)
+
+type CategoryMappingsHandler struct {
+	Service services.CategoryMappingsService
+}
+
+func NewCategoryMappingsHandler(service services.CategoryMappingsService) CategoryMappingsHandler {
+	return CategoryMappingsHandler{
+		Service: service,
+	}
+}
+
+// Create godoc
+// @Summary Create category mapping
+// @Description Map a MercadoLibre category or domain to a Jopit category for the user's shop, overriding the global mapping
+// @Tags Category Mappings
+// @Param Authorization header string true "Bearer token"
+// @Param mapping body dto.CategoryMappingRequest true "Category mapping"
+// @Accept json
+// @Produce json
+// @Success 201 {object} models.CategoryMapping
+// @Failure 400 "Bad Request - Invalid mapping"
+// @Failure 401 "Unauthorized Firebase Token"
+// @Failure 409 "The category is already mapped"
+// @Router /etl/category-mappings [post]
+func (h CategoryMappingsHandler) Create(c *gin.Context) {
+	var input dto.CategoryMappingRequest
+
+	if err := binding.JSON.Bind(c.Request, &input); err != nil {
+		apiErr := apierrors.NewApiError(err.Error(), "bad_request", http.StatusBadRequest, apierrors.CauseList{})
+		c.Error(apiErr)
+		c.JSON(apiErr.Status(), apiErr)
+		return
+	}
+
+	userID, apiErr := goauth.GetUserId(c)
+	if apiErr != nil {
+		c.Error(apiErr)
+		c.JSON(apiErr.Status(), apiErr)
+		return
+	}
+
+	ctx := context.WithValue(c.Request.Context(), goauth.FirebaseUserID, userID)
+	ctx = context.WithValue(ctx, goauth.FirebaseAuthHeader, c.GetHeader("Authorization"))
+
+	mapping, apiErr := h.Service.Create(ctx, input)
+	if apiErr != nil {
+		c.Error(apiErr)
+		c.JSON(apiErr.Status(), apiErr)
+		return
+	}
+
+	c.JSON(http.StatusCreated, mapping)
+}
+
+// GetByUserID godoc
+// @Summary List category mappings
+// @Description List the mappings used by the imports of the user's shop, its own first and then the global ones
+// @Tags Category Mappings
+// @Param Authorization header string true "Bearer token"
+// @Produce json
+// @Success 200 {array} models.CategoryMapping
+// @Failure 401 "Unauthorized Firebase Token"
+// @Router /etl/category-mappings [get]
+func (h CategoryMappingsHandler) GetByUserID(c *gin.Context) {
+	userID, apiErr := goauth.GetUserId(c)
+	if apiErr != nil {
+		c.Error(apiErr)
+		c.JSON(apiErr.Status(), apiErr)
+		return
+	}
+
+	ctx := context.WithValue(c.Request.Context(), goauth.FirebaseUserID, userID)
+	ctx = context.WithValue(ctx, goauth.FirebaseAuthHeader, c.GetHeader("Authorization"))
+
+	mappings, apiErr := h.Service.GetByUserID(ctx)
+	if apiErr != nil {
+		c.Error(apiErr)
+		c.JSON(apiErr.Status(), apiErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, mappings)
+}
+
+// Suggest godoc
+// @Summary Suggest category mappings
+// @Description Propose Jopit categories for the MercadoLibre categories of the shop's imported items that have no mapping, from their category path and domain
+// @Tags Category Mappings
+// @Param Authorization header string true "Bearer token"
+// @Produce json
+// @Success 200 {array} models.CategoryMappingSuggestion
+// @Failure 401 "Unauthorized Firebase Token"
+// @Router /etl/category-mappings/suggestions [get]
+func (h CategoryMappingsHandler) Suggest(c *gin.Context) {
+	userID, apiErr := goauth.GetUserId(c)
+	if apiErr != nil {
+		c.Error(apiErr)
+		c.JSON(apiErr.Status(), apiErr)
+		return
+	}
+
+	ctx := context.WithValue(c.Request.Context(), goauth.FirebaseUserID, userID)
+	ctx = context.WithValue(ctx, goauth.FirebaseAuthHeader, c.GetHeader("Authorization"))
+
+	suggestions, apiErr := h.Service.Suggest(ctx)
+	if apiErr != nil {
+		c.Error(apiErr)
+		c.JSON(apiErr.Status(), apiErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, suggestions)
+}
+
+// Update godoc
+// @Summary Update category mapping
+// @Description Replace a category mapping of the user's shop
+// @Tags Category Mappings
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Mapping ID"
+// @Param mapping body dto.CategoryMappingRequest true "Category mapping"
+// @Accept json
+// @Produce json
+// @Success 200 {object} models.CategoryMapping
+// @Failure 400 "Bad Request - Invalid mapping"
+// @Failure 401 "Unauthorized Firebase Token"
+// @Failure 404 "Mapping not found"
+// @Failure 409 "The category is already mapped"
+// @Router /etl/category-mappings/{id} [put]
+func (h CategoryMappingsHandler) Update(c *gin.Context) {
+	mappingID := c.Param("id")
+	if apiErr := utils.ValidateHexID([]string{mappingID}); apiErr != nil {
+		c.Error(apiErr)
+		c.JSON(apiErr.Status(), apiErr)
+		return
+	}
+
+	var input dto.CategoryMappingRequest
+
+	if err := binding.JSON.Bind(c.Request, &input); err != nil {
+		apiErr := apierrors.NewApiError(err.Error(), "bad_request", http.StatusBadRequest, apierrors.CauseList{})
+		c.Error(apiErr)
+		c.JSON(apiErr.Status(), apiErr)
+		return
+	}
+
+	userID, apiErr := goauth.GetUserId(c)
+	if apiErr != nil {
+		c.Error(apiErr)
+		c.JSON(apiErr.Status(), apiErr)
+		return
+	}
+
+	ctx := context.WithValue(c.Request.Context(), goauth.FirebaseUserID, userID)
+	ctx = context.WithValue(ctx, goauth.FirebaseAuthHeader, c.GetHeader("Authorization"))
+
+	mapping, apiErr := h.Service.Update(ctx, mappingID, input)
+	if apiErr != nil {
+		c.Error(apiErr)
+		c.JSON(apiErr.Status(), apiErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, mapping)
+}
+
+// Delete godoc
+// @Summary Delete category mapping
+// @Description Delete a category mapping of the user's shop, the global mapping applies again
+// @Tags Category Mappings
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Mapping ID"
+// @Success 204
+// @Failure 401 "Unauthorized Firebase Token"
+// @Failure 404 "Mapping not found"
+// @Router /etl/category-mappings/{id} [delete]
+func (h CategoryMappingsHandler) Delete(c *gin.Context) {
+	mappingID := c.Param("id")
+	if apiErr := utils.ValidateHexID([]string{mappingID}); apiErr != nil {
+		c.Error(apiErr)
+		c.JSON(apiErr.Status(), apiErr)
+		return
+	}
+
+	userID, apiErr := goauth.GetUserId(c)
+	if apiErr != nil {
+		c.Error(apiErr)
+		c.JSON(apiErr.Status(), apiErr)
+		return
+	}
+
+	ctx := context.WithValue(c.Request.Context(), goauth.FirebaseUserID, userID)
+	ctx = context.WithValue(ctx, goauth.FirebaseAuthHeader, c.GetHeader("Authorization"))
+
+	if apiErr := h.Service.Delete(ctx, mappingID); apiErr != nil {
+		c.Error(apiErr)
+		c.JSON(apiErr.Status(), apiErr)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// CreateGlobal godoc
+// @Summary Create global category mapping
+// @Description Map a MercadoLibre category or domain to a Jopit category for every shop
+// @Tags Category Mappings
+// @Security BasicAuth
+// @Param mapping body dto.CategoryMappingRequest true "Category mapping"
+// @Accept json
+// @Produce json
+// @Success 201 {object} models.CategoryMapping
+// @Failure 400 "Bad Request - Invalid mapping"
+// @Failure 401 "Unauthorized"
+// @Failure 409 "The category is already mapped"
+// @Router /etl/admin/category-mappings [post]
+func (h CategoryMappingsHandler) CreateGlobal(c *gin.Context) {
+	var input dto.CategoryMappingRequest
+
+	if err := binding.JSON.Bind(c.Request, &input); err != nil {
+		apiErr := apierrors.NewApiError(err.Error(), "bad_request", http.StatusBadRequest, apierrors.CauseList{})
+		c.Error(apiErr)
+		c.JSON(apiErr.Status(), apiErr)
+		return
+	}
+
+	mapping, apiErr := h.Service.CreateGlobal(c.Request.Context(), input)
+	if apiErr != nil {
+		c.Error(apiErr)
+		c.JSON(apiErr.Status(), apiErr)
+		return
+	}
+
+	c.JSON(http.StatusCreated, mapping)
+}
+
+// GetGlobal godoc
+// @Summary List global category mappings
+// @Description List the category mappings shared by every shop
+// @Tags Category Mappings
+// @Security BasicAuth
+// @Produce json
+// @Success 200 {array} models.CategoryMapping
+// @Failure 401 "Unauthorized"
+// @Router /etl/admin/category-mappings [get]
+func (h CategoryMappingsHandler) GetGlobal(c *gin.Context) {
+	mappings, apiErr := h.Service.GetGlobal(c.Request.Context())
+	if apiErr != nil {
+		c.Error(apiErr)
+		c.JSON(apiErr.Status(), apiErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, mappings)
+}
+
+// UpdateGlobal godoc
+// @Summary Update global category mapping
+// @Description Replace a category mapping shared by every shop
+// @Tags Category Mappings
+// @Security BasicAuth
+// @Param id path string true "Mapping ID"
+// @Param mapping body dto.CategoryMappingRequest true "Category mapping"
+// @Accept json
+// @Produce json
+// @Success 200 {object} models.CategoryMapping
+// @Failure 400 "Bad Request - Invalid mapping"
+// @Failure 401 "Unauthorized"
+// @Failure 404 "Mapping not found"
+// @Failure 409 "The category is already mapped"
+// @Router /etl/admin/category-mappings/{id} [put]
+func (h CategoryMappingsHandler) UpdateGlobal(c *gin.Context) {
+	mappingID := c.Param("id")
+	if apiErr := utils.ValidateHexID([]string{mappingID}); apiErr != nil {
+		c.Error(apiErr)
+		c.JSON(apiErr.Status(), apiErr)
+		return
+	}
+
+	var input dto.CategoryMappingRequest
+
+	if err := binding.JSON.Bind(c.Request, &input); err != nil {
+		apiErr := apierrors.NewApiError(err.Error(), "bad_request", http.StatusBadRequest, apierrors.CauseList{})
+		c.Error(apiErr)
+		c.JSON(apiErr.Status(), apiErr)
+		return
+	}
+
+	mapping, apiErr := h.Service.UpdateGlobal(c.Request.Context(), mappingID, input)
+	if apiErr != nil {
+		c.Error(apiErr)
+		c.JSON(apiErr.Status(), apiErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, mapping)
+}
+
+// DeleteGlobal godoc
+// @Summary Delete global category mapping
+// @Description Delete a category mapping shared by every shop
+// @Tags Category Mappings
+// @Security BasicAuth
+// @Param id path string true "Mapping ID"
+// @Success 204
+// @Failure 401 "Unauthorized"
+// @Failure 404 "Mapping not found"
+// @Router /etl/admin/category-mappings/{id} [delete]
+func (h CategoryMappingsHandler) DeleteGlobal(c *gin.Context) {
+	mappingID := c.Param("id")
+	if apiErr := utils.ValidateHexID([]string{mappingID}); apiErr != nil {
+		c.Error(apiErr)
+		c.JSON(apiErr.Status(), apiErr)
+		return
+	}
+
+	if apiErr := h.Service.DeleteGlobal(c.Request.Context(), mappingID); apiErr != nil {
+		c.Error(apiErr)
+		c.JSON(apiErr.Status(), apiErr)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -2,9 +2,13 @@ package handlers
 
 import (
 	"context"
+	"io"
 	"net/http"
+	"time"
 
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models"
 	"github.com/jopitnow/jopit-api-etl/src/main/domain/services"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/utils"
 
 	"github.com/jopitnow/go-jopit-toolkit/goauth"
 	"github.com/jopitnow/go-jopit-toolkit/goutils/apierrors"
@@ -12,27 +16,36 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+const (
+	etlJobEventsPollInterval = 5 * time.Second
+)
+
 type EtlHandler struct {
 	Service             services.EtlService
+	JobsService         services.EtlJobsService
 	MercadoLibreService services.MercadoLibreService
 }
 
-func NewEtlsHandler(service services.EtlService, mercadoLibreService services.MercadoLibreService) EtlHandler {
+func NewEtlsHandler(service services.EtlService, jobsService services.EtlJobsService, mercadoLibreService services.MercadoLibreService) EtlHandler {
 	return EtlHandler{
 		Service:             service,
+		JobsService:         jobsService,
 		MercadoLibreService: mercadoLibreService,
 	}
 }
 
-// CreateItem godoc
-// @Summary Create Item
-// @Description Create item in db
-// @Tags Items
-// @Accept  json
+// LoadApi godoc
+// @Summary Load items from the shop's API
+// @Description Fetch the items from the API configured in the shop's company layout, transform and upsert them into Items API
+// @Tags ETL
 // @Produce  json
-// @Param item body dto.ItemDTO true "Add item"
-// @Success 201
-// @Router /items [post]
+// @Param Authorization header string true "Bearer token"
+// @Success 200 {object} services.ETLResult
+// @Failure 401 "Unauthorized"
+// @Failure 404 "Company layout not found or no items returned"
+// @Failure 409 "An ETL job is running for the shop"
+// @Failure 500 "All items failed, with the per item failures, or Internal Server Error"
+// @Router /etl/api/load [post]
 func (h EtlHandler) LoadApi(c *gin.Context) {
 
 	userID, apiErr := goauth.GetUserId(c)
@@ -43,26 +56,38 @@ func (h EtlHandler) LoadApi(c *gin.Context) {
 	}
 
 	ctx := context.WithValue(c.Request.Context(), goauth.FirebaseUserID, userID)
+	ctx = context.WithValue(ctx, goauth.FirebaseAuthHeader, c.GetHeader("Authorization"))
 
 	response, apiErr := h.Service.LoadApi(ctx)
 	if apiErr != nil {
 		c.Error(apiErr)
-		c.JSON(apiErr.Status(), apiErr)
+		// Return partial results even if there's an error
+		if response != nil {
+			c.JSON(apiErr.Status(), response)
+		} else {
+			c.JSON(apiErr.Status(), apiErr)
+		}
 		return
 	}
 
-	c.JSON(http.StatusCreated, response)
+	c.JSON(http.StatusOK, response)
 }
 
-// CreateItem godoc
-// @Summary Create Item
-// @Description Create item in db
-// @Tags Items
-// @Accept  json
+// LoadCsv godoc
+// @Summary Load items from a CSV file
+// @Description Transform the rows of the uploaded CSV file with the shop's company layout and upsert them into Items API
+// @Tags ETL
+// @Accept  multipart/form-data
 // @Produce  json
-// @Param item body dto.ItemDTO true "Add item"
-// @Success 201
-// @Router /items [post]
+// @Param Authorization header string true "Bearer token"
+// @Param file formData file true "CSV file"
+// @Success 200 {object} services.ETLResult
+// @Failure 400 "CSV file required"
+// @Failure 401 "Unauthorized"
+// @Failure 404 "Company layout not found or empty file"
+// @Failure 409 "An ETL job is running for the shop"
+// @Failure 500 "All items failed, with the per item failures, or Internal Server Error"
+// @Router /etl/csv/load [post]
 func (h EtlHandler) LoadCsv(c *gin.Context) {
 
 	userID, apiErr := goauth.GetUserId(c)
@@ -73,42 +98,59 @@ func (h EtlHandler) LoadCsv(c *gin.Context) {
 	}
 
 	ctx := context.WithValue(c.Request.Context(), goauth.FirebaseUserID, userID)
+	ctx = context.WithValue(ctx, goauth.FirebaseAuthHeader, c.GetHeader("Authorization"))
 
 	fileHeader, err := c.FormFile("file")
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "CSV file required"})
+		apiErr := apierrors.NewApiError("CSV file required", "bad_request", http.StatusBadRequest, apierrors.CauseList{err.Error()})
+		c.Error(apiErr)
+		c.JSON(apiErr.Status(), apiErr)
 		return
 	}
 
 	response, apiErr := h.Service.LoadCsv(ctx, fileHeader)
 	if apiErr != nil {
 		c.Error(apiErr)
-		c.JSON(apiErr.Status(), apiErr)
+		// Return partial results even if there's an error
+		if response != nil {
+			c.JSON(apiErr.Status(), response)
+		} else {
+			c.JSON(apiErr.Status(), apiErr)
+		}
 		return
 	}
 
-	c.JSON(http.StatusCreated, response)
+	c.JSON(http.StatusOK, response)
 }
 
-// DeleteItem godoc
-// @Summary Delete item
-// @Description Delete item by ID
-// @Tags Items
-// @Accept  json
-// @Produce  json
-// @Param id path string true "Item ID"
+// Delete godoc
+// @Summary Delete a batch
+// @Description Delete the items of the user's shop imported by a batch, along with their prices
+// @Tags ETL
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Batch ID"
 // @Success 204
-// @Router /items/{id} [delete]
+// @Failure 401 "Unauthorized"
+// @Failure 404 "Batch not found"
+// @Router /etl/batch/{id} [delete]
 func (h EtlHandler) Delete(c *gin.Context) {
 
-	ctx := context.WithValue(c.Request.Context(), goauth.FirebaseAuthHeader, c.GetHeader("Authorization"))
+	userID, apiErr := goauth.GetUserId(c)
+	if apiErr != nil {
+		c.Error(apiErr)
+		c.JSON(apiErr.Status(), apiErr)
+		return
+	}
+
+	ctx := context.WithValue(c.Request.Context(), goauth.FirebaseUserID, userID)
+	ctx = context.WithValue(ctx, goauth.FirebaseAuthHeader, c.GetHeader("Authorization"))
 
 	batchID := c.Param("id")
 	if batchID == "" {
 		err := apierrors.NewApiError("empty required BatchID", "bad_request", http.StatusBadRequest, apierrors.CauseList{})
 		c.Error(err)
 		c.JSON(err.Status(), err)
-
+		return
 	}
 
 	err := h.Service.DeleteBatch(ctx, batchID)
@@ -121,15 +163,61 @@ func (h EtlHandler) Delete(c *gin.Context) {
 	c.Status(http.StatusNoContent)
 }
 
+// Rollback godoc
+// @Summary Roll back a batch
+// @Description Undo a run of the user's shop: items it created are deleted and items it updated are restored to their state before the run. Items changed by a later run are skipped.
+// @Tags ETL
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Batch ID"
+// @Success 200 {object} models.EtlRollbackResult
+// @Failure 401 "Unauthorized"
+// @Failure 404 "Batch has no snapshot"
+// @Failure 409 "An ETL job is running for the shop"
+// @Failure 500 "Internal Server Error"
+// @Router /etl/batch/{id}/rollback [post]
+func (h EtlHandler) Rollback(c *gin.Context) {
+
+	userID, apiErr := goauth.GetUserId(c)
+	if apiErr != nil {
+		c.Error(apiErr)
+		c.JSON(apiErr.Status(), apiErr)
+		return
+	}
+
+	ctx := context.WithValue(c.Request.Context(), goauth.FirebaseUserID, userID)
+	ctx = context.WithValue(ctx, goauth.FirebaseAuthHeader, c.GetHeader("Authorization"))
+
+	batchID := c.Param("id")
+	if batchID == "" {
+		err := apierrors.NewApiError("empty required BatchID", "bad_request", http.StatusBadRequest, apierrors.CauseList{})
+		c.Error(err)
+		c.JSON(err.Status(), err)
+		return
+	}
+
+	result, err := h.Service.RollbackBatch(ctx, batchID)
+	if err != nil {
+		c.Error(err)
+		c.JSON(err.Status(), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
 // LoadMercadoLibre godoc
 // @Summary Load items from MercadoLibre
-// @Description Fetch items from MercadoLibre, transform to Jopit format, and load into Items API
+// @Description Enqueue an ETL job that fetches items from MercadoLibre, transforms them to Jopit format and loads them into Items API
 // @Tags ETL
 // @Accept  json
 // @Produce  json
 // @Param Authorization header string true "Bearer token"
-// @Success 200 {object} services.ETLResult
+// @Param mode query string false "Load mode: full (default) or incremental"
+// @Success 202 {object} models.EtlJob
+// @Failure 400 "Invalid mode"
 // @Failure 401 "Unauthorized"
+// @Failure 503 "ETL job queue is full"
 // @Failure 500 "Internal Server Error"
 // @Router /etl/mercadolibre/load [post]
 func (h EtlHandler) LoadMercadoLibre(c *gin.Context) {
@@ -143,20 +231,258 @@ func (h EtlHandler) LoadMercadoLibre(c *gin.Context) {
 	ctx := context.WithValue(c.Request.Context(), goauth.FirebaseUserID, userID)
 	ctx = context.WithValue(ctx, goauth.FirebaseAuthHeader, c.GetHeader("Authorization"))
 
-	// Execute full ETL process (Extract, Transform, Load)
-	result, apiErr := h.Service.LoadMercadoLibre(ctx)
+	options, apiErr := loadOptionsFromQuery(c)
 	if apiErr != nil {
 		c.Error(apiErr)
-		// Return partial results even if there's an error
-		if result != nil {
-			c.JSON(apiErr.Status(), result)
-		} else {
-			c.JSON(apiErr.Status(), apiErr)
-		}
+		c.JSON(apiErr.Status(), apiErr)
 		return
 	}
 
-	c.JSON(http.StatusOK, result)
+	// The ETL process (Extract, Transform, Load) runs in the background, poll the job for its result
+	job, apiErr := h.JobsService.EnqueueMercadoLibre(ctx, options)
+	if apiErr != nil {
+		c.Error(apiErr)
+		c.JSON(apiErr.Status(), apiErr)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, job)
+}
+
+// PreviewMercadoLibre godoc
+// @Summary Preview a MercadoLibre load
+// @Description Enqueue an ETL job that fetches and transforms the MercadoLibre items without loading them. The finished job has the counts of what a load would create, update and fail; the transformed items and a per item diff against the shop's current Jopit items are downloaded from /etl/artifacts/{batch_id}/preview.
+// @Tags ETL
+// @Produce  json
+// @Param Authorization header string true "Bearer token"
+// @Param mode query string false "Load mode: full (default) or incremental"
+// @Success 202 {object} models.EtlJob
+// @Failure 400 "Invalid mode"
+// @Failure 401 "Unauthorized"
+// @Failure 503 "ETL job queue is full"
+// @Failure 500 "Internal Server Error"
+// @Router /etl/mercadolibre/preview [post]
+func (h EtlHandler) PreviewMercadoLibre(c *gin.Context) {
+	userID, apiErr := goauth.GetUserId(c)
+	if apiErr != nil {
+		c.Error(apiErr)
+		c.JSON(apiErr.Status(), apiErr)
+		return
+	}
+
+	ctx := context.WithValue(c.Request.Context(), goauth.FirebaseUserID, userID)
+	ctx = context.WithValue(ctx, goauth.FirebaseAuthHeader, c.GetHeader("Authorization"))
+
+	options, apiErr := loadOptionsFromQuery(c)
+	if apiErr != nil {
+		c.Error(apiErr)
+		c.JSON(apiErr.Status(), apiErr)
+		return
+	}
+
+	// A preview extracts the whole catalog like a load does, so it also runs in the background
+	job, apiErr := h.JobsService.EnqueueMercadoLibrePreview(ctx, options)
+	if apiErr != nil {
+		c.Error(apiErr)
+		c.JSON(apiErr.Status(), apiErr)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, job)
+}
+
+// loadOptionsFromQuery reads the load mode query param shared by load and preview
+func loadOptionsFromQuery(c *gin.Context) (models.EtlLoadOptions, apierrors.ApiError) {
+	options := models.EtlLoadOptions{}
+	switch mode := c.DefaultQuery("mode", models.EtlModeFull); mode {
+	case models.EtlModeFull:
+	case models.EtlModeIncremental:
+		options.Incremental = true
+	default:
+		return options, apierrors.NewApiError("invalid mode "+mode+", expected full or incremental", "bad_request", http.StatusBadRequest, apierrors.CauseList{})
+	}
+	return options, nil
+}
+
+// GetJob godoc
+// @Summary Get ETL job
+// @Description Get the status, progress and result of an ETL job started by the authenticated user
+// @Tags ETL
+// @Produce  json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Job ID"
+// @Success 200 {object} models.EtlJob
+// @Failure 401 "Unauthorized"
+// @Failure 404 "Not Found"
+// @Failure 500 "Internal Server Error"
+// @Router /etl/jobs/{id} [get]
+func (h EtlHandler) GetJob(c *gin.Context) {
+	userID, apiErr := goauth.GetUserId(c)
+	if apiErr != nil {
+		c.Error(apiErr)
+		c.JSON(apiErr.Status(), apiErr)
+		return
+	}
+
+	ctx := context.WithValue(c.Request.Context(), goauth.FirebaseUserID, userID)
+
+	jobID := c.Param("id")
+	if err := utils.ValidateHexID([]string{jobID}); err != nil {
+		c.Error(err)
+		c.JSON(err.Status(), err)
+		return
+	}
+
+	job, apiErr := h.JobsService.Get(ctx, jobID)
+	if apiErr != nil {
+		c.Error(apiErr)
+		c.JSON(apiErr.Status(), apiErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// GetJobs godoc
+// @Summary List ETL jobs
+// @Description List the latest ETL jobs started by the authenticated user
+// @Tags ETL
+// @Produce  json
+// @Param Authorization header string true "Bearer token"
+// @Success 200 {array} models.EtlJob
+// @Failure 401 "Unauthorized"
+// @Failure 500 "Internal Server Error"
+// @Router /etl/jobs [get]
+func (h EtlHandler) GetJobs(c *gin.Context) {
+	userID, apiErr := goauth.GetUserId(c)
+	if apiErr != nil {
+		c.Error(apiErr)
+		c.JSON(apiErr.Status(), apiErr)
+		return
+	}
+
+	ctx := context.WithValue(c.Request.Context(), goauth.FirebaseUserID, userID)
+
+	jobs, apiErr := h.JobsService.GetByUserID(ctx)
+	if apiErr != nil {
+		c.Error(apiErr)
+		c.JSON(apiErr.Status(), apiErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, jobs)
+}
+
+// CancelJob godoc
+// @Summary Cancel ETL job
+// @Description Cancel a queued or running ETL job, items loaded before the cancellation are kept and listed in the job
+// @Tags ETL
+// @Produce  json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Job ID"
+// @Success 202 {object} models.EtlJob
+// @Failure 401 "Unauthorized"
+// @Failure 404 "Not Found"
+// @Failure 409 "Job already finished"
+// @Failure 500 "Internal Server Error"
+// @Router /etl/jobs/{id} [delete]
+func (h EtlHandler) CancelJob(c *gin.Context) {
+	userID, apiErr := goauth.GetUserId(c)
+	if apiErr != nil {
+		c.Error(apiErr)
+		c.JSON(apiErr.Status(), apiErr)
+		return
+	}
+
+	ctx := context.WithValue(c.Request.Context(), goauth.FirebaseUserID, userID)
+
+	jobID := c.Param("id")
+	if err := utils.ValidateHexID([]string{jobID}); err != nil {
+		c.Error(err)
+		c.JSON(err.Status(), err)
+		return
+	}
+
+	job, apiErr := h.JobsService.Cancel(ctx, jobID)
+	if apiErr != nil {
+		c.Error(apiErr)
+		c.JSON(apiErr.Status(), apiErr)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, job)
+}
+
+// GetJobEvents godoc
+// @Summary Stream ETL job events
+// @Description Stream stage changes and per item results of an ETL job as Server-Sent Events
+// @Tags ETL
+// @Produce  text/event-stream
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Job ID"
+// @Success 200 {object} models.EtlJobEvent
+// @Failure 401 "Unauthorized"
+// @Failure 404 "Not Found"
+// @Failure 500 "Internal Server Error"
+// @Router /etl/jobs/{id}/events [get]
+func (h EtlHandler) GetJobEvents(c *gin.Context) {
+	userID, apiErr := goauth.GetUserId(c)
+	if apiErr != nil {
+		c.Error(apiErr)
+		c.JSON(apiErr.Status(), apiErr)
+		return
+	}
+
+	ctx := context.WithValue(c.Request.Context(), goauth.FirebaseUserID, userID)
+
+	jobID := c.Param("id")
+	if err := utils.ValidateHexID([]string{jobID}); err != nil {
+		c.Error(err)
+		c.JSON(err.Status(), err)
+		return
+	}
+
+	job, events, unsubscribe, apiErr := h.JobsService.Subscribe(ctx, jobID)
+	if apiErr != nil {
+		c.Error(apiErr)
+		c.JSON(apiErr.Status(), apiErr)
+		return
+	}
+	defer unsubscribe()
+
+	if job.IsFinished() {
+		c.SSEvent(models.EtlJobEventFinished, models.EtlJobEvent{Type: models.EtlJobEventFinished, JobID: job.ID, Job: &job, Timestamp: time.Now().UTC()})
+		return
+	}
+
+	c.SSEvent(models.EtlJobEventSnapshot, models.EtlJobEvent{Type: models.EtlJobEventSnapshot, JobID: job.ID, Stage: job.Stage, Job: &job, Timestamp: time.Now().UTC()})
+
+	// Live events only come from the replica running the job, the persisted record is polled as a fallback
+	ticker := time.NewTicker(etlJobEventsPollInterval)
+	defer ticker.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-ctx.Done():
+			return false
+		case event := <-events:
+			c.SSEvent(event.Type, event)
+			return event.Type != models.EtlJobEventFinished
+		case <-ticker.C:
+			current, err := h.JobsService.Get(ctx, jobID)
+			if err != nil {
+				return false
+			}
+
+			eventType := models.EtlJobEventSnapshot
+			if current.IsFinished() {
+				eventType = models.EtlJobEventFinished
+			}
+
+			c.SSEvent(eventType, models.EtlJobEvent{Type: eventType, JobID: current.ID, Stage: current.Stage, Job: &current, Timestamp: time.Now().UTC()})
+			return eventType != models.EtlJobEventFinished
+		}
+	})
 }
 
 // GetMercadoLibreItem godoc
@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jopitnow/go-jopit-toolkit/goauth"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/services"
+)
+
+type EtlArtifactsHandler struct {
+	Service services.EtlArtifactsService
+}
+
+func NewEtlArtifactsHandler(service services.EtlArtifactsService) EtlArtifactsHandler {
+	return EtlArtifactsHandler{
+		Service: service,
+	}
+}
+
+// Download godoc
+// @Summary Download an ETL run artifact
+// @Description Download the raw extract, the transformed items, the failures, the before-image or the preview of a run of the user's shop
+// @Tags ETL
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param batch_id path string true "Batch ID of the run"
+// @Param kind path string true "Artifact: extracted, transformed, failed, snapshot or preview"
+// @Success 200 "Artifact JSON file"
+// @Failure 400 "Unknown artifact"
+// @Failure 401 "Unauthorized Firebase Token"
+// @Failure 404 "Artifact not found"
+// @Router /etl/artifacts/{batch_id}/{kind} [get]
+func (h EtlArtifactsHandler) Download(c *gin.Context) {
+	userID, apiErr := goauth.GetUserId(c)
+	if apiErr != nil {
+		c.Error(apiErr)
+		c.JSON(apiErr.Status(), apiErr)
+		return
+	}
+
+	ctx := context.WithValue(c.Request.Context(), goauth.FirebaseUserID, userID)
+	ctx = context.WithValue(ctx, goauth.FirebaseAuthHeader, c.GetHeader("Authorization"))
+
+	batchID := c.Param("batch_id")
+	kind := c.Param("kind")
+
+	artifact, size, apiErr := h.Service.Open(ctx, batchID, kind)
+	if apiErr != nil {
+		c.Error(apiErr)
+		c.JSON(apiErr.Status(), apiErr)
+		return
+	}
+	defer artifact.Close()
+
+	headers := map[string]string{
+		"Content-Disposition": fmt.Sprintf(`attachment; filename="%s-%s.json"`, batchID, kind),
+	}
+
+	c.DataFromReader(http.StatusOK, size, "application/json", artifact, headers)
+}
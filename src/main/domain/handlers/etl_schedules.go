@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/jopitnow/go-jopit-toolkit/goauth"
+	"github.com/jopitnow/go-jopit-toolkit/goutils/apierrors"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models/dto"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/services"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/utils"
+)
+
+type EtlSchedulesHandler struct {
+	Service services.EtlSchedulesService
+}
+
+func NewEtlSchedulesHandler(service services.EtlSchedulesService) EtlSchedulesHandler {
+	return EtlSchedulesHandler{
+		Service: service,
+	}
+}
+
+// Create godoc
+// @Summary Create ETL schedule
+// @Description Create a recurring import for the user's shop, driven by a UTC cron expression or an interval in minutes
+// @Tags ETL Schedules
+// @Param Authorization header string true "Bearer token"
+// @Param schedule body dto.EtlScheduleRequest true "Schedule"
+// @Accept json
+// @Produce json
+// @Success 201 {object} models.EtlSchedule
+// @Failure 400 "Bad Request - Invalid schedule"
+// @Failure 401 "Unauthorized Firebase Token"
+// @Failure 409 "The shop already has a schedule for the source"
+// @Router /etl/schedules [post]
+func (h EtlSchedulesHandler) Create(c *gin.Context) {
+	var input dto.EtlScheduleRequest
+
+	if err := binding.JSON.Bind(c.Request, &input); err != nil {
+		apiErr := apierrors.NewApiError(err.Error(), "bad_request", http.StatusBadRequest, apierrors.CauseList{})
+		c.Error(apiErr)
+		c.JSON(apiErr.Status(), apiErr)
+		return
+	}
+
+	userID, apiErr := goauth.GetUserId(c)
+	if apiErr != nil {
+		c.Error(apiErr)
+		c.JSON(apiErr.Status(), apiErr)
+		return
+	}
+
+	ctx := context.WithValue(c.Request.Context(), goauth.FirebaseUserID, userID)
+	ctx = context.WithValue(ctx, goauth.FirebaseAuthHeader, c.GetHeader("Authorization"))
+
+	schedule, apiErr := h.Service.Create(ctx, input)
+	if apiErr != nil {
+		c.Error(apiErr)
+		c.JSON(apiErr.Status(), apiErr)
+		return
+	}
+
+	c.JSON(http.StatusCreated, schedule)
+}
+
+// Get godoc
+// @Summary Get ETL schedule
+// @Description Get a schedule of the authenticated user
+// @Tags ETL Schedules
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Schedule ID"
+// @Produce json
+// @Success 200 {object} models.EtlSchedule
+// @Failure 401 "Unauthorized Firebase Token"
+// @Failure 404 "Schedule not found"
+// @Router /etl/schedules/{id} [get]
+func (h EtlSchedulesHandler) Get(c *gin.Context) {
+	scheduleID := c.Param("id")
+	if apiErr := utils.ValidateHexID([]string{scheduleID}); apiErr != nil {
+		c.Error(apiErr)
+		c.JSON(apiErr.Status(), apiErr)
+		return
+	}
+
+	userID, apiErr := goauth.GetUserId(c)
+	if apiErr != nil {
+		c.Error(apiErr)
+		c.JSON(apiErr.Status(), apiErr)
+		return
+	}
+
+	ctx := context.WithValue(c.Request.Context(), goauth.FirebaseUserID, userID)
+
+	schedule, apiErr := h.Service.Get(ctx, scheduleID)
+	if apiErr != nil {
+		c.Error(apiErr)
+		c.JSON(apiErr.Status(), apiErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+// GetByUserID godoc
+// @Summary List ETL schedules
+// @Description List the schedules of the authenticated user
+// @Tags ETL Schedules
+// @Param Authorization header string true "Bearer token"
+// @Produce json
+// @Success 200 {array} models.EtlSchedule
+// @Failure 401 "Unauthorized Firebase Token"
+// @Router /etl/schedules [get]
+func (h EtlSchedulesHandler) GetByUserID(c *gin.Context) {
+	userID, apiErr := goauth.GetUserId(c)
+	if apiErr != nil {
+		c.Error(apiErr)
+		c.JSON(apiErr.Status(), apiErr)
+		return
+	}
+
+	ctx := context.WithValue(c.Request.Context(), goauth.FirebaseUserID, userID)
+
+	schedules, apiErr := h.Service.GetByUserID(ctx)
+	if apiErr != nil {
+		c.Error(apiErr)
+		c.JSON(apiErr.Status(), apiErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, schedules)
+}
+
+// Update godoc
+// @Summary Update ETL schedule
+// @Description Replace the timing, mode and enabled flag of a schedule, the next run is recalculated
+// @Tags ETL Schedules
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Schedule ID"
+// @Param schedule body dto.EtlScheduleRequest true "Schedule"
+// @Accept json
+// @Produce json
+// @Success 200 {object} models.EtlSchedule
+// @Failure 400 "Bad Request - Invalid schedule"
+// @Failure 401 "Unauthorized Firebase Token"
+// @Failure 404 "Schedule not found"
+// @Router /etl/schedules/{id} [put]
+func (h EtlSchedulesHandler) Update(c *gin.Context) {
+	scheduleID := c.Param("id")
+	if apiErr := utils.ValidateHexID([]string{scheduleID}); apiErr != nil {
+		c.Error(apiErr)
+		c.JSON(apiErr.Status(), apiErr)
+		return
+	}
+
+	var input dto.EtlScheduleRequest
+
+	if err := binding.JSON.Bind(c.Request, &input); err != nil {
+		apiErr := apierrors.NewApiError(err.Error(), "bad_request", http.StatusBadRequest, apierrors.CauseList{})
+		c.Error(apiErr)
+		c.JSON(apiErr.Status(), apiErr)
+		return
+	}
+
+	userID, apiErr := goauth.GetUserId(c)
+	if apiErr != nil {
+		c.Error(apiErr)
+		c.JSON(apiErr.Status(), apiErr)
+		return
+	}
+
+	ctx := context.WithValue(c.Request.Context(), goauth.FirebaseUserID, userID)
+
+	schedule, apiErr := h.Service.Update(ctx, scheduleID, input)
+	if apiErr != nil {
+		c.Error(apiErr)
+		c.JSON(apiErr.Status(), apiErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+// Delete godoc
+// @Summary Delete ETL schedule
+// @Description Delete a schedule of the authenticated user, jobs already started keep running
+// @Tags ETL Schedules
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Schedule ID"
+// @Success 204
+// @Failure 401 "Unauthorized Firebase Token"
+// @Failure 404 "Schedule not found"
+// @Router /etl/schedules/{id} [delete]
+func (h EtlSchedulesHandler) Delete(c *gin.Context) {
+	scheduleID := c.Param("id")
+	if apiErr := utils.ValidateHexID([]string{scheduleID}); apiErr != nil {
+		c.Error(apiErr)
+		c.JSON(apiErr.Status(), apiErr)
+		return
+	}
+
+	userID, apiErr := goauth.GetUserId(c)
+	if apiErr != nil {
+		c.Error(apiErr)
+		c.JSON(apiErr.Status(), apiErr)
+		return
+	}
+
+	ctx := context.WithValue(c.Request.Context(), goauth.FirebaseUserID, userID)
+
+	if apiErr := h.Service.Delete(ctx, scheduleID); apiErr != nil {
+		c.Error(apiErr)
+		c.JSON(apiErr.Status(), apiErr)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
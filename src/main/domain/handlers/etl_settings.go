@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/jopitnow/go-jopit-toolkit/goauth"
+	"github.com/jopitnow/go-jopit-toolkit/goutils/apierrors"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models/dto"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/services"
+)
+
+type EtlSettingsHandler struct {
+	Service services.EtlSettingsService
+}
+
+func NewEtlSettingsHandler(service services.EtlSettingsService) EtlSettingsHandler {
+	return EtlSettingsHandler{
+		Service: service,
+	}
+}
+
+// Get godoc
+// @Summary Get ETL settings
+// @Description Get the preferences applied when transforming the items imported into the user's shop
+// @Tags ETL Settings
+// @Param Authorization header string true "Bearer token"
+// @Produce json
+// @Success 200 {object} models.EtlSettings
+// @Failure 401 "Unauthorized Firebase Token"
+// @Router /etl/settings [get]
+func (h EtlSettingsHandler) Get(c *gin.Context) {
+	userID, apiErr := goauth.GetUserId(c)
+	if apiErr != nil {
+		c.Error(apiErr)
+		c.JSON(apiErr.Status(), apiErr)
+		return
+	}
+
+	ctx := context.WithValue(c.Request.Context(), goauth.FirebaseUserID, userID)
+	ctx = context.WithValue(ctx, goauth.FirebaseAuthHeader, c.GetHeader("Authorization"))
+
+	settings, apiErr := h.Service.Get(ctx)
+	if apiErr != nil {
+		c.Error(apiErr)
+		c.JSON(apiErr.Status(), apiErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+// Update godoc
+// @Summary Update ETL settings
+// @Description Replace the preferences applied when transforming the items imported into the user's shop
+// @Tags ETL Settings
+// @Param Authorization header string true "Bearer token"
+// @Param settings body dto.EtlSettingsRequest true "Settings"
+// @Accept json
+// @Produce json
+// @Success 200 {object} models.EtlSettings
+// @Failure 400 "Bad Request - Invalid settings"
+// @Failure 401 "Unauthorized Firebase Token"
+// @Router /etl/settings [put]
+func (h EtlSettingsHandler) Update(c *gin.Context) {
+	var input dto.EtlSettingsRequest
+
+	if err := binding.JSON.Bind(c.Request, &input); err != nil {
+		apiErr := apierrors.NewApiError(err.Error(), "bad_request", http.StatusBadRequest, apierrors.CauseList{})
+		c.Error(apiErr)
+		c.JSON(apiErr.Status(), apiErr)
+		return
+	}
+
+	userID, apiErr := goauth.GetUserId(c)
+	if apiErr != nil {
+		c.Error(apiErr)
+		c.JSON(apiErr.Status(), apiErr)
+		return
+	}
+
+	ctx := context.WithValue(c.Request.Context(), goauth.FirebaseUserID, userID)
+	ctx = context.WithValue(ctx, goauth.FirebaseAuthHeader, c.GetHeader("Authorization"))
+
+	settings, apiErr := h.Service.Update(ctx, input)
+	if apiErr != nil {
+		c.Error(apiErr)
+		c.JSON(apiErr.Status(), apiErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/jopitnow/go-jopit-toolkit/goutils/apierrors"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models/dto"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/services"
+)
+
+type MercadoLibreNotificationsHandler struct {
+	service services.MercadoLibreNotificationsService
+}
+
+func NewMercadoLibreNotificationsHandler(
+	service services.MercadoLibreNotificationsService,
+) MercadoLibreNotificationsHandler {
+	return MercadoLibreNotificationsHandler{
+		service: service,
+	}
+}
+
+// Receive godoc
+// @Summary Receive MercadoLibre notifications
+// @Description Callback registered in the MercadoLibre application. Item notifications of linked sellers are queued and the item is re-imported.
+// @Tags MercadoLibre Notifications
+// @Param notification body dto.MeliNotification true "MercadoLibre notification"
+// @Accept json
+// @Success 200 "Notification accepted"
+// @Failure 400 "Bad Request - Invalid notification"
+// @Failure 403 "Notification of another application"
+// @Failure 503 "Notifications queue is full"
+// @Router /etl/mercadolibre/notifications [post]
+func (h *MercadoLibreNotificationsHandler) Receive(c *gin.Context) {
+	var notification dto.MeliNotification
+	if err := binding.JSON.Bind(c.Request, &notification); err != nil {
+		apiErr := apierrors.NewApiError(err.Error(), "bad_request", http.StatusBadRequest, apierrors.CauseList{})
+		c.Error(apiErr)
+		c.JSON(apiErr.Status(), apiErr)
+		return
+	}
+
+	if apiErr := h.service.Receive(c.Request.Context(), notification); apiErr != nil {
+		c.Error(apiErr)
+		c.JSON(apiErr.Status(), apiErr)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
@@ -3,11 +3,13 @@ package handlers
 import (
 	"context"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/binding"
 	"github.com/jopitnow/go-jopit-toolkit/goauth"
 	"github.com/jopitnow/go-jopit-toolkit/goutils/apierrors"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models"
 	"github.com/jopitnow/jopit-api-etl/src/main/domain/models/dto"
 	"github.com/jopitnow/jopit-api-etl/src/main/domain/services"
 )
@@ -26,11 +28,13 @@ func NewMercadoLibreCredentialsHandler(
 
 // GetOAuthURL godoc
 // @Summary Get MercadoLibre OAuth URL
-// @Description Get MercadoLibre OAuth URL for authentication.
+// @Description Get MercadoLibre OAuth URL for authentication on the seller's site, Argentina by default.
 // @Tags MercadoLibre OAuth Credentials
 // @Param Authorization header string true "Bearer token"
+// @Param site_id query string false "MercadoLibre site, like MLA, MLB, MLM, MLU or MLC"
 // @Produce json
 // @Success 200 {object} models.MercadoLibreURL
+// @Failure 400 "Bad Request - Unsupported site"
 // @Failure 401 "Unauthorized Firebase Token"
 // @Failure 500 "Internal Server Error"
 // @Router /mercadolibre/oauth [get]
@@ -44,7 +48,9 @@ func (h *MercadoLibreCredentialsHandler) GetOAuthURL(c *gin.Context) {
 
 	ctx := context.WithValue(c.Request.Context(), goauth.FirebaseUserID, userID)
 
-	url, err := h.service.GetOAuthURL(ctx)
+	siteID := strings.ToUpper(strings.TrimSpace(c.DefaultQuery("site_id", models.DefaultMeliSiteID)))
+
+	url, err := h.service.GetOAuthURL(ctx, siteID)
 	if err != nil {
 		c.Error(err)
 		c.JSON(err.Status(), err)
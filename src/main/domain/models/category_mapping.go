@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+// CategoryMapping maps a MercadoLibre category, or every category of a MercadoLibre domain, to a Jopit category.
+// Mappings without a shop are global, a shop's own mappings take precedence over them.
+type CategoryMapping struct {
+	ID              string    `json:"id" bson:"_id,omitempty"`
+	ShopID          string    `json:"shop_id,omitempty" bson:"shop_id"`
+	UserID          string    `json:"user_id,omitempty" bson:"user_id,omitempty"`
+	MeliCategoryID  string    `json:"meli_category_id,omitempty" bson:"meli_category_id"`
+	MeliDomainID    string    `json:"meli_domain_id,omitempty" bson:"meli_domain_id"`
+	CategoryID      string    `json:"category_id" bson:"category_id"`
+	CategoryName    string    `json:"category_name" bson:"category_name"`
+	SubcategoryID   string    `json:"subcategory_id,omitempty" bson:"subcategory_id,omitempty"`
+	SubcategoryName string    `json:"subcategory_name,omitempty" bson:"subcategory_name,omitempty"`
+	CreatedAt       time.Time `json:"created_at" bson:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at" bson:"updated_at"`
+}
+
+// IsGlobal reports whether the mapping applies to every shop
+func (m CategoryMapping) IsGlobal() bool {
+	return m.ShopID == ""
+}
+
+// ToItemCategory returns the Jopit category the mapping points to
+func (m CategoryMapping) ToItemCategory() ItemCategory {
+	category := ItemCategory{
+		ID:   m.CategoryID,
+		Name: m.CategoryName,
+	}
+
+	if m.SubcategoryID != "" {
+		category.Subcategory = &Subcategory{
+			ID:   m.SubcategoryID,
+			Name: m.SubcategoryName,
+		}
+	}
+
+	return category
+}
+
+// CategoryMappingSuggestion proposes a Jopit category for a MercadoLibre category of a shop's catalog that
+// has no mapping yet. Suggested is nil when nothing matched; otherwise it can be created as is.
+type CategoryMappingSuggestion struct {
+	MeliCategoryID   string           `json:"meli_category_id"`
+	MeliDomainID     string           `json:"meli_domain_id,omitempty"`
+	MeliCategoryPath []string         `json:"meli_category_path,omitempty"`
+	ItemsCount       int              `json:"items_count"`
+	Suggested        *CategoryMapping `json:"suggested,omitempty"`
+	Confidence       float64          `json:"confidence"`
+	Reason           string           `json:"reason,omitempty"`
+}
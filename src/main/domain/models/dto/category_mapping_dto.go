@@ -0,0 +1,25 @@
+package dto
+
+import "github.com/jopitnow/jopit-api-etl/src/main/domain/models"
+
+// CategoryMappingRequest creates or replaces a category mapping, exactly one of MeliCategoryID or MeliDomainID must be set
+// and the subcategory is optional, but its id and name go together
+type CategoryMappingRequest struct {
+	MeliCategoryID  string `json:"meli_category_id"`
+	MeliDomainID    string `json:"meli_domain_id"`
+	CategoryID      string `json:"category_id" binding:"required,len=24,hexadecimal"`
+	CategoryName    string `json:"category_name" binding:"required"`
+	SubcategoryID   string `json:"subcategory_id" binding:"omitempty,len=24,hexadecimal"`
+	SubcategoryName string `json:"subcategory_name"`
+}
+
+func (r *CategoryMappingRequest) ToModel() models.CategoryMapping {
+	return models.CategoryMapping{
+		MeliCategoryID:  r.MeliCategoryID,
+		MeliDomainID:    r.MeliDomainID,
+		CategoryID:      r.CategoryID,
+		CategoryName:    r.CategoryName,
+		SubcategoryID:   r.SubcategoryID,
+		SubcategoryName: r.SubcategoryName,
+	}
+}
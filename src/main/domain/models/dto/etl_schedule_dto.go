@@ -0,0 +1,27 @@
+package dto
+
+import "github.com/jopitnow/jopit-api-etl/src/main/domain/models"
+
+// EtlScheduleRequest creates or replaces a shop import schedule, exactly one of Cron or IntervalMinutes must be set
+type EtlScheduleRequest struct {
+	Source          string `json:"source" binding:"required,oneof=mercadolibre api"`
+	Cron            string `json:"cron"`
+	IntervalMinutes int    `json:"interval_minutes" binding:"gte=0"`
+	Mode            string `json:"mode" binding:"omitempty,oneof=full incremental"`
+	Enabled         *bool  `json:"enabled"`
+}
+
+func (r *EtlScheduleRequest) ToModel() models.EtlSchedule {
+	enabled := true
+	if r.Enabled != nil {
+		enabled = *r.Enabled
+	}
+
+	return models.EtlSchedule{
+		Source:          r.Source,
+		Cron:            r.Cron,
+		IntervalMinutes: r.IntervalMinutes,
+		Options:         models.EtlLoadOptions{Incremental: r.Mode == models.EtlModeIncremental},
+		Enabled:         enabled,
+	}
+}
@@ -0,0 +1,46 @@
+package dto
+
+import (
+	"strings"
+
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models"
+)
+
+// EtlSettingsRequest replaces the ETL settings of the user's shop
+type EtlSettingsRequest struct {
+	DefaultDimensions *EtlDimensionsRequest `json:"default_dimensions"`
+	// ColorHexes maps color names, as sellers write them, to hex codes like #1A2B3C
+	ColorHexes map[string]string `json:"color_hexes" binding:"omitempty,dive,keys,required,endkeys,hexcolor"`
+	// BaseCurrency is an ISO 4217 code imported prices are converted to, like USD
+	BaseCurrency string `json:"base_currency" binding:"omitempty,iso4217"`
+}
+
+// EtlDimensionsRequest is a package size, weight in grams and lengths in centimeters
+type EtlDimensionsRequest struct {
+	Weight int `json:"weight" binding:"required,gt=0"`
+	Length int `json:"length" binding:"required,gt=0"`
+	Height int `json:"height" binding:"required,gt=0"`
+	Width  int `json:"width" binding:"required,gt=0"`
+}
+
+func (r *EtlSettingsRequest) ToModel() models.EtlSettings {
+	settings := models.EtlSettings{BaseCurrency: strings.ToUpper(strings.TrimSpace(r.BaseCurrency))}
+
+	if r.DefaultDimensions != nil {
+		settings.DefaultDimensions = &models.Dimensions{
+			Weight: r.DefaultDimensions.Weight,
+			Length: r.DefaultDimensions.Length,
+			Height: r.DefaultDimensions.Height,
+			Width:  r.DefaultDimensions.Width,
+		}
+	}
+
+	if len(r.ColorHexes) > 0 {
+		settings.ColorHexes = make(map[string]string, len(r.ColorHexes))
+		for name, hex := range r.ColorHexes {
+			settings.ColorHexes[strings.TrimSpace(name)] = strings.ToUpper(hex)
+		}
+	}
+
+	return settings
+}
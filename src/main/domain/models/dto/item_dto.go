@@ -8,6 +8,17 @@ type BulkCreateItemsRequest struct {
 	Items []models.Item `json:"items" binding:"required"`
 }
 
+type BulkDeleteItemsRequest struct {
+	ShopID  string `json:"shop_id" binding:"required"`
+	BatchID string `json:"batch_id" binding:"required"`
+}
+
+// BulkDeleteItemsByIDRequest deletes some items of a shop, whatever batch loaded them
+type BulkDeleteItemsByIDRequest struct {
+	ShopID   string   `json:"shop_id" binding:"required"`
+	ItemsIDs []string `json:"items_ids" binding:"required"`
+}
+
 type BulkUpsertResponse struct {
 	TotalItems   int64 `json:"total_items"`
 	CreatedCount int64 `json:"created_count"`
@@ -1,8 +1,12 @@
 package dto
 
+import "time"
+
 // MercadoLibreAuthRedirectDTO is the request body when MercadoLibre redirects with auth code
 type MercadoLibreAuthRedirectDTO struct {
 	Code string `json:"code" binding:"required"`
+	// SiteID is the state MercadoLibre redirected with, the site the seller was sent to authorize on
+	SiteID string `json:"site_id"`
 }
 
 // MercadoLibreAuthRequestDTO is the request body for OAuth token exchange
@@ -36,6 +40,18 @@ type MercadoLibreUserResponse struct {
 	SiteID    string `json:"site_id"`
 }
 
+// MeliNotification is the payload MercadoLibre posts to the notifications callback
+type MeliNotification struct {
+	ID            string `json:"_id"`
+	Resource      string `json:"resource" binding:"required"`
+	UserID        int64  `json:"user_id" binding:"required"`
+	Topic         string `json:"topic" binding:"required"`
+	ApplicationID int64  `json:"application_id"`
+	Attempts      int    `json:"attempts"`
+	Sent          string `json:"sent"`
+	Received      string `json:"received"`
+}
+
 // MercadoLibreSearchFilters contains params for the search endpoint
 type MercadoLibreSearchFilters struct {
 	SiteID   string            `json:"site_id"`
@@ -61,6 +77,8 @@ type MeliAttributeValue struct {
 	ID     *string     `json:"id"`
 	Name   string      `json:"name"`
 	Struct interface{} `json:"struct"`
+	// Metadata carries extra value data, such as the rgb of a color
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
 }
 
 // MeliAttribute represents item attributes
@@ -100,6 +118,9 @@ type MeliVariation struct {
 	InventoryID           *string         `json:"inventory_id"`
 	ItemRelations         []interface{}   `json:"item_relations"`
 	UserProductID         string          `json:"user_product_id"`
+
+	// Attributes are only returned when the item is requested with include_attributes=all, SELLER_SKU lives here
+	Attributes []MeliAttribute `json:"attributes"`
 }
 
 // MeliItemResponse represents a full item from MercadoLibre
@@ -146,6 +167,65 @@ type MeliItemResponse struct {
 	LastUpdated        string          `json:"last_updated"`
 	CatalogListing     bool            `json:"catalog_listing"`
 	Channels           []string        `json:"channels"`
+
+	// Description is not part of the item resource, it is fetched from /items/{id}/description during extraction
+	Description *MeliItemDescription `json:"description,omitempty"`
+	// SalePrices is not part of the item resource either, it is fetched from /items/{id}/prices for discounted items
+	SalePrices []MeliItemPrice `json:"sale_prices,omitempty"`
+}
+
+// MeliMultigetResult is the outcome of one id of a multiget call, Item is only set when Code is 200
+type MeliMultigetResult struct {
+	ID      string
+	Code    int
+	Item    MeliItemResponse
+	Message string
+}
+
+// MeliItemDescription is the seller's product copy of an item, plain text or HTML
+type MeliItemDescription struct {
+	Text        string `json:"text"`
+	PlainText   string `json:"plain_text"`
+	LastUpdated string `json:"last_updated"`
+	DateCreated string `json:"date_created"`
+}
+
+// MeliItemPricesResponse lists the prices an item sells at, its standard price and the promotions running on it
+type MeliItemPricesResponse struct {
+	ID     string          `json:"id"`
+	Prices []MeliItemPrice `json:"prices"`
+}
+
+// MeliItemPrice is one price of an item, RegularAmount is the price struck through while a promotion runs
+type MeliItemPrice struct {
+	ID            string                 `json:"id"`
+	Type          string                 `json:"type"`
+	Amount        float64                `json:"amount"`
+	RegularAmount *float64               `json:"regular_amount"`
+	CurrencyID    string                 `json:"currency_id"`
+	LastUpdated   string                 `json:"last_updated"`
+	Conditions    MeliPriceConditions    `json:"conditions"`
+	Metadata      map[string]interface{} `json:"metadata"`
+}
+
+// MeliPriceConditions tells who and when a price applies to, restrictions limit it to channels or buyers
+type MeliPriceConditions struct {
+	ContextRestrictions []string `json:"context_restrictions"`
+	StartTime           *string  `json:"start_time"`
+	EndTime             *string  `json:"end_time"`
+}
+
+// MeliCategoryResponse is a MercadoLibre category with its ancestors, root first and the category itself last
+type MeliCategoryResponse struct {
+	ID           string             `json:"id"`
+	Name         string             `json:"name"`
+	PathFromRoot []MeliCategoryNode `json:"path_from_root"`
+}
+
+// MeliCategoryNode is a category of a MercadoLibre category path
+type MeliCategoryNode struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
 }
 
 // MeliUserItemsSearchResponse represents the search response with item IDs
@@ -156,6 +236,8 @@ type MeliUserItemsSearchResponse struct {
 		Offset int `json:"offset"`
 		Limit  int `json:"limit"`
 	} `json:"paging"`
+	// ScrollID continues a scan search, only set when search_type=scan
+	ScrollID string `json:"scroll_id,omitempty"`
 }
 
 // MeliSearchResponse represents the public search response
@@ -215,3 +297,10 @@ type MeliSizeChartResponse struct {
 	TemplateID      string               `json:"template_id"`
 	ConversionType  string               `json:"conversion_type,omitempty"`
 }
+
+// MeliCachedSizeChart is a size chart kept locally, the items of a seller share a handful of charts
+type MeliCachedSizeChart struct {
+	ID        string                `json:"id" bson:"_id"`
+	Chart     MeliSizeChartResponse `json:"chart" bson:"chart"`
+	FetchedAt time.Time             `json:"fetched_at" bson:"fetched_at"`
+}
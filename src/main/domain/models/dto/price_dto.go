@@ -1,5 +1,7 @@
 package dto
 
+import "github.com/jopitnow/jopit-api-etl/src/main/domain/models"
+
 type PriceDTO struct {
 	ID       string      `json:"id"`
 	Amount   float64     `json:"amount" binding:"required"`
@@ -16,3 +18,7 @@ type CurrencyDTO struct {
 type RequestItemsList struct {
 	ItemsIDs []string `json:"items_ids" binding:"required"`
 }
+
+type BulkUpsertPricesRequest struct {
+	Prices []models.Price `json:"prices" binding:"required"`
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// EtlBatchSnapshot is captured right before a run loads its items, so the run can be rolled back
+type EtlBatchSnapshot struct {
+	BatchID    string    `json:"batch_id"`
+	ShopID     string    `json:"shop_id"`
+	SourceType string    `json:"source_type"`
+	Created    []string  `json:"created"` // external ids the run adds to the shop
+	Updated    []Item    `json:"updated"` // shop items the run overwrites, as they were before it
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// EtlRollbackResult tells what a rollback did, items changed again by a later run are skipped
+type EtlRollbackResult struct {
+	BatchID  string   `json:"batch_id"`
+	Restored []string `json:"restored"`
+	Deleted  []string `json:"deleted"`
+	Skipped  []string `json:"skipped,omitempty"`
+}
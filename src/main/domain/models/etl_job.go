@@ -0,0 +1,127 @@
+package models
+
+import "time"
+
+const (
+	EtlJobTypeMercadoLibre = "mercadolibre"
+	EtlJobTypeApi          = "api"
+	EtlJobTypePreview      = "mercadolibre_preview"
+
+	EtlJobStatusQueued    = "queued"
+	EtlJobStatusRunning   = "running"
+	EtlJobStatusCompleted = "completed"
+	EtlJobStatusFailed    = "failed"
+	EtlJobStatusCancelled = "cancelled"
+
+	EtlStageExtract   = "extract"
+	EtlStageTransform = "transform"
+	EtlStageLoad      = "load"
+
+	EtlModeFull        = "full"
+	EtlModeIncremental = "incremental"
+)
+
+// EtlLoadOptions tunes how a load run processes the source catalog
+type EtlLoadOptions struct {
+	// Incremental only re-imports the items updated at the source since the last successful run
+	Incremental bool `json:"incremental" bson:"incremental"`
+}
+
+// EtlJob is the persisted record of an asynchronous ETL run. Only the first failed and loaded items and warnings
+// are listed, the counts and the batch artifacts cover them all.
+type EtlJob struct {
+	ID             string             `json:"id" bson:"_id,omitempty"`
+	ShopID         string             `json:"shop_id" bson:"shop_id,omitempty"`
+	UserID         string             `json:"user_id" bson:"user_id"`
+	Type           string             `json:"type" bson:"type"`
+	Status         string             `json:"status" bson:"status"`
+	Stage          string             `json:"stage,omitempty" bson:"stage,omitempty"`
+	BatchID        string             `json:"batch_id,omitempty" bson:"batch_id,omitempty"`
+	Options        EtlLoadOptions     `json:"options" bson:"options"`
+	ScheduleID     string             `json:"schedule_id,omitempty" bson:"schedule_id,omitempty"`
+	TotalItems     int                `json:"total_items" bson:"total_items"`
+	ProcessedItems int                `json:"processed_items" bson:"processed_items"`
+	CreatedCount   int                `json:"created_count" bson:"created_count"`
+	UpdatedCount   int                `json:"updated_count" bson:"updated_count"`
+	FailureCount   int                `json:"failure_count" bson:"failure_count"`
+	SkippedCount   int                `json:"skipped_count" bson:"skipped_count"`
+	FailedItems    []FailedItem       `json:"failed_items,omitempty" bson:"failed_items,omitempty"`
+	Warnings       []TransformWarning `json:"warnings,omitempty" bson:"warnings,omitempty"`
+	LoadedItems    []string           `json:"loaded_items,omitempty" bson:"loaded_items,omitempty"`
+	// HeartbeatAt is renewed by the replica holding the job, jobs only live in that replica's memory
+	HeartbeatAt time.Time `json:"-" bson:"heartbeat_at"`
+	// Only ever set to true, omitempty keeps progress updates from clearing it
+	CancelRequested bool       `json:"cancel_requested,omitempty" bson:"cancel_requested,omitempty"`
+	Error           string     `json:"error,omitempty" bson:"error,omitempty"`
+	CreatedAt       time.Time  `json:"created_at" bson:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at" bson:"updated_at"`
+	StartedAt       *time.Time `json:"started_at,omitempty" bson:"started_at,omitempty"`
+	FinishedAt      *time.Time `json:"finished_at,omitempty" bson:"finished_at,omitempty"`
+}
+
+// FailedItem represents an item that failed during ETL
+type FailedItem struct {
+	ExternalID   string `json:"external_id" bson:"external_id"`
+	Title        string `json:"title,omitempty" bson:"title,omitempty"`
+	FailureStage string `json:"failure_stage" bson:"failure_stage"` // "transform" or "load"
+	ErrorMessage string `json:"error_message" bson:"error_message"`
+}
+
+const (
+	TransformWarningUnmappedCategory = "unmapped_category"
+	TransformWarningUnknownColor     = "unknown_color"
+	TransformWarningMissingFxRate    = "missing_fx_rate"
+	LoadWarningPriceNotSaved         = "price_not_saved"
+)
+
+// TransformWarning is an item that was imported but with part of its data guessed, defaulted or not saved
+type TransformWarning struct {
+	ExternalID string `json:"external_id" bson:"external_id"`
+	Title      string `json:"title,omitempty" bson:"title,omitempty"`
+	Code       string `json:"code" bson:"code"`
+	Message    string `json:"message" bson:"message"`
+}
+
+func (j *EtlJob) IsFinished() bool {
+	return j.Status == EtlJobStatusCompleted || j.Status == EtlJobStatusFailed || j.Status == EtlJobStatusCancelled
+}
+
+const (
+	EtlJobEventSnapshot = "snapshot"
+	EtlJobEventStage    = "stage"
+	EtlJobEventPage     = "page"
+	EtlJobEventItem     = "item"
+	EtlJobEventFinished = "finished"
+
+	EtlItemTransformed = "transformed"
+	EtlItemLoaded      = "loaded"
+	EtlItemFailed      = "failed"
+)
+
+// EtlJobEvent is a progress notification streamed to the clients following a job
+type EtlJobEvent struct {
+	Type       string      `json:"type"`
+	JobID      string      `json:"job_id"`
+	Stage      string      `json:"stage,omitempty"`
+	Page       int         `json:"page,omitempty"`
+	Current    int         `json:"current,omitempty"`
+	Total      int         `json:"total,omitempty"`
+	ExternalID string      `json:"external_id,omitempty"`
+	ItemStatus string      `json:"item_status,omitempty"`
+	Failure    *FailedItem `json:"failure,omitempty"`
+	Job        *EtlJob     `json:"job,omitempty"`
+	Timestamp  time.Time   `json:"timestamp"`
+}
+
+const (
+	EtlArtifactExtracted   = "extracted"
+	EtlArtifactTransformed = "transformed"
+	EtlArtifactFailed      = "failed"
+	EtlArtifactSnapshot    = "snapshot"
+	EtlArtifactPreview     = "preview"
+)
+
+// IsEtlArtifact reports whether kind is one of the artifacts saved for every run, or the preview of a preview run
+func IsEtlArtifact(kind string) bool {
+	return kind == EtlArtifactExtracted || kind == EtlArtifactTransformed || kind == EtlArtifactFailed || kind == EtlArtifactSnapshot || kind == EtlArtifactPreview
+}
@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+const (
+	EtlScheduleSourceMercadoLibre = "mercadolibre"
+	EtlScheduleSourceApi          = "api"
+)
+
+// EtlSchedule triggers recurring imports of a shop, either on a cron expression or every IntervalMinutes
+type EtlSchedule struct {
+	ID              string         `json:"id" bson:"_id,omitempty"`
+	ShopID          string         `json:"shop_id" bson:"shop_id"`
+	UserID          string         `json:"user_id" bson:"user_id"`
+	Source          string         `json:"source" bson:"source"`
+	Cron            string         `json:"cron,omitempty" bson:"cron,omitempty"` // UTC, minute hour day-of-month month day-of-week
+	IntervalMinutes int            `json:"interval_minutes,omitempty" bson:"interval_minutes,omitempty"`
+	Options         EtlLoadOptions `json:"options" bson:"options"`
+	Enabled         bool           `json:"enabled" bson:"enabled"`
+	NextRunAt       time.Time      `json:"next_run_at" bson:"next_run_at"`
+	LastRunAt       *time.Time     `json:"last_run_at,omitempty" bson:"last_run_at,omitempty"`
+	LastJobID       string         `json:"last_job_id,omitempty" bson:"last_job_id,omitempty"`
+	LastError       string         `json:"last_error,omitempty" bson:"last_error,omitempty"`
+	CreatedAt       time.Time      `json:"created_at" bson:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at" bson:"updated_at"`
+}
+
+// JobType returns the ETL job type started by the schedule
+func (s *EtlSchedule) JobType() string {
+	if s.Source == EtlScheduleSourceApi {
+		return EtlJobTypeApi
+	}
+	return EtlJobTypeMercadoLibre
+}
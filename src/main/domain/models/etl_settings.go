@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// EtlSettings are a shop's preferences for transforming its imported items
+type EtlSettings struct {
+	ID     string `json:"id,omitempty" bson:"_id,omitempty"`
+	ShopID string `json:"shop_id" bson:"shop_id"`
+	UserID string `json:"user_id" bson:"user_id"`
+	// DefaultDimensions replace the Jopit defaults for items whose source has no package data
+	DefaultDimensions *Dimensions `json:"default_dimensions,omitempty" bson:"default_dimensions,omitempty"`
+	// ColorHexes extend the color name to hex dictionary used for variant swatches, and win over it
+	ColorHexes map[string]string `json:"color_hexes,omitempty" bson:"color_hexes,omitempty"`
+	// BaseCurrency is the currency imported prices are converted to, empty keeps the source's currency
+	BaseCurrency string    `json:"base_currency,omitempty" bson:"base_currency,omitempty"`
+	UpdatedAt    time.Time `json:"updated_at" bson:"updated_at"`
+}
@@ -6,6 +6,12 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+const (
+	MeliSourceType = "meli"
+	CsvSourceType  = "csv"
+	ApiSourceType  = "api"
+)
+
 type Items struct {
 	Items []Item `json:"items"`
 }
@@ -96,6 +102,13 @@ type Size struct {
 	GarmentWaistWidth    *int   `json:"garment_waist_width,omitempty" bson:"garment_waist_width,$set,omitempty"`
 }
 
+const (
+	SizeGuideBodyPartUpper = "upper"
+	SizeGuideBodyPartLower = "lower"
+	SizeGuideBodyPartFull  = "full_body"
+	SizeGuideBodyPartFeet  = "feet"
+)
+
 type SizeGuide struct {
 	Type               string `json:"type" bson:"type,$set"`
 	BodyPart           string `json:"body_part" bson:"body_part,$set"`
@@ -110,6 +123,10 @@ type SizeStock struct {
 	SizeLabel string `json:"size_label" bson:"size_label,$set"`
 	Stock     int    `json:"stock" bson:"stock,$set"`
 	SKU       string `json:"sku,omitempty" bson:"sku,$set,omitempty"`
+	// Price is set when the size sells at a price other than the item's
+	Price *float64 `json:"price,omitempty" bson:"price,$set,omitempty"`
+	// InventoryID is the warehouse inventory the size's stock is kept in
+	InventoryID string `json:"inventory_id,omitempty" bson:"inventory_id,$set,omitempty"`
 }
 
 type Variant struct {
@@ -187,3 +204,25 @@ func (i *Item) ValidateEmptySlices() {
 		i.SizeGuide.Sizes = []Size{}
 	}
 }
+
+const (
+	ItemDiffCreate    = "create"
+	ItemDiffUpdate    = "update"
+	ItemDiffUnchanged = "unchanged"
+)
+
+// ItemDiff describes how loading a transformed item would change the shop's catalog
+type ItemDiff struct {
+	ExternalID string            `json:"external_id"`
+	ItemID     string            `json:"item_id,omitempty"` // existing Jopit item, empty when it would be created
+	Name       string            `json:"name"`
+	Action     string            `json:"action"`
+	Changes    []ItemFieldChange `json:"changes,omitempty"`
+}
+
+// ItemFieldChange holds the current and the incoming value of a changed item field
+type ItemFieldChange struct {
+	Field  string      `json:"field"`
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
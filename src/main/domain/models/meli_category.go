@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// MeliCategory is a MercadoLibre category kept locally, so suggesting mappings doesn't hit MercadoLibre per item
+type MeliCategory struct {
+	ID   string `json:"id" bson:"_id"`
+	Name string `json:"name" bson:"name"`
+	// PathFromRoot lists the category's ancestors, root first and the category itself last
+	PathFromRoot []MeliCategoryNode `json:"path_from_root" bson:"path_from_root"`
+	FetchedAt    time.Time          `json:"fetched_at" bson:"fetched_at"`
+}
+
+type MeliCategoryNode struct {
+	ID   string `json:"id" bson:"id"`
+	Name string `json:"name" bson:"name"`
+}
+
+// PathNames returns the names of the category path, root first
+func (c MeliCategory) PathNames() []string {
+	names := make([]string, 0, len(c.PathFromRoot))
+	for _, node := range c.PathFromRoot {
+		names = append(names, node.Name)
+	}
+	return names
+}
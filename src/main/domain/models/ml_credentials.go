@@ -4,6 +4,12 @@ import "time"
 
 const MeliType = "MELI"
 
+// DefaultMeliSiteID is the site of credentials stored before sellers could connect from other countries
+const DefaultMeliSiteID = "MLA"
+
+// meliTokenRefreshMargin is how long before their expiry tokens are refreshed
+const meliTokenRefreshMargin = 1 * time.Hour
+
 type MercadoLibreURL struct {
 	URL string `json:"url,omitempty"`
 }
@@ -18,6 +24,21 @@ type MercadoLibreCredential struct {
 	ExpiresIn    int       `json:"expires_in" bson:"expires_in,omitempty"`
 	Scope        string    `json:"scope,omitempty" bson:"scope,omitempty"`
 	UserIDMeli   int64     `json:"user_id_meli,omitempty" bson:"user_id_meli,omitempty"` // MercadoLibre's user ID
+	SiteID       string    `json:"site_id,omitempty" bson:"site_id,omitempty"`           // MercadoLibre site the seller sells on, like MLA or MLU
 	UpdatedAt    time.Time `json:"updated_at" bson:"updated_at,omitempty"`
 	CreatedAt    time.Time `json:"created_at" bson:"created_at,omitempty"`
 }
+
+// Site returns the MercadoLibre site of the seller, Argentina for credentials saved without one
+func (c MercadoLibreCredential) Site() string {
+	if c.SiteID == "" {
+		return DefaultMeliSiteID
+	}
+	return c.SiteID
+}
+
+// NeedsRefresh reports whether the access token expired or expires within the refresh margin
+func (c MercadoLibreCredential) NeedsRefresh() bool {
+	expiresAt := c.UpdatedAt.Add(time.Duration(c.ExpiresIn) * time.Second)
+	return time.Now().UTC().After(expiresAt.Add(-meliTokenRefreshMargin))
+}
@@ -1,11 +1,24 @@
 package models
 
+import "time"
+
 type Price struct {
-	ID       string   `json:"id"`
-	ItemID   string   `json:"item_id"`
-	ShopID   string   `json:"shop_id"`
-	Amount   float64  `json:"amount"`
-	Currency Currency `json:"currency"`
+	ID     string  `json:"id"`
+	ItemID string  `json:"item_id"`
+	ShopID string  `json:"shop_id"`
+	Amount float64 `json:"amount"`
+	// ListAmount is the regular price shown struck through while the item sells at Amount, nil when not discounted
+	ListAmount *float64   `json:"list_amount,omitempty"`
+	Promotion  *Promotion `json:"promotion,omitempty"`
+	Currency   Currency   `json:"currency"`
+}
+
+// Promotion is the discount behind a sale price, its window is open ended when a bound is missing
+type Promotion struct {
+	ID       string     `json:"id,omitempty"`
+	Type     string     `json:"type,omitempty"`
+	StartsAt *time.Time `json:"starts_at,omitempty"`
+	EndsAt   *time.Time `json:"ends_at,omitempty"`
 }
 
 type Currency struct {
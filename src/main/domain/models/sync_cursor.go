@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// SyncCursor tracks what an incremental MercadoLibre sync already loaded for a shop
+type SyncCursor struct {
+	ID         string            `json:"id,omitempty" bson:"_id,omitempty"`
+	ShopID     string            `json:"shop_id" bson:"shop_id"`
+	SourceType string            `json:"source_type" bson:"source_type"`
+	LastRunAt  time.Time         `json:"last_run_at" bson:"last_run_at"`
+	Items      map[string]string `json:"items" bson:"items"` // external id -> source last_updated
+	UpdatedAt  time.Time         `json:"updated_at" bson:"updated_at"`
+}
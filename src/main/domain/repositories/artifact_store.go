@@ -0,0 +1,168 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jopitnow/go-jopit-toolkit/goutils/apierrors"
+	"go.opentelemetry.io/otel"
+)
+
+const (
+	ArtifactStoreError = "[%s] Error in artifact store"
+)
+
+var tracerArtifactStore = otel.Tracer("artifact-store")
+
+// ArtifactStore is a blob store for run artifacts, keys are slash separated paths
+type ArtifactStore interface {
+	Save(ctx context.Context, key string, content []byte) apierrors.ApiError
+	Open(ctx context.Context, key string) (io.ReadCloser, int64, apierrors.ApiError)
+	// DeleteOlderThan removes every batch whose artifacts were all written before the cutoff, returning how many
+	DeleteOlderThan(ctx context.Context, cutoff time.Time) (int, apierrors.ApiError)
+}
+
+type localArtifactStore struct {
+	basePath string
+}
+
+// NewLocalArtifactStore stores the artifacts as files under basePath, it should be a persistent volume shared by the replicas
+func NewLocalArtifactStore(basePath string) ArtifactStore {
+	return &localArtifactStore{
+		basePath: basePath,
+	}
+}
+
+func (s *localArtifactStore) Save(ctx context.Context, key string, content []byte) apierrors.ApiError {
+	_, span := tracerArtifactStore.Start(ctx, "Save")
+	defer span.End()
+
+	path, err := s.path(key)
+	if err != nil {
+		return apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf(ArtifactStoreError, "Save"), "bad_request", http.StatusBadRequest, apierrors.CauseList{err.Error()}))
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf(ArtifactStoreError, "Save"), "internal_server_error", http.StatusInternalServerError, apierrors.CauseList{err.Error()}))
+	}
+
+	// Write to a temporary file first so readers never see a partially written artifact
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, content, 0o644); err != nil {
+		return apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf(ArtifactStoreError, "Save"), "internal_server_error", http.StatusInternalServerError, apierrors.CauseList{err.Error()}))
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		return apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf(ArtifactStoreError, "Save"), "internal_server_error", http.StatusInternalServerError, apierrors.CauseList{err.Error()}))
+	}
+
+	return nil
+}
+
+func (s *localArtifactStore) Open(ctx context.Context, key string) (io.ReadCloser, int64, apierrors.ApiError) {
+	_, span := tracerArtifactStore.Start(ctx, "Open")
+	defer span.End()
+
+	path, err := s.path(key)
+	if err != nil {
+		return nil, 0, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf(ArtifactStoreError, "Open"), "bad_request", http.StatusBadRequest, apierrors.CauseList{err.Error()}))
+	}
+
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, 0, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf(ArtifactStoreError, "Open"), "not_found", http.StatusNotFound, apierrors.CauseList{"artifact not found"}))
+	}
+
+	if err != nil {
+		return nil, 0, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf(ArtifactStoreError, "Open"), "internal_server_error", http.StatusInternalServerError, apierrors.CauseList{err.Error()}))
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, 0, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf(ArtifactStoreError, "Open"), "internal_server_error", http.StatusInternalServerError, apierrors.CauseList{err.Error()}))
+	}
+
+	return file, info.Size(), nil
+}
+
+// DeleteOlderThan walks the shop/batch directories, a batch is removed as a whole so a rollback never finds
+// part of its artifacts missing
+func (s *localArtifactStore) DeleteOlderThan(ctx context.Context, cutoff time.Time) (int, apierrors.ApiError) {
+	_, span := tracerArtifactStore.Start(ctx, "DeleteOlderThan")
+	defer span.End()
+
+	shops, err := os.ReadDir(s.basePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf(ArtifactStoreError, "DeleteOlderThan"), "internal_server_error", http.StatusInternalServerError, apierrors.CauseList{err.Error()}))
+	}
+
+	deleted := 0
+	for _, shop := range shops {
+		if !shop.IsDir() {
+			continue
+		}
+
+		shopPath := filepath.Join(s.basePath, shop.Name())
+		batches, err := os.ReadDir(shopPath)
+		if err != nil {
+			continue
+		}
+
+		for _, batch := range batches {
+			if !batch.IsDir() {
+				continue
+			}
+
+			batchPath := filepath.Join(shopPath, batch.Name())
+			if !writtenBefore(batchPath, cutoff) {
+				continue
+			}
+			if err := os.RemoveAll(batchPath); err != nil {
+				return deleted, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf(ArtifactStoreError, "DeleteOlderThan"), "internal_server_error", http.StatusInternalServerError, apierrors.CauseList{err.Error()}))
+			}
+			deleted++
+		}
+	}
+
+	return deleted, nil
+}
+
+// writtenBefore reports whether every file of a batch directory was last written before the cutoff
+func writtenBefore(batchPath string, cutoff time.Time) bool {
+	files, err := os.ReadDir(batchPath)
+	if err != nil {
+		return false
+	}
+
+	for _, file := range files {
+		info, err := file.Info()
+		if err != nil || !info.ModTime().Before(cutoff) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// path resolves a key inside the base path, rejecting keys that would escape it
+func (s *localArtifactStore) path(key string) (string, error) {
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." || strings.ContainsRune(part, filepath.Separator) {
+			return "", fmt.Errorf("invalid artifact key %q", key)
+		}
+	}
+
+	return filepath.Join(s.basePath, filepath.FromSlash(key)), nil
+}
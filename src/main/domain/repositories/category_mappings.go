@@ -0,0 +1,150 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/jopitnow/go-jopit-toolkit/gonosql"
+	"github.com/jopitnow/go-jopit-toolkit/goutils/apierrors"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel"
+	"gopkg.in/mgo.v2/bson"
+)
+
+const (
+	CategoryMappingsDatabaseError = "[%s] Error in DB"
+)
+
+var tracerCategoryMappingsRepo = otel.Tracer("category-mappings-repo")
+
+type CategoryMappingsRepository interface {
+	Get(ctx context.Context, mappingID string) (models.CategoryMapping, apierrors.ApiError)
+	GetByShopIDs(ctx context.Context, shopIDs []string) ([]models.CategoryMapping, apierrors.ApiError)
+	Create(ctx context.Context, mapping models.CategoryMapping) (string, apierrors.ApiError)
+	Update(ctx context.Context, mapping models.CategoryMapping) apierrors.ApiError
+	Delete(ctx context.Context, mappingID string) apierrors.ApiError
+}
+
+type categoryMappingsRepository struct {
+	Collection *mongo.Collection
+}
+
+func NewCategoryMappingsRepository(collection *mongo.Collection) CategoryMappingsRepository {
+	return &categoryMappingsRepository{
+		Collection: collection,
+	}
+}
+
+func (r *categoryMappingsRepository) Get(ctx context.Context, mappingID string) (models.CategoryMapping, apierrors.ApiError) {
+	ctx, span := tracerCategoryMappingsRepo.Start(ctx, "Get")
+	defer span.End()
+
+	primitiveID, err := primitive.ObjectIDFromHex(mappingID)
+	if err != nil {
+		return models.CategoryMapping{}, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf(CategoryMappingsDatabaseError, "Get"), "bad_request", http.StatusBadRequest, apierrors.CauseList{err.Error()}))
+	}
+
+	var mapping models.CategoryMapping
+	result := r.Collection.FindOne(ctx, bson.M{"_id": primitiveID})
+	if errors.Is(result.Err(), mongo.ErrNoDocuments) {
+		return models.CategoryMapping{}, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf(CategoryMappingsDatabaseError, "Get"), "not_found", http.StatusNotFound, apierrors.CauseList{"no documents found"}))
+	}
+
+	if result.Err() != nil {
+		return models.CategoryMapping{}, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf(CategoryMappingsDatabaseError, "Get"), "internal_server_error", http.StatusInternalServerError, apierrors.CauseList{result.Err()}))
+	}
+
+	if err := result.Decode(&mapping); err != nil {
+		return models.CategoryMapping{}, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf(CategoryMappingsDatabaseError, "Get"), "internal_server_error", http.StatusInternalServerError, apierrors.CauseList{err}))
+	}
+
+	return mapping, nil
+}
+
+// GetByShopIDs returns the mappings of the given shops, the empty shop id selects the global mappings
+func (r *categoryMappingsRepository) GetByShopIDs(ctx context.Context, shopIDs []string) ([]models.CategoryMapping, apierrors.ApiError) {
+	ctx, span := tracerCategoryMappingsRepo.Start(ctx, "GetByShopIDs")
+	defer span.End()
+
+	mappings := []models.CategoryMapping{}
+
+	opts := options.Find().SetSort(bson.M{"created_at": 1})
+
+	cursor, err := r.Collection.Find(ctx, bson.M{"shop_id": bson.M{"$in": shopIDs}}, opts)
+	if err != nil {
+		return nil, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf(CategoryMappingsDatabaseError, "GetByShopIDs"), "internal_server_error", http.StatusInternalServerError, apierrors.CauseList{err}))
+	}
+
+	if err = cursor.All(ctx, &mappings); err != nil {
+		return nil, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf(CategoryMappingsDatabaseError, "GetByShopIDs"), "internal_server_error", http.StatusInternalServerError, apierrors.CauseList{err}))
+	}
+
+	return mappings, nil
+}
+
+func (r *categoryMappingsRepository) Create(ctx context.Context, mapping models.CategoryMapping) (string, apierrors.ApiError) {
+	ctx, span := tracerCategoryMappingsRepo.Start(ctx, "Create")
+	defer span.End()
+
+	result, err := gonosql.InsertOne(ctx, r.Collection, mapping)
+	if err != nil {
+		return "", apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf(CategoryMappingsDatabaseError, "Create"), "internal_server_error", http.StatusInternalServerError, apierrors.CauseList{err}))
+	}
+
+	insertedID, ok := result.InsertedID.(primitive.ObjectID)
+	if !ok {
+		return "", apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf(CategoryMappingsDatabaseError, "Create"), "internal_server_error", http.StatusInternalServerError, apierrors.CauseList{"unexpected inserted id"}))
+	}
+
+	return insertedID.Hex(), nil
+}
+
+func (r *categoryMappingsRepository) Update(ctx context.Context, mapping models.CategoryMapping) apierrors.ApiError {
+	ctx, span := tracerCategoryMappingsRepo.Start(ctx, "Update")
+	defer span.End()
+
+	primitiveID, err := primitive.ObjectIDFromHex(mapping.ID)
+	if err != nil {
+		return apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf(CategoryMappingsDatabaseError, "Update"), "bad_request", http.StatusBadRequest, apierrors.CauseList{err.Error()}))
+	}
+
+	mapping.ID = ""
+
+	// Replace rather than $set, so a removed subcategory is cleared
+	result, err := r.Collection.ReplaceOne(ctx, bson.M{"_id": primitiveID}, mapping)
+	if err != nil {
+		return apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf(CategoryMappingsDatabaseError, "Update"), "internal_server_error", http.StatusInternalServerError, apierrors.CauseList{err.Error()}))
+	}
+
+	if result.MatchedCount == 0 {
+		return apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf(CategoryMappingsDatabaseError, "Update"), "not_found", http.StatusNotFound, apierrors.CauseList{}))
+	}
+
+	return nil
+}
+
+func (r *categoryMappingsRepository) Delete(ctx context.Context, mappingID string) apierrors.ApiError {
+	ctx, span := tracerCategoryMappingsRepo.Start(ctx, "Delete")
+	defer span.End()
+
+	primitiveID, err := primitive.ObjectIDFromHex(mappingID)
+	if err != nil {
+		return apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf(CategoryMappingsDatabaseError, "Delete"), "bad_request", http.StatusBadRequest, apierrors.CauseList{err.Error()}))
+	}
+
+	result, err := r.Collection.DeleteOne(ctx, bson.M{"_id": primitiveID})
+	if err != nil {
+		return apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf(CategoryMappingsDatabaseError, "Delete"), "internal_server_error", http.StatusInternalServerError, apierrors.CauseList{err.Error()}))
+	}
+
+	if result.DeletedCount == 0 {
+		return apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf(CategoryMappingsDatabaseError, "Delete"), "not_found", http.StatusNotFound, apierrors.CauseList{}))
+	}
+
+	return nil
+}
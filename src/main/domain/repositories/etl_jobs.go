@@ -0,0 +1,212 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/jopitnow/go-jopit-toolkit/gonosql"
+	"github.com/jopitnow/go-jopit-toolkit/goutils/apierrors"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel"
+	"gopkg.in/mgo.v2/bson"
+)
+
+const (
+	EtlJobsDatabaseError = "[%s] Error in DB"
+	etlJobsListLimit     = 50
+)
+
+var tracerEtlJobsRepo = otel.Tracer("etl-jobs-repo")
+
+type EtlJobsRepository interface {
+	Get(ctx context.Context, jobID string) (models.EtlJob, apierrors.ApiError)
+	GetByUserID(ctx context.Context, userID string) ([]models.EtlJob, apierrors.ApiError)
+	Create(ctx context.Context, job models.EtlJob) (string, apierrors.ApiError)
+	Update(ctx context.Context, job models.EtlJob) apierrors.ApiError
+	RequestCancel(ctx context.Context, jobID string) apierrors.ApiError
+	Heartbeat(ctx context.Context, jobIDs []string) apierrors.ApiError
+	FailStale(ctx context.Context, before time.Time, reason string) (int64, apierrors.ApiError)
+}
+
+type etlJobsRepository struct {
+	Collection *mongo.Collection
+}
+
+func NewEtlJobsRepository(collection *mongo.Collection) EtlJobsRepository {
+	return &etlJobsRepository{
+		Collection: collection,
+	}
+}
+
+func (r *etlJobsRepository) Get(ctx context.Context, jobID string) (models.EtlJob, apierrors.ApiError) {
+	ctx, span := tracerEtlJobsRepo.Start(ctx, "Get")
+	defer span.End()
+
+	primitiveID, err := primitive.ObjectIDFromHex(jobID)
+	if err != nil {
+		return models.EtlJob{}, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf(EtlJobsDatabaseError, "Get"), "bad_request", http.StatusBadRequest, apierrors.CauseList{err.Error()}))
+	}
+
+	var job models.EtlJob
+	result := r.Collection.FindOne(ctx, bson.M{"_id": primitiveID})
+	if errors.Is(result.Err(), mongo.ErrNoDocuments) {
+		return models.EtlJob{}, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf(EtlJobsDatabaseError, "Get"), "not_found", http.StatusNotFound, apierrors.CauseList{"no documents found"}))
+	}
+
+	if result.Err() != nil {
+		return models.EtlJob{}, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf(EtlJobsDatabaseError, "Get"), "internal_server_error", http.StatusInternalServerError, apierrors.CauseList{result.Err()}))
+	}
+
+	if err := result.Decode(&job); err != nil {
+		return models.EtlJob{}, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf(EtlJobsDatabaseError, "Get"), "internal_server_error", http.StatusInternalServerError, apierrors.CauseList{err}))
+	}
+
+	return job, nil
+}
+
+func (r *etlJobsRepository) GetByUserID(ctx context.Context, userID string) ([]models.EtlJob, apierrors.ApiError) {
+	ctx, span := tracerEtlJobsRepo.Start(ctx, "GetByUserID")
+	defer span.End()
+
+	jobs := []models.EtlJob{}
+
+	opts := options.Find().SetSort(bson.M{"created_at": -1}).SetLimit(etlJobsListLimit)
+
+	cursor, err := r.Collection.Find(ctx, bson.M{"user_id": userID}, opts)
+	if err != nil {
+		return nil, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf(EtlJobsDatabaseError, "GetByUserID"), "internal_server_error", http.StatusInternalServerError, apierrors.CauseList{err}))
+	}
+
+	if err = cursor.All(ctx, &jobs); err != nil {
+		return nil, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf(EtlJobsDatabaseError, "GetByUserID"), "internal_server_error", http.StatusInternalServerError, apierrors.CauseList{err}))
+	}
+
+	return jobs, nil
+}
+
+func (r *etlJobsRepository) Create(ctx context.Context, job models.EtlJob) (string, apierrors.ApiError) {
+	ctx, span := tracerEtlJobsRepo.Start(ctx, "Create")
+	defer span.End()
+
+	result, err := gonosql.InsertOne(ctx, r.Collection, job)
+	if err != nil {
+		return "", apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf(EtlJobsDatabaseError, "Create"), "internal_server_error", http.StatusInternalServerError, apierrors.CauseList{err}))
+	}
+
+	insertedID, ok := result.InsertedID.(primitive.ObjectID)
+	if !ok {
+		return "", apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf(EtlJobsDatabaseError, "Create"), "internal_server_error", http.StatusInternalServerError, apierrors.CauseList{"unexpected inserted id"}))
+	}
+
+	return insertedID.Hex(), nil
+}
+
+// Update writes a queued or running job. Finished jobs are never written again, so a job failed as stale or
+// cancelled stays so; not_found tells the caller the job was taken away from it.
+func (r *etlJobsRepository) Update(ctx context.Context, job models.EtlJob) apierrors.ApiError {
+	ctx, span := tracerEtlJobsRepo.Start(ctx, "Update")
+	defer span.End()
+
+	primitiveID, err := primitive.ObjectIDFromHex(job.ID)
+	if err != nil {
+		return apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf(EtlJobsDatabaseError, "Update"), "internal_server_error", http.StatusInternalServerError, apierrors.CauseList{err.Error()}))
+	}
+
+	job.ID = ""
+
+	filter := bson.M{
+		"_id":    primitiveID,
+		"status": bson.M{"$in": []string{models.EtlJobStatusQueued, models.EtlJobStatusRunning}},
+	}
+
+	result, err := r.Collection.UpdateOne(ctx, filter, bson.M{"$set": job})
+	if err != nil {
+		return apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf(EtlJobsDatabaseError, "Update"), "internal_server_error", http.StatusInternalServerError, apierrors.CauseList{err.Error()}))
+	}
+
+	if result.MatchedCount == 0 {
+		return apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf(EtlJobsDatabaseError, "Update"), "not_found", http.StatusNotFound, apierrors.CauseList{}))
+	}
+
+	return nil
+}
+
+func (r *etlJobsRepository) RequestCancel(ctx context.Context, jobID string) apierrors.ApiError {
+	ctx, span := tracerEtlJobsRepo.Start(ctx, "RequestCancel")
+	defer span.End()
+
+	primitiveID, err := primitive.ObjectIDFromHex(jobID)
+	if err != nil {
+		return apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf(EtlJobsDatabaseError, "RequestCancel"), "bad_request", http.StatusBadRequest, apierrors.CauseList{err.Error()}))
+	}
+
+	// Only the flag is written so the progress saved by the worker is never overwritten
+	result, err := r.Collection.UpdateOne(ctx, bson.M{"_id": primitiveID}, bson.M{"$set": bson.M{"cancel_requested": true}})
+	if err != nil {
+		return apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf(EtlJobsDatabaseError, "RequestCancel"), "internal_server_error", http.StatusInternalServerError, apierrors.CauseList{err.Error()}))
+	}
+
+	if result.MatchedCount == 0 {
+		return apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf(EtlJobsDatabaseError, "RequestCancel"), "not_found", http.StatusNotFound, apierrors.CauseList{}))
+	}
+
+	return nil
+}
+
+// Heartbeat renews the heartbeat of the queued and running jobs held by the calling replica
+func (r *etlJobsRepository) Heartbeat(ctx context.Context, jobIDs []string) apierrors.ApiError {
+	ctx, span := tracerEtlJobsRepo.Start(ctx, "Heartbeat")
+	defer span.End()
+
+	primitiveIDs := make([]primitive.ObjectID, 0, len(jobIDs))
+	for _, jobID := range jobIDs {
+		primitiveID, err := primitive.ObjectIDFromHex(jobID)
+		if err != nil {
+			return apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf(EtlJobsDatabaseError, "Heartbeat"), "internal_server_error", http.StatusInternalServerError, apierrors.CauseList{err.Error()}))
+		}
+		primitiveIDs = append(primitiveIDs, primitiveID)
+	}
+
+	filter := bson.M{
+		"_id":    bson.M{"$in": primitiveIDs},
+		"status": bson.M{"$in": []string{models.EtlJobStatusQueued, models.EtlJobStatusRunning}},
+	}
+
+	if _, err := r.Collection.UpdateMany(ctx, filter, bson.M{"$set": bson.M{"heartbeat_at": time.Now().UTC()}}); err != nil {
+		return apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf(EtlJobsDatabaseError, "Heartbeat"), "internal_server_error", http.StatusInternalServerError, apierrors.CauseList{err.Error()}))
+	}
+
+	return nil
+}
+
+// FailStale marks the queued and running jobs whose heartbeat is older than before as failed, for when the
+// replica holding them died and lost them. It returns how many jobs were failed.
+func (r *etlJobsRepository) FailStale(ctx context.Context, before time.Time, reason string) (int64, apierrors.ApiError) {
+	ctx, span := tracerEtlJobsRepo.Start(ctx, "FailStale")
+	defer span.End()
+
+	now := time.Now().UTC()
+	filter := bson.M{
+		"status":       bson.M{"$in": []string{models.EtlJobStatusQueued, models.EtlJobStatusRunning}},
+		"heartbeat_at": bson.M{"$lt": before},
+	}
+	update := bson.M{"$set": bson.M{
+		"status":      models.EtlJobStatusFailed,
+		"error":       reason,
+		"updated_at":  now,
+		"finished_at": now,
+	}}
+
+	result, err := r.Collection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf(EtlJobsDatabaseError, "FailStale"), "internal_server_error", http.StatusInternalServerError, apierrors.CauseList{err.Error()}))
+	}
+
+	return result.ModifiedCount, nil
+}
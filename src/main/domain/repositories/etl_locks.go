@@ -0,0 +1,77 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/jopitnow/go-jopit-toolkit/goutils/apierrors"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel"
+	"gopkg.in/mgo.v2/bson"
+)
+
+const (
+	EtlLocksDatabaseError = "[%s] Error in DB"
+)
+
+var tracerEtlLocksRepo = otel.Tracer("etl-locks-repo")
+
+// EtlLocksRepository is a lease based lock shared by all the replicas. A lock expires after its ttl
+// so a crashed replica never keeps it forever, holders renew it by acquiring it again.
+type EtlLocksRepository interface {
+	Acquire(ctx context.Context, key string, owner string, ttl time.Duration) (bool, apierrors.ApiError)
+	Release(ctx context.Context, key string, owner string) apierrors.ApiError
+}
+
+type etlLocksRepository struct {
+	Collection *mongo.Collection
+}
+
+func NewEtlLocksRepository(collection *mongo.Collection) EtlLocksRepository {
+	return &etlLocksRepository{
+		Collection: collection,
+	}
+}
+
+func (r *etlLocksRepository) Acquire(ctx context.Context, key string, owner string, ttl time.Duration) (bool, apierrors.ApiError) {
+	ctx, span := tracerEtlLocksRepo.Start(ctx, "Acquire")
+	defer span.End()
+
+	now := time.Now().UTC()
+
+	// Matches a free, expired or already owned lock. A lock held by someone else does not match,
+	// the upsert then collides with the existing _id and the lock is reported as taken.
+	filter := bson.M{
+		"_id": key,
+		"$or": []bson.M{
+			{"expires_at": bson.M{"$lte": now}},
+			{"owner": owner},
+		},
+	}
+	update := bson.M{"$set": bson.M{"owner": owner, "expires_at": now.Add(ttl)}}
+
+	_, err := r.Collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf(EtlLocksDatabaseError, "Acquire"), "internal_server_error", http.StatusInternalServerError, apierrors.CauseList{err.Error()}))
+	}
+
+	return true, nil
+}
+
+func (r *etlLocksRepository) Release(ctx context.Context, key string, owner string) apierrors.ApiError {
+	ctx, span := tracerEtlLocksRepo.Start(ctx, "Release")
+	defer span.End()
+
+	if _, err := r.Collection.DeleteOne(ctx, bson.M{"_id": key, "owner": owner}); err != nil {
+		return apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf(EtlLocksDatabaseError, "Release"), "internal_server_error", http.StatusInternalServerError, apierrors.CauseList{err.Error()}))
+	}
+
+	return nil
+}
@@ -0,0 +1,215 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/jopitnow/go-jopit-toolkit/gonosql"
+	"github.com/jopitnow/go-jopit-toolkit/goutils/apierrors"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel"
+	"gopkg.in/mgo.v2/bson"
+)
+
+const (
+	EtlSchedulesDatabaseError = "[%s] Error in DB"
+)
+
+var tracerEtlSchedulesRepo = otel.Tracer("etl-schedules-repo")
+
+type EtlSchedulesRepository interface {
+	Get(ctx context.Context, scheduleID string) (models.EtlSchedule, apierrors.ApiError)
+	GetByUserID(ctx context.Context, userID string) ([]models.EtlSchedule, apierrors.ApiError)
+	GetDue(ctx context.Context, now time.Time, limit int64) ([]models.EtlSchedule, apierrors.ApiError)
+	Create(ctx context.Context, schedule models.EtlSchedule) (string, apierrors.ApiError)
+	Update(ctx context.Context, schedule models.EtlSchedule) apierrors.ApiError
+	Delete(ctx context.Context, scheduleID string) apierrors.ApiError
+	ClaimRun(ctx context.Context, schedule models.EtlSchedule, nextRunAt time.Time, now time.Time) (bool, apierrors.ApiError)
+	RecordRun(ctx context.Context, scheduleID string, jobID string, runError string) apierrors.ApiError
+}
+
+type etlSchedulesRepository struct {
+	Collection *mongo.Collection
+}
+
+func NewEtlSchedulesRepository(collection *mongo.Collection) EtlSchedulesRepository {
+	return &etlSchedulesRepository{
+		Collection: collection,
+	}
+}
+
+func (r *etlSchedulesRepository) Get(ctx context.Context, scheduleID string) (models.EtlSchedule, apierrors.ApiError) {
+	ctx, span := tracerEtlSchedulesRepo.Start(ctx, "Get")
+	defer span.End()
+
+	primitiveID, err := primitive.ObjectIDFromHex(scheduleID)
+	if err != nil {
+		return models.EtlSchedule{}, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf(EtlSchedulesDatabaseError, "Get"), "bad_request", http.StatusBadRequest, apierrors.CauseList{err.Error()}))
+	}
+
+	var schedule models.EtlSchedule
+	result := r.Collection.FindOne(ctx, bson.M{"_id": primitiveID})
+	if errors.Is(result.Err(), mongo.ErrNoDocuments) {
+		return models.EtlSchedule{}, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf(EtlSchedulesDatabaseError, "Get"), "not_found", http.StatusNotFound, apierrors.CauseList{"no documents found"}))
+	}
+
+	if result.Err() != nil {
+		return models.EtlSchedule{}, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf(EtlSchedulesDatabaseError, "Get"), "internal_server_error", http.StatusInternalServerError, apierrors.CauseList{result.Err()}))
+	}
+
+	if err := result.Decode(&schedule); err != nil {
+		return models.EtlSchedule{}, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf(EtlSchedulesDatabaseError, "Get"), "internal_server_error", http.StatusInternalServerError, apierrors.CauseList{err}))
+	}
+
+	return schedule, nil
+}
+
+func (r *etlSchedulesRepository) GetByUserID(ctx context.Context, userID string) ([]models.EtlSchedule, apierrors.ApiError) {
+	ctx, span := tracerEtlSchedulesRepo.Start(ctx, "GetByUserID")
+	defer span.End()
+
+	schedules := []models.EtlSchedule{}
+
+	opts := options.Find().SetSort(bson.M{"created_at": 1})
+
+	cursor, err := r.Collection.Find(ctx, bson.M{"user_id": userID}, opts)
+	if err != nil {
+		return nil, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf(EtlSchedulesDatabaseError, "GetByUserID"), "internal_server_error", http.StatusInternalServerError, apierrors.CauseList{err}))
+	}
+
+	if err = cursor.All(ctx, &schedules); err != nil {
+		return nil, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf(EtlSchedulesDatabaseError, "GetByUserID"), "internal_server_error", http.StatusInternalServerError, apierrors.CauseList{err}))
+	}
+
+	return schedules, nil
+}
+
+// GetDue returns the enabled schedules whose next run is not in the future
+func (r *etlSchedulesRepository) GetDue(ctx context.Context, now time.Time, limit int64) ([]models.EtlSchedule, apierrors.ApiError) {
+	ctx, span := tracerEtlSchedulesRepo.Start(ctx, "GetDue")
+	defer span.End()
+
+	schedules := []models.EtlSchedule{}
+
+	filter := bson.M{"enabled": true, "next_run_at": bson.M{"$lte": now}}
+	opts := options.Find().SetSort(bson.M{"next_run_at": 1}).SetLimit(limit)
+
+	cursor, err := r.Collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf(EtlSchedulesDatabaseError, "GetDue"), "internal_server_error", http.StatusInternalServerError, apierrors.CauseList{err}))
+	}
+
+	if err = cursor.All(ctx, &schedules); err != nil {
+		return nil, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf(EtlSchedulesDatabaseError, "GetDue"), "internal_server_error", http.StatusInternalServerError, apierrors.CauseList{err}))
+	}
+
+	return schedules, nil
+}
+
+func (r *etlSchedulesRepository) Create(ctx context.Context, schedule models.EtlSchedule) (string, apierrors.ApiError) {
+	ctx, span := tracerEtlSchedulesRepo.Start(ctx, "Create")
+	defer span.End()
+
+	result, err := gonosql.InsertOne(ctx, r.Collection, schedule)
+	if err != nil {
+		return "", apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf(EtlSchedulesDatabaseError, "Create"), "internal_server_error", http.StatusInternalServerError, apierrors.CauseList{err}))
+	}
+
+	insertedID, ok := result.InsertedID.(primitive.ObjectID)
+	if !ok {
+		return "", apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf(EtlSchedulesDatabaseError, "Create"), "internal_server_error", http.StatusInternalServerError, apierrors.CauseList{"unexpected inserted id"}))
+	}
+
+	return insertedID.Hex(), nil
+}
+
+func (r *etlSchedulesRepository) Update(ctx context.Context, schedule models.EtlSchedule) apierrors.ApiError {
+	ctx, span := tracerEtlSchedulesRepo.Start(ctx, "Update")
+	defer span.End()
+
+	primitiveID, err := primitive.ObjectIDFromHex(schedule.ID)
+	if err != nil {
+		return apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf(EtlSchedulesDatabaseError, "Update"), "bad_request", http.StatusBadRequest, apierrors.CauseList{err.Error()}))
+	}
+
+	schedule.ID = ""
+
+	// Replace rather than $set, so switching between cron and interval clears the previous one
+	result, err := r.Collection.ReplaceOne(ctx, bson.M{"_id": primitiveID}, schedule)
+	if err != nil {
+		return apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf(EtlSchedulesDatabaseError, "Update"), "internal_server_error", http.StatusInternalServerError, apierrors.CauseList{err.Error()}))
+	}
+
+	if result.MatchedCount == 0 {
+		return apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf(EtlSchedulesDatabaseError, "Update"), "not_found", http.StatusNotFound, apierrors.CauseList{}))
+	}
+
+	return nil
+}
+
+func (r *etlSchedulesRepository) Delete(ctx context.Context, scheduleID string) apierrors.ApiError {
+	ctx, span := tracerEtlSchedulesRepo.Start(ctx, "Delete")
+	defer span.End()
+
+	primitiveID, err := primitive.ObjectIDFromHex(scheduleID)
+	if err != nil {
+		return apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf(EtlSchedulesDatabaseError, "Delete"), "bad_request", http.StatusBadRequest, apierrors.CauseList{err.Error()}))
+	}
+
+	result, err := r.Collection.DeleteOne(ctx, bson.M{"_id": primitiveID})
+	if err != nil {
+		return apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf(EtlSchedulesDatabaseError, "Delete"), "internal_server_error", http.StatusInternalServerError, apierrors.CauseList{err.Error()}))
+	}
+
+	if result.DeletedCount == 0 {
+		return apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf(EtlSchedulesDatabaseError, "Delete"), "not_found", http.StatusNotFound, apierrors.CauseList{}))
+	}
+
+	return nil
+}
+
+// ClaimRun moves the schedule to its next run only if no other replica did it first, the caller that
+// gets true owns the current run
+func (r *etlSchedulesRepository) ClaimRun(ctx context.Context, schedule models.EtlSchedule, nextRunAt time.Time, now time.Time) (bool, apierrors.ApiError) {
+	ctx, span := tracerEtlSchedulesRepo.Start(ctx, "ClaimRun")
+	defer span.End()
+
+	primitiveID, err := primitive.ObjectIDFromHex(schedule.ID)
+	if err != nil {
+		return false, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf(EtlSchedulesDatabaseError, "ClaimRun"), "bad_request", http.StatusBadRequest, apierrors.CauseList{err.Error()}))
+	}
+
+	filter := bson.M{"_id": primitiveID, "enabled": true, "next_run_at": schedule.NextRunAt}
+	update := bson.M{"$set": bson.M{"next_run_at": nextRunAt, "last_run_at": now, "updated_at": now}}
+
+	result, err := r.Collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf(EtlSchedulesDatabaseError, "ClaimRun"), "internal_server_error", http.StatusInternalServerError, apierrors.CauseList{err.Error()}))
+	}
+
+	return result.ModifiedCount == 1, nil
+}
+
+// RecordRun stores the outcome of triggering a run, only the run fields are written
+func (r *etlSchedulesRepository) RecordRun(ctx context.Context, scheduleID string, jobID string, runError string) apierrors.ApiError {
+	ctx, span := tracerEtlSchedulesRepo.Start(ctx, "RecordRun")
+	defer span.End()
+
+	primitiveID, err := primitive.ObjectIDFromHex(scheduleID)
+	if err != nil {
+		return apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf(EtlSchedulesDatabaseError, "RecordRun"), "bad_request", http.StatusBadRequest, apierrors.CauseList{err.Error()}))
+	}
+
+	update := bson.M{"$set": bson.M{"last_job_id": jobID, "last_error": runError}}
+	if _, err := r.Collection.UpdateOne(ctx, bson.M{"_id": primitiveID}, update); err != nil {
+		return apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf(EtlSchedulesDatabaseError, "RecordRun"), "internal_server_error", http.StatusInternalServerError, apierrors.CauseList{err.Error()}))
+	}
+
+	return nil
+}
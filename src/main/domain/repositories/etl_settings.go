@@ -0,0 +1,72 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/jopitnow/go-jopit-toolkit/goutils/apierrors"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel"
+	"gopkg.in/mgo.v2/bson"
+)
+
+const (
+	EtlSettingsDatabaseError = "[%s] Error in DB"
+)
+
+var tracerEtlSettingsRepo = otel.Tracer("etl-settings-repo")
+
+type EtlSettingsRepository interface {
+	GetByShopID(ctx context.Context, shopID string) (models.EtlSettings, apierrors.ApiError)
+	Save(ctx context.Context, settings models.EtlSettings) apierrors.ApiError
+}
+
+type etlSettingsRepository struct {
+	Collection *mongo.Collection
+}
+
+func NewEtlSettingsRepository(collection *mongo.Collection) EtlSettingsRepository {
+	return &etlSettingsRepository{
+		Collection: collection,
+	}
+}
+
+func (r *etlSettingsRepository) GetByShopID(ctx context.Context, shopID string) (models.EtlSettings, apierrors.ApiError) {
+	ctx, span := tracerEtlSettingsRepo.Start(ctx, "GetByShopID")
+	defer span.End()
+
+	var settings models.EtlSettings
+	result := r.Collection.FindOne(ctx, bson.M{"shop_id": shopID})
+	if errors.Is(result.Err(), mongo.ErrNoDocuments) {
+		return models.EtlSettings{}, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf(EtlSettingsDatabaseError, "GetByShopID"), "not_found", http.StatusNotFound, apierrors.CauseList{"no documents found"}))
+	}
+
+	if result.Err() != nil {
+		return models.EtlSettings{}, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf(EtlSettingsDatabaseError, "GetByShopID"), "internal_server_error", http.StatusInternalServerError, apierrors.CauseList{result.Err()}))
+	}
+
+	if err := result.Decode(&settings); err != nil {
+		return models.EtlSettings{}, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf(EtlSettingsDatabaseError, "GetByShopID"), "internal_server_error", http.StatusInternalServerError, apierrors.CauseList{err}))
+	}
+
+	return settings, nil
+}
+
+func (r *etlSettingsRepository) Save(ctx context.Context, settings models.EtlSettings) apierrors.ApiError {
+	ctx, span := tracerEtlSettingsRepo.Start(ctx, "Save")
+	defer span.End()
+
+	settings.ID = ""
+
+	// Replace rather than $set, so settings removed by the user are cleared
+	_, err := r.Collection.ReplaceOne(ctx, bson.M{"shop_id": settings.ShopID}, settings, options.Replace().SetUpsert(true))
+	if err != nil {
+		return apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf(EtlSettingsDatabaseError, "Save"), "internal_server_error", http.StatusInternalServerError, apierrors.CauseList{err.Error()}))
+	}
+
+	return nil
+}
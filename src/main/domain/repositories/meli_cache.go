@@ -0,0 +1,35 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jopitnow/go-jopit-toolkit/goutils/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// meliCacheIndexTimeout bounds the index creation done when a cache repository is built
+const meliCacheIndexTimeout = 10 * time.Second
+
+// ensureFetchedAtTTLIndex has Mongo delete the cached MercadoLibre entries expireAfter after they were fetched,
+// so entries no run asks for anymore don't pile up. Creating an index that already exists is a no-op, a failure
+// only leaves the entries in place.
+func ensureFetchedAtTTLIndex(collection *mongo.Collection, expireAfter time.Duration) {
+	if expireAfter <= 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), meliCacheIndexTimeout)
+	defer cancel()
+
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "fetched_at", Value: 1}},
+		Options: options.Index().SetName("fetched_at_ttl").SetExpireAfterSeconds(int32(expireAfter.Seconds())),
+	})
+	if err != nil {
+		logger.Errorf(fmt.Sprintf("error creating the fetched_at ttl index of %s", collection.Name()), err)
+	}
+}
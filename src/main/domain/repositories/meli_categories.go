@@ -0,0 +1,73 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/jopitnow/go-jopit-toolkit/goutils/apierrors"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel"
+	"gopkg.in/mgo.v2/bson"
+)
+
+const (
+	MeliCategoriesDatabaseError = "[%s] Error in DB"
+)
+
+var tracerMeliCategoriesRepo = otel.Tracer("meli-categories-repo")
+
+type MeliCategoriesRepository interface {
+	Get(ctx context.Context, categoryID string) (models.MeliCategory, apierrors.ApiError)
+	Save(ctx context.Context, category models.MeliCategory) apierrors.ApiError
+}
+
+type meliCategoriesRepository struct {
+	Collection *mongo.Collection
+}
+
+// NewMeliCategoriesRepository builds the categories cache, Mongo deletes the categories expireAfter after they were fetched
+func NewMeliCategoriesRepository(collection *mongo.Collection, expireAfter time.Duration) MeliCategoriesRepository {
+	ensureFetchedAtTTLIndex(collection, expireAfter)
+
+	return &meliCategoriesRepository{
+		Collection: collection,
+	}
+}
+
+func (r *meliCategoriesRepository) Get(ctx context.Context, categoryID string) (models.MeliCategory, apierrors.ApiError) {
+	ctx, span := tracerMeliCategoriesRepo.Start(ctx, "Get")
+	defer span.End()
+
+	var category models.MeliCategory
+	result := r.Collection.FindOne(ctx, bson.M{"_id": categoryID})
+	if errors.Is(result.Err(), mongo.ErrNoDocuments) {
+		return models.MeliCategory{}, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf(MeliCategoriesDatabaseError, "Get"), "not_found", http.StatusNotFound, apierrors.CauseList{"no documents found"}))
+	}
+
+	if result.Err() != nil {
+		return models.MeliCategory{}, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf(MeliCategoriesDatabaseError, "Get"), "internal_server_error", http.StatusInternalServerError, apierrors.CauseList{result.Err()}))
+	}
+
+	if err := result.Decode(&category); err != nil {
+		return models.MeliCategory{}, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf(MeliCategoriesDatabaseError, "Get"), "internal_server_error", http.StatusInternalServerError, apierrors.CauseList{err}))
+	}
+
+	return category, nil
+}
+
+func (r *meliCategoriesRepository) Save(ctx context.Context, category models.MeliCategory) apierrors.ApiError {
+	ctx, span := tracerMeliCategoriesRepo.Start(ctx, "Save")
+	defer span.End()
+
+	_, err := r.Collection.ReplaceOne(ctx, bson.M{"_id": category.ID}, category, options.Replace().SetUpsert(true))
+	if err != nil {
+		return apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf(MeliCategoriesDatabaseError, "Save"), "internal_server_error", http.StatusInternalServerError, apierrors.CauseList{err.Error()}))
+	}
+
+	return nil
+}
@@ -0,0 +1,73 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/jopitnow/go-jopit-toolkit/goutils/apierrors"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models/dto"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel"
+	"gopkg.in/mgo.v2/bson"
+)
+
+const (
+	MeliSizeChartsDatabaseError = "[%s] Error in DB"
+)
+
+var tracerMeliSizeChartsRepo = otel.Tracer("meli-size-charts-repo")
+
+type MeliSizeChartsRepository interface {
+	Get(ctx context.Context, chartID string) (dto.MeliCachedSizeChart, apierrors.ApiError)
+	Save(ctx context.Context, chart dto.MeliCachedSizeChart) apierrors.ApiError
+}
+
+type meliSizeChartsRepository struct {
+	Collection *mongo.Collection
+}
+
+// NewMeliSizeChartsRepository builds the size charts cache, Mongo deletes the charts expireAfter after they were fetched
+func NewMeliSizeChartsRepository(collection *mongo.Collection, expireAfter time.Duration) MeliSizeChartsRepository {
+	ensureFetchedAtTTLIndex(collection, expireAfter)
+
+	return &meliSizeChartsRepository{
+		Collection: collection,
+	}
+}
+
+func (r *meliSizeChartsRepository) Get(ctx context.Context, chartID string) (dto.MeliCachedSizeChart, apierrors.ApiError) {
+	ctx, span := tracerMeliSizeChartsRepo.Start(ctx, "Get")
+	defer span.End()
+
+	var chart dto.MeliCachedSizeChart
+	result := r.Collection.FindOne(ctx, bson.M{"_id": chartID})
+	if errors.Is(result.Err(), mongo.ErrNoDocuments) {
+		return dto.MeliCachedSizeChart{}, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf(MeliSizeChartsDatabaseError, "Get"), "not_found", http.StatusNotFound, apierrors.CauseList{"no documents found"}))
+	}
+
+	if result.Err() != nil {
+		return dto.MeliCachedSizeChart{}, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf(MeliSizeChartsDatabaseError, "Get"), "internal_server_error", http.StatusInternalServerError, apierrors.CauseList{result.Err()}))
+	}
+
+	if err := result.Decode(&chart); err != nil {
+		return dto.MeliCachedSizeChart{}, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf(MeliSizeChartsDatabaseError, "Get"), "internal_server_error", http.StatusInternalServerError, apierrors.CauseList{err}))
+	}
+
+	return chart, nil
+}
+
+func (r *meliSizeChartsRepository) Save(ctx context.Context, chart dto.MeliCachedSizeChart) apierrors.ApiError {
+	ctx, span := tracerMeliSizeChartsRepo.Start(ctx, "Save")
+	defer span.End()
+
+	_, err := r.Collection.ReplaceOne(ctx, bson.M{"_id": chart.ID}, chart, options.Replace().SetUpsert(true))
+	if err != nil {
+		return apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf(MeliSizeChartsDatabaseError, "Save"), "internal_server_error", http.StatusInternalServerError, apierrors.CauseList{err.Error()}))
+	}
+
+	return nil
+}
@@ -27,6 +27,7 @@ var tracerMeliRepo = otel.Tracer("meli-credentials-repo")
 type MercadoLibreCredentialsRepository interface {
 	GetCredentialsByShopID(ctx context.Context, shopID string) (models.MercadoLibreCredential, apierrors.ApiError)
 	GetCredentialsByUserID(ctx context.Context, userID string) (models.MercadoLibreCredential, apierrors.ApiError)
+	GetCredentialsByMeliUserID(ctx context.Context, meliUserID int64) (models.MercadoLibreCredential, apierrors.ApiError)
 	CreateCredentials(ctx context.Context, credentials models.MercadoLibreCredential) apierrors.ApiError
 	UpdateCredentials(ctx context.Context, credentials models.MercadoLibreCredential) apierrors.ApiError
 	DeleteCredentials(ctx context.Context, userID string) apierrors.ApiError
@@ -60,6 +61,15 @@ func (r *mercadoLibreCredentialsRepository) GetCredentialsByUserID(ctx context.C
 	return r.getCredentials(ctx, filter, span)
 }
 
+func (r *mercadoLibreCredentialsRepository) GetCredentialsByMeliUserID(ctx context.Context, meliUserID int64) (models.MercadoLibreCredential, apierrors.ApiError) {
+	ctx, span := tracerMeliRepo.Start(ctx, "GetCredentialsByMeliUserID")
+	defer span.End()
+
+	filter := bson.M{"user_id_meli": meliUserID}
+
+	return r.getCredentials(ctx, filter, span)
+}
+
 func (r *mercadoLibreCredentialsRepository) CreateCredentials(ctx context.Context, credentials models.MercadoLibreCredential) apierrors.ApiError {
 	ctx, span := tracerMeliRepo.Start(ctx, "CreateCredentials")
 	defer span.End()
@@ -0,0 +1,72 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/jopitnow/go-jopit-toolkit/goutils/apierrors"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel"
+	"gopkg.in/mgo.v2/bson"
+)
+
+const (
+	SyncCursorsDatabaseError = "[%s] Error in DB"
+)
+
+var tracerSyncCursorsRepo = otel.Tracer("sync-cursors-repo")
+
+type SyncCursorsRepository interface {
+	GetByShopID(ctx context.Context, shopID string, sourceType string) (models.SyncCursor, apierrors.ApiError)
+	Save(ctx context.Context, cursor models.SyncCursor) apierrors.ApiError
+}
+
+type syncCursorsRepository struct {
+	Collection *mongo.Collection
+}
+
+func NewSyncCursorsRepository(collection *mongo.Collection) SyncCursorsRepository {
+	return &syncCursorsRepository{
+		Collection: collection,
+	}
+}
+
+func (r *syncCursorsRepository) GetByShopID(ctx context.Context, shopID string, sourceType string) (models.SyncCursor, apierrors.ApiError) {
+	ctx, span := tracerSyncCursorsRepo.Start(ctx, "GetByShopID")
+	defer span.End()
+
+	var cursor models.SyncCursor
+	result := r.Collection.FindOne(ctx, bson.M{"shop_id": shopID, "source_type": sourceType})
+	if errors.Is(result.Err(), mongo.ErrNoDocuments) {
+		return models.SyncCursor{}, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf(SyncCursorsDatabaseError, "GetByShopID"), "not_found", http.StatusNotFound, apierrors.CauseList{"no documents found"}))
+	}
+
+	if result.Err() != nil {
+		return models.SyncCursor{}, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf(SyncCursorsDatabaseError, "GetByShopID"), "internal_server_error", http.StatusInternalServerError, apierrors.CauseList{result.Err()}))
+	}
+
+	if err := result.Decode(&cursor); err != nil {
+		return models.SyncCursor{}, apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf(SyncCursorsDatabaseError, "GetByShopID"), "internal_server_error", http.StatusInternalServerError, apierrors.CauseList{err}))
+	}
+
+	return cursor, nil
+}
+
+func (r *syncCursorsRepository) Save(ctx context.Context, cursor models.SyncCursor) apierrors.ApiError {
+	ctx, span := tracerSyncCursorsRepo.Start(ctx, "Save")
+	defer span.End()
+
+	cursor.ID = ""
+
+	filter := bson.M{"shop_id": cursor.ShopID, "source_type": cursor.SourceType}
+	_, err := r.Collection.UpdateOne(ctx, filter, bson.M{"$set": cursor}, options.Update().SetUpsert(true))
+	if err != nil {
+		return apierrors.NewWrapAndTraceError(span, apierrors.NewApiError(fmt.Sprintf(SyncCursorsDatabaseError, "Save"), "internal_server_error", http.StatusInternalServerError, apierrors.CauseList{err.Error()}))
+	}
+
+	return nil
+}
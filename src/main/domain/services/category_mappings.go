@@ -0,0 +1,296 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/jopitnow/go-jopit-toolkit/goauth"
+	"github.com/jopitnow/go-jopit-toolkit/goutils/apierrors"
+	"github.com/jopitnow/go-jopit-toolkit/goutils/logger"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/clients"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models/dto"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/repositories"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/utils"
+)
+
+// CategoryMappingsService manages the MercadoLibre to Jopit category mappings. The plain methods work on the
+// mappings of the user's shop, the Global ones on the mappings shared by every shop.
+type CategoryMappingsService interface {
+	Create(ctx context.Context, input dto.CategoryMappingRequest) (models.CategoryMapping, apierrors.ApiError)
+	GetByUserID(ctx context.Context) ([]models.CategoryMapping, apierrors.ApiError)
+	Update(ctx context.Context, mappingID string, input dto.CategoryMappingRequest) (models.CategoryMapping, apierrors.ApiError)
+	Delete(ctx context.Context, mappingID string) apierrors.ApiError
+	CreateGlobal(ctx context.Context, input dto.CategoryMappingRequest) (models.CategoryMapping, apierrors.ApiError)
+	GetGlobal(ctx context.Context) ([]models.CategoryMapping, apierrors.ApiError)
+	UpdateGlobal(ctx context.Context, mappingID string, input dto.CategoryMappingRequest) (models.CategoryMapping, apierrors.ApiError)
+	DeleteGlobal(ctx context.Context, mappingID string) apierrors.ApiError
+	GetByShopID(ctx context.Context, shopID string) ([]models.CategoryMapping, apierrors.ApiError)
+	Suggest(ctx context.Context) ([]models.CategoryMappingSuggestion, apierrors.ApiError)
+}
+
+type categoryMappingsService struct {
+	repository     repositories.CategoryMappingsRepository
+	shopsClient    clients.ShopClient
+	itemsClient    clients.ItemsClient
+	meliCategories MeliCategoriesService
+}
+
+func NewCategoryMappingsService(repository repositories.CategoryMappingsRepository, shopsClient clients.ShopClient, itemsClient clients.ItemsClient, meliCategories MeliCategoriesService) CategoryMappingsService {
+	return &categoryMappingsService{
+		repository:     repository,
+		shopsClient:    shopsClient,
+		itemsClient:    itemsClient,
+		meliCategories: meliCategories,
+	}
+}
+
+func (s *categoryMappingsService) Create(ctx context.Context, input dto.CategoryMappingRequest) (models.CategoryMapping, apierrors.ApiError) {
+	shop, err := s.shopsClient.GetShopByUserID(ctx)
+	if err != nil {
+		return models.CategoryMapping{}, err
+	}
+
+	return s.create(ctx, shop.ID, fmt.Sprint(ctx.Value(goauth.FirebaseUserID)), input)
+}
+
+// GetByUserID returns the mappings of the user's shop followed by the global ones
+func (s *categoryMappingsService) GetByUserID(ctx context.Context) ([]models.CategoryMapping, apierrors.ApiError) {
+	shop, err := s.shopsClient.GetShopByUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetByShopID(ctx, shop.ID)
+}
+
+func (s *categoryMappingsService) Update(ctx context.Context, mappingID string, input dto.CategoryMappingRequest) (models.CategoryMapping, apierrors.ApiError) {
+	shop, err := s.shopsClient.GetShopByUserID(ctx)
+	if err != nil {
+		return models.CategoryMapping{}, err
+	}
+
+	return s.update(ctx, shop.ID, mappingID, input)
+}
+
+func (s *categoryMappingsService) Delete(ctx context.Context, mappingID string) apierrors.ApiError {
+	shop, err := s.shopsClient.GetShopByUserID(ctx)
+	if err != nil {
+		return err
+	}
+
+	return s.delete(ctx, shop.ID, mappingID)
+}
+
+func (s *categoryMappingsService) CreateGlobal(ctx context.Context, input dto.CategoryMappingRequest) (models.CategoryMapping, apierrors.ApiError) {
+	return s.create(ctx, "", "", input)
+}
+
+func (s *categoryMappingsService) GetGlobal(ctx context.Context) ([]models.CategoryMapping, apierrors.ApiError) {
+	return s.repository.GetByShopIDs(ctx, []string{""})
+}
+
+func (s *categoryMappingsService) UpdateGlobal(ctx context.Context, mappingID string, input dto.CategoryMappingRequest) (models.CategoryMapping, apierrors.ApiError) {
+	return s.update(ctx, "", mappingID, input)
+}
+
+func (s *categoryMappingsService) DeleteGlobal(ctx context.Context, mappingID string) apierrors.ApiError {
+	return s.delete(ctx, "", mappingID)
+}
+
+// GetByShopID returns the mappings a shop's runs use, its own first and then the global ones
+func (s *categoryMappingsService) GetByShopID(ctx context.Context, shopID string) ([]models.CategoryMapping, apierrors.ApiError) {
+	mappings, err := s.repository.GetByShopIDs(ctx, []string{shopID, ""})
+	if err != nil {
+		return nil, err
+	}
+
+	ordered := make([]models.CategoryMapping, 0, len(mappings))
+	for _, mapping := range mappings {
+		if !mapping.IsGlobal() {
+			ordered = append(ordered, mapping)
+		}
+	}
+	for _, mapping := range mappings {
+		if mapping.IsGlobal() {
+			ordered = append(ordered, mapping)
+		}
+	}
+
+	return ordered, nil
+}
+
+// Suggest proposes mappings for the MercadoLibre categories of the shop's imported items that have none,
+// most used categories first
+func (s *categoryMappingsService) Suggest(ctx context.Context) ([]models.CategoryMappingSuggestion, apierrors.ApiError) {
+	shop, err := s.shopsClient.GetShopByUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := s.itemsClient.GetItemsByShopID(ctx, shop.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	mappings, err := s.GetByShopID(ctx, shop.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	usages := utils.CategoryUsages(items)
+	unmapped := make([]utils.CategoryUsage, 0, len(usages))
+	for _, usage := range usages {
+		if _, ok := utils.ResolveCategoryMapping(mappings, usage.CategoryID, usage.DomainID); !ok {
+			unmapped = append(unmapped, usage)
+		}
+	}
+
+	suggestions := make([]models.CategoryMappingSuggestion, 0, len(unmapped))
+	if len(unmapped) == 0 {
+		return suggestions, nil
+	}
+
+	jopitCategories, err := s.itemsClient.GetCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, usage := range unmapped {
+		// Without its path a category can still be suggested from its domain
+		category, err := s.meliCategories.GetCategory(ctx, usage.CategoryID)
+		if err != nil {
+			logger.Errorf(fmt.Sprintf("error getting MercadoLibre category %s", usage.CategoryID), err)
+			category = models.MeliCategory{ID: usage.CategoryID}
+		}
+
+		suggestions = append(suggestions, utils.SuggestCategoryMapping(usage, category, usages, mappings, jopitCategories))
+	}
+
+	return suggestions, nil
+}
+
+func (s *categoryMappingsService) create(ctx context.Context, shopID string, userID string, input dto.CategoryMappingRequest) (models.CategoryMapping, apierrors.ApiError) {
+	mapping := input.ToModel()
+	mapping.ShopID = shopID
+	mapping.UserID = userID
+
+	if err := s.validate(ctx, mapping); err != nil {
+		return models.CategoryMapping{}, err
+	}
+
+	now := time.Now().UTC()
+	mapping.CreatedAt = now
+	mapping.UpdatedAt = now
+
+	mappingID, err := s.repository.Create(ctx, mapping)
+	if err != nil {
+		return models.CategoryMapping{}, err
+	}
+	mapping.ID = mappingID
+
+	return mapping, nil
+}
+
+func (s *categoryMappingsService) update(ctx context.Context, shopID string, mappingID string, input dto.CategoryMappingRequest) (models.CategoryMapping, apierrors.ApiError) {
+	mapping, err := s.get(ctx, shopID, mappingID)
+	if err != nil {
+		return models.CategoryMapping{}, err
+	}
+
+	updated := input.ToModel()
+	updated.ID = mapping.ID
+	updated.ShopID = mapping.ShopID
+	updated.UserID = mapping.UserID
+	updated.CreatedAt = mapping.CreatedAt
+	updated.UpdatedAt = time.Now().UTC()
+
+	if err := s.validate(ctx, updated); err != nil {
+		return models.CategoryMapping{}, err
+	}
+
+	if err := s.repository.Update(ctx, updated); err != nil {
+		return models.CategoryMapping{}, err
+	}
+
+	return updated, nil
+}
+
+func (s *categoryMappingsService) delete(ctx context.Context, shopID string, mappingID string) apierrors.ApiError {
+	if _, err := s.get(ctx, shopID, mappingID); err != nil {
+		return err
+	}
+
+	return s.repository.Delete(ctx, mappingID)
+}
+
+// get returns a mapping of the given scope, mappings of other shops and global ones are not found for a shop
+func (s *categoryMappingsService) get(ctx context.Context, shopID string, mappingID string) (models.CategoryMapping, apierrors.ApiError) {
+	mapping, err := s.repository.Get(ctx, mappingID)
+	if err != nil {
+		return models.CategoryMapping{}, err
+	}
+
+	if mapping.ShopID != shopID {
+		return models.CategoryMapping{}, apierrors.NewApiError("category mapping not found", "not_found", http.StatusNotFound, apierrors.CauseList{})
+	}
+
+	return mapping, nil
+}
+
+// validate checks the mapping targets a single MercadoLibre category or domain not mapped yet in its scope, and
+// points to a category (and subcategory) Jopit has
+func (s *categoryMappingsService) validate(ctx context.Context, mapping models.CategoryMapping) apierrors.ApiError {
+	if (mapping.MeliCategoryID == "") == (mapping.MeliDomainID == "") {
+		return apierrors.NewApiError("exactly one of meli_category_id or meli_domain_id is required", "bad_request", http.StatusBadRequest, apierrors.CauseList{})
+	}
+
+	if (mapping.SubcategoryID == "") != (mapping.SubcategoryName == "") {
+		return apierrors.NewApiError("subcategory_id and subcategory_name go together", "bad_request", http.StatusBadRequest, apierrors.CauseList{})
+	}
+
+	jopitCategories, err := s.itemsClient.GetCategories(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := validateJopitCategory(jopitCategories, mapping.CategoryID, mapping.SubcategoryID); err != nil {
+		return err
+	}
+
+	existing, err := s.repository.GetByShopIDs(ctx, []string{mapping.ShopID})
+	if err != nil {
+		return err
+	}
+
+	for _, other := range existing {
+		if other.ID != mapping.ID && other.MeliCategoryID == mapping.MeliCategoryID && other.MeliDomainID == mapping.MeliDomainID {
+			return apierrors.NewApiError("the category is already mapped", "conflict", http.StatusConflict, apierrors.CauseList{other.ID})
+		}
+	}
+
+	return nil
+}
+
+// validateJopitCategory checks the category exists and, when given, has the subcategory
+func validateJopitCategory(jopitCategories []models.ItemCategory, categoryID string, subcategoryID string) apierrors.ApiError {
+	categoryFound := false
+	for _, category := range jopitCategories {
+		if category.ID != categoryID {
+			continue
+		}
+		categoryFound = true
+
+		if subcategoryID == "" || (category.Subcategory != nil && category.Subcategory.ID == subcategoryID) {
+			return nil
+		}
+	}
+
+	if !categoryFound {
+		return apierrors.NewApiError(fmt.Sprintf("category_id %q is not a Jopit category", categoryID), "bad_request", http.StatusBadRequest, apierrors.CauseList{})
+	}
+
+	return apierrors.NewApiError(fmt.Sprintf("subcategory_id %q is not a subcategory of %q", subcategoryID, categoryID), "bad_request", http.StatusBadRequest, apierrors.CauseList{})
+}
@@ -44,7 +44,7 @@ func (s *companyLayout) Get(ctx context.Context, companyLayoutID string) (models
 }
 
 func (s *companyLayout) GetByShopID(ctx context.Context, shopID string) (models.CompanyLayout, apierrors.ApiError) {
-	companyLayout, err := s.repository.Get(ctx, shopID)
+	companyLayout, err := s.repository.GetByShopID(ctx, shopID)
 	if err != nil {
 		return models.CompanyLayout{}, err
 	}
@@ -2,224 +2,541 @@ package services
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"mime/multipart"
-	"os"
+	"net/http"
+	"time"
 
 	"github.com/jopitnow/jopit-api-etl/src/main/domain/clients"
 	"github.com/jopitnow/jopit-api-etl/src/main/domain/models"
 	"github.com/jopitnow/jopit-api-etl/src/main/domain/models/dto"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/repositories"
 	"github.com/jopitnow/jopit-api-etl/src/main/domain/utils"
 
 	"github.com/jopitnow/go-jopit-toolkit/goauth"
 	"github.com/jopitnow/go-jopit-toolkit/goutils/apierrors"
 )
 
+const (
+	etlLoadChunkSize = 100
+)
+
+type shopIDKey struct{}
+
+type shopLockHeldKey struct{}
+
 type EtlService interface {
-	LoadApi(ctx context.Context) (string, apierrors.ApiError)
-	LoadCsv(ctx context.Context, file *multipart.FileHeader) (string, apierrors.ApiError)
-	LoadMercadoLibre(ctx context.Context) (*ETLResult, apierrors.ApiError)
+	LoadApi(ctx context.Context) (*ETLResult, apierrors.ApiError)
+	LoadCsv(ctx context.Context, file *multipart.FileHeader) (*ETLResult, apierrors.ApiError)
+	LoadMercadoLibre(ctx context.Context, options models.EtlLoadOptions) (*ETLResult, apierrors.ApiError)
+	PreviewMercadoLibre(ctx context.Context, options models.EtlLoadOptions) (*ETLPreview, apierrors.ApiError)
+	SyncMercadoLibreItem(ctx context.Context, credentials models.MercadoLibreCredential, meliItemID string) apierrors.ApiError
 	DeleteBatch(ctx context.Context, batchID string) apierrors.ApiError
+	RollbackBatch(ctx context.Context, batchID string) (*models.EtlRollbackResult, apierrors.ApiError)
 }
 
 // ETLResult contains the results of an ETL operation
 type ETLResult struct {
-	BatchID      string       `json:"batch_id"`
-	TotalItems   int          `json:"total_items"`
-	CreatedCount int          `json:"created_count"`
-	UpdatedCount int          `json:"updated_count"`
-	FailureCount int          `json:"failure_count"`
-	FailedItems  []FailedItem `json:"failed_items,omitempty"`
+	BatchID      string                    `json:"batch_id"`
+	TotalItems   int                       `json:"total_items"`
+	CreatedCount int                       `json:"created_count"`
+	UpdatedCount int                       `json:"updated_count"`
+	FailureCount int                       `json:"failure_count"`
+	SkippedCount int                       `json:"skipped_count"`
+	FailedItems  []models.FailedItem       `json:"failed_items,omitempty"`
+	LoadedItems  []string                  `json:"loaded_items,omitempty"`
+	Warnings     []models.TransformWarning `json:"warnings,omitempty"`
 }
 
-// FailedItem represents an item that failed during ETL
-type FailedItem struct {
-	ExternalID   string `json:"external_id"`
-	Title        string `json:"title,omitempty"`
-	FailureStage string `json:"failure_stage"` // "transform" or "load"
-	ErrorMessage string `json:"error_message"`
+// ETLPreview contains the outcome of a run that stops before loading, Diff compares each item with the shop's catalog
+type ETLPreview struct {
+	BatchID        string                    `json:"batch_id"`
+	TotalItems     int                       `json:"total_items"`
+	CreateCount    int                       `json:"create_count"`
+	UpdateCount    int                       `json:"update_count"`
+	UnchangedCount int                       `json:"unchanged_count"`
+	FailureCount   int                       `json:"failure_count"`
+	SkippedCount   int                       `json:"skipped_count"`
+	Items          []models.Item             `json:"items"`
+	FailedItems    []models.FailedItem       `json:"failed_items,omitempty"`
+	Warnings       []models.TransformWarning `json:"warnings,omitempty"`
+	Diff           []models.ItemDiff         `json:"diff"`
 }
 
 type etlService struct {
 	companyConfigService CompanyLayoutService
 	itemsClient          clients.ItemsClient
+	pricesClient         clients.PricesClient
 	httpClient           clients.FetchApiClient
 	shopsClient          clients.ShopClient
 	mercadoLibreService  MercadoLibreService
+	syncCursors          repositories.SyncCursorsRepository
+	artifacts            EtlArtifactsService
+	locks                repositories.EtlLocksRepository
+	settings             EtlSettingsService
+	categoryMappings     CategoryMappingsService
+	sizeCharts           MeliSizeChartsService
+	fxRates              utils.FxRateProvider
 }
 
 func NewEtlService(
 	httpClient clients.FetchApiClient,
 	itemsClient clients.ItemsClient,
+	pricesClient clients.PricesClient,
 	shopsClient clients.ShopClient,
 	mercadoLibreService MercadoLibreService,
+	companyConfigService CompanyLayoutService,
+	syncCursors repositories.SyncCursorsRepository,
+	artifacts EtlArtifactsService,
+	locks repositories.EtlLocksRepository,
+	settings EtlSettingsService,
+	categoryMappings CategoryMappingsService,
+	sizeCharts MeliSizeChartsService,
+	fxRates utils.FxRateProvider,
 ) EtlService {
 	return &etlService{
-		httpClient:          httpClient,
-		itemsClient:         itemsClient,
-		shopsClient:         shopsClient,
-		mercadoLibreService: mercadoLibreService,
+		httpClient:           httpClient,
+		itemsClient:          itemsClient,
+		pricesClient:         pricesClient,
+		shopsClient:          shopsClient,
+		mercadoLibreService:  mercadoLibreService,
+		companyConfigService: companyConfigService,
+		syncCursors:          syncCursors,
+		artifacts:            artifacts,
+		locks:                locks,
+		settings:             settings,
+		categoryMappings:     categoryMappings,
+		sizeCharts:           sizeCharts,
+		fxRates:              fxRates,
+	}
+}
+
+// WithShopID attaches an already resolved shop to the context, so runs without the user's token
+// (jobs, schedules) do not need to call the shops API
+func WithShopID(ctx context.Context, shopID string) context.Context {
+	return context.WithValue(ctx, shopIDKey{}, shopID)
+}
+
+// withShopLockHeld marks a run whose caller already holds the shop lock, like a job
+func withShopLockHeld(ctx context.Context) context.Context {
+	return context.WithValue(ctx, shopLockHeldKey{}, true)
+}
+
+// lockShop takes the shop lock for a run writing the shop's items, runs started by a job already hold it.
+// The returned func releases the lock.
+func (s *etlService) lockShop(ctx context.Context, shopID string, owner string) (func(), apierrors.ApiError) {
+	if held, _ := ctx.Value(shopLockHeldKey{}).(bool); held {
+		return func() {}, nil
+	}
+
+	lockKey := etlShopLockKey(shopID)
+	acquired, err := s.locks.Acquire(ctx, lockKey, owner, etlShopLockTTL)
+	if err != nil {
+		return nil, err
 	}
+	if !acquired {
+		return nil, apierrors.NewApiError("an etl job is writing the items of this shop, retry when it finishes", "conflict", http.StatusConflict, apierrors.CauseList{})
+	}
+
+	return func() { s.locks.Release(context.WithoutCancel(ctx), lockKey, owner) }, nil
 }
 
-func (s *etlService) LoadApi(ctx context.Context) (string, apierrors.ApiError) {
+func (s *etlService) getShopID(ctx context.Context) (string, apierrors.ApiError) {
+	if shopID, ok := ctx.Value(shopIDKey{}).(string); ok && shopID != "" {
+		return shopID, nil
+	}
 
 	shop, err := s.shopsClient.GetShopByUserID(ctx)
 	if err != nil {
 		return "", err
 	}
 
-	companyLayout, err := s.companyConfigService.GetByShopID(ctx, shop.ID)
+	return shop.ID, nil
+}
+
+// LoadApi imports the items returned by the shop's API as described by its company layout
+func (s *etlService) LoadApi(ctx context.Context) (*ETLResult, apierrors.ApiError) {
+
+	shopID, err := s.getShopID(ctx)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	//validate Layout ??
+	companyLayout, err := s.companyConfigService.GetByShopID(ctx, shopID)
+	if err != nil {
+		return nil, err
+	}
 
-	response, err := s.httpClient.FetchAPI(ctx, companyLayout)
+	release, err := s.lockShop(ctx, shopID, fmt.Sprintf("api:%d", time.Now().UnixNano()))
 	if err != nil {
-		return "", err
+		return nil, err
 	}
+	defer release()
 
-	batchID, items := utils.Transform(response, companyLayout, fmt.Sprint(ctx.Value(goauth.FirebaseAuthHeader)))
+	progress := progressFromContext(ctx)
+	progress.StageStarted(models.EtlStageExtract, 0)
 
-	err = s.itemsClient.BulkCreateItems(ctx, items)
+	response, err := s.httpClient.FetchAPI(ctx, companyLayout)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	return batchID, nil
+	return s.loadRecords(ctx, shopID, companyLayout, response, models.ApiSourceType)
 }
 
-func (s *etlService) LoadCsv(ctx context.Context, file *multipart.FileHeader) (string, apierrors.ApiError) {
+// LoadCsv imports the rows of an uploaded CSV file as described by the shop's company layout
+func (s *etlService) LoadCsv(ctx context.Context, file *multipart.FileHeader) (*ETLResult, apierrors.ApiError) {
 
-	shop, err := s.shopsClient.GetShopByUserID(ctx)
+	shopID, err := s.getShopID(ctx)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	companyLayout, err := s.companyConfigService.GetByShopID(ctx, shop.ID)
+	companyLayout, err := s.companyConfigService.GetByShopID(ctx, shopID)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+
+	release, err := s.lockShop(ctx, shopID, fmt.Sprintf("csv:%d", time.Now().UnixNano()))
+	if err != nil {
+		return nil, err
 	}
+	defer release()
 
-	//validate Layout ??
+	progress := progressFromContext(ctx)
+	progress.StageStarted(models.EtlStageExtract, 0)
 
 	data, err := utils.ExtractFromCSV(file)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	batchID, items := utils.Transform(data, companyLayout, fmt.Sprint(ctx.Value(goauth.FirebaseAuthHeader)))
+	return s.loadRecords(ctx, shopID, companyLayout, data, models.CsvSourceType)
+}
+
+// loadRecords transforms the extracted records with the company layout and loads them
+func (s *etlService) loadRecords(ctx context.Context, shopID string, companyLayout models.CompanyLayout, records []map[string]string, sourceType string) (*ETLResult, apierrors.ApiError) {
+	if len(records) == 0 {
+		return nil, apierrors.NewApiError(fmt.Sprintf("no items found from %s", sourceType), "not_found", http.StatusNotFound, apierrors.CauseList{})
+	}
 
-	err = s.itemsClient.BulkCreateItems(ctx, items)
-	if err != nil {
-		return "", err
+	userID := fmt.Sprint(ctx.Value(goauth.FirebaseUserID))
+	progress := progressFromContext(ctx)
+
+	progress.StageStarted(models.EtlStageTransform, len(records))
+	batchID, items, failedItems := utils.Transform(records, companyLayout, userID, sourceType)
+	for _, failed := range failedItems {
+		progress.ItemFailed(failed)
+	}
+	for _, item := range items {
+		progress.ItemTransformed(item.Source.ExternalID)
+	}
+
+	s.saveArtifact(ctx, shopID, batchID, models.EtlArtifactExtracted, records)
+
+	if err := s.prepareLoad(ctx, shopID, batchID, sourceType, items); err != nil {
+		return nil, err
 	}
+	s.saveArtifact(ctx, shopID, batchID, models.EtlArtifactTransformed, items)
+
+	loaded := s.loadItems(ctx, items)
+	failedItems = append(failedItems, loaded.failedItems...)
 
-	return batchID, nil
+	result := &ETLResult{
+		BatchID:      batchID,
+		TotalItems:   len(records),
+		CreatedCount: loaded.createdCount,
+		UpdatedCount: loaded.updatedCount,
+		FailureCount: len(failedItems),
+		FailedItems:  failedItems,
+		LoadedItems:  loaded.loadedItems,
+		Warnings:     loaded.warnings,
+	}
+
+	s.saveArtifact(ctx, shopID, batchID, models.EtlArtifactFailed, failedItems)
+
+	return finishResult(ctx, result)
 }
 
+// DeleteBatch removes from the user's shop the items imported by a batch, along with their prices
 func (s *etlService) DeleteBatch(ctx context.Context, batchID string) apierrors.ApiError {
-	return s.itemsClient.BulkDeleteItems(ctx, batchID)
+	shopID, err := s.getShopID(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Hold the shop lock so no run loads the shop while its batch is deleted
+	release, err := s.lockShop(ctx, shopID, fmt.Sprintf("delete:%s", batchID))
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	currentItems, err := s.itemsClient.GetItemsByShopID(ctx, shopID)
+	if err != nil {
+		return err
+	}
+
+	// Prices go first, a retried delete no longer sees the items once they are deleted
+	batchItemIDs := itemIDsByBatch(currentItems, batchID)
+	for start := 0; start < len(batchItemIDs); start += etlLoadChunkSize {
+		chunk := batchItemIDs[start:min(start+etlLoadChunkSize, len(batchItemIDs))]
+		if err := s.pricesClient.BulkDeletePrices(ctx, chunk); err != nil {
+			return err
+		}
+	}
+
+	return s.itemsClient.BulkDeleteItems(ctx, shopID, batchID)
 }
 
-// LoadMercadoLibre performs full ETL from MercadoLibre to Jopit Items
-func (s *etlService) LoadMercadoLibre(ctx context.Context) (*ETLResult, apierrors.ApiError) {
-	// Get shop and user info
-	shop, err := s.shopsClient.GetShopByUserID(ctx)
+// RollbackBatch undoes a run of the user's shop: the items it created are deleted and the items it updated get
+// back the state captured before loading. Items changed by a later run are left alone.
+func (s *etlService) RollbackBatch(ctx context.Context, batchID string) (*models.EtlRollbackResult, apierrors.ApiError) {
+	shopID, err := s.getShopID(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	userID := fmt.Sprint(ctx.Value(goauth.FirebaseUserID))
-	batchID := fmt.Sprintf("meli-%s", userID)
+	var snapshot models.EtlBatchSnapshot
+	if err := s.artifacts.Load(ctx, shopID, batchID, models.EtlArtifactSnapshot, &snapshot); err != nil {
+		if err.Status() == http.StatusNotFound {
+			return nil, apierrors.NewApiError(fmt.Sprintf("batch %s has no snapshot to roll back", batchID), "not_found", http.StatusNotFound, apierrors.CauseList{})
+		}
+		return nil, err
+	}
 
-	// STEP 1: EXTRACT - Get all MercadoLibre items with pagination
-	meliItems, err := s.mercadoLibreService.GetUserItemsDetailsWithPagination(ctx, 50) // 50 items per page
+	// Hold the shop lock so no run loads the shop while it is rolled back
+	release, err := s.lockShop(ctx, shopID, fmt.Sprintf("rollback:%s", batchID))
 	if err != nil {
 		return nil, err
 	}
+	defer release()
 
-	if len(meliItems) == 0 {
-		return nil, apierrors.NewApiError("no items found from MercadoLibre", "not_found", 404, apierrors.CauseList{})
+	currentItems, err := s.itemsClient.GetItemsByShopID(ctx, shopID)
+	if err != nil {
+		return nil, err
 	}
 
-	// Write MercadoLibre items to JSON file
-	if meliJSON, err := json.MarshalIndent(meliItems, "", "  "); err == nil {
-		os.WriteFile("meli-items-extracted.json", meliJSON, 0644)
+	restore, deleted, skipped := utils.PlanRollback(snapshot, currentItems)
+
+	result := &models.EtlRollbackResult{
+		BatchID:  batchID,
+		Restored: make([]string, 0, len(restore)),
+		Deleted:  deleted,
+		Skipped:  skipped,
 	}
 
-	// STEP 2: TRANSFORM - Convert MercadoLibre items to Jopit format
-	jopitItems := make([]models.Item, 0, len(meliItems))
-	failedItems := make([]FailedItem, 0)
+	for start := 0; start < len(restore); start += etlLoadChunkSize {
+		chunk := restore[start:min(start+etlLoadChunkSize, len(restore))]
+		if _, err := s.itemsClient.BulkUpsertItems(ctx, chunk); err != nil {
+			return result, err
+		}
+		// Items snapshotted without a price keep the current one
+		if prices := restorablePrices(chunk); len(prices) > 0 {
+			if err := s.pricesClient.BulkUpsertPrices(ctx, prices); err != nil {
+				return result, err
+			}
+		}
+		for _, item := range chunk {
+			result.Restored = append(result.Restored, item.Source.ExternalID)
+		}
+	}
 
-	for _, meliItem := range meliItems {
-		// Transform with error handling
-		jopitItem, transformErr := s.transformMeliItem(ctx, meliItem, shop.ID, userID, batchID)
+	// Created items are deleted by id, restored items may still carry the batch id until their next run
+	deletedIDs := itemIDsByExternalID(currentItems, snapshot.SourceType, deleted)
+	for start := 0; start < len(deletedIDs); start += etlLoadChunkSize {
+		chunk := deletedIDs[start:min(start+etlLoadChunkSize, len(deletedIDs))]
+		// Prices go first, a retried rollback no longer sees the items once they are deleted
+		if err := s.pricesClient.BulkDeletePrices(ctx, chunk); err != nil {
+			return result, err
+		}
+		if err := s.itemsClient.BulkDeleteItemsByID(ctx, shopID, chunk); err != nil {
+			return result, err
+		}
+	}
 
-		if transformErr != nil {
-			// Log failure and continue
-			failedItems = append(failedItems, FailedItem{
-				ExternalID:   meliItem.ID,
-				Title:        meliItem.Title,
-				FailureStage: "transform",
-				ErrorMessage: transformErr.Error(),
-			})
-			continue
+	return result, nil
+}
+
+// itemIDsByExternalID returns the ids of the items of a source with the given external ids
+func itemIDsByExternalID(items []models.Item, sourceType string, externalIDs []string) []string {
+	wanted := make(map[string]bool, len(externalIDs))
+	for _, externalID := range externalIDs {
+		wanted[externalID] = true
+	}
+
+	ids := make([]string, 0, len(externalIDs))
+	for _, item := range items {
+		if item.Source != nil && item.Source.SourceType == sourceType && wanted[item.Source.ExternalID] {
+			ids = append(ids, item.ID)
 		}
+	}
+	return ids
+}
 
-		jopitItems = append(jopitItems, jopitItem)
+// itemIDsByBatch returns the ids of the items last written by the batch
+func itemIDsByBatch(items []models.Item, batchID string) []string {
+	ids := make([]string, 0)
+	for _, item := range items {
+		if item.Source != nil && item.Source.BatchID == batchID {
+			ids = append(ids, item.ID)
+		}
 	}
+	return ids
+}
 
-	// Write Jopit items to JSON file
-	if jopitJSON, err := json.MarshalIndent(jopitItems, "", "  "); err == nil {
-		os.WriteFile("jopit-items-transformed.json", jopitJSON, 0644)
+// prepareLoad runs before a run loads anything. Items already in the shop take the id of the existing item,
+// and the before-image of the run is saved. Unlike the other artifacts the snapshot must be written, a run that
+// could not be rolled back is not started.
+func (s *etlService) prepareLoad(ctx context.Context, shopID, batchID, sourceType string, items []models.Item) apierrors.ApiError {
+	if len(items) == 0 || ctx.Err() != nil {
+		return nil
 	}
 
-	// Write failed items to JSON file if any
-	if len(failedItems) > 0 {
-		if failedJSON, err := json.MarshalIndent(failedItems, "", "  "); err == nil {
-			os.WriteFile("jopit-items-failed.json", failedJSON, 0644)
+	existingItems, err := s.itemsClient.GetItemsByShopID(ctx, shopID)
+	if err != nil {
+		return err
+	}
+
+	utils.ReconcileItemIdentity(existingItems, items)
+	snapshot := utils.SnapshotBatch(batchID, shopID, sourceType, existingItems, items)
+
+	// Prices live in the prices API, the before-image needs them to restore what the run overwrites
+	if err := s.attachCurrentPrices(ctx, snapshot.Updated); err != nil {
+		return err
+	}
+
+	return s.artifacts.Save(ctx, shopID, batchID, models.EtlArtifactSnapshot, snapshot)
+}
+
+// attachCurrentPrices sets the price the prices API has for each of the shop's items, items without one keep
+// an empty price
+func (s *etlService) attachCurrentPrices(ctx context.Context, items []models.Item) apierrors.ApiError {
+	for start := 0; start < len(items); start += etlLoadChunkSize {
+		chunk := items[start:min(start+etlLoadChunkSize, len(items))]
+
+		itemIDs := make([]string, 0, len(chunk))
+		for _, item := range chunk {
+			itemIDs = append(itemIDs, item.ID)
+		}
+
+		prices, err := s.pricesClient.GetItemsPrices(ctx, itemIDs)
+		if err != nil {
+			return err
+		}
+
+		pricesByItemID := make(map[string]models.Price, len(prices.Prices))
+		for _, price := range prices.Prices {
+			pricesByItemID[price.ItemID] = price
+		}
+		for i := range chunk {
+			chunk[i].Price = pricesByItemID[chunk[i].ID]
 		}
 	}
 
-	// STEP 3: LOAD - Bulk upsert items into Jopit Items API
-	var createdCount int64
-	var updatedCount int64
+	return nil
+}
+
+// loadOutput is what the load stage of a run produces
+type loadOutput struct {
+	createdCount int
+	updatedCount int
+	loadedItems  []string
+	failedItems  []models.FailedItem
+	warnings     []models.TransformWarning
+}
+
+// loadItems bulk upserts the items into Jopit Items API in chunks, so a cancellation stops between chunks.
+// The prices of each loaded chunk go to the prices API, an item whose price was not saved is loaded with a warning.
+func (s *etlService) loadItems(ctx context.Context, items []models.Item) loadOutput {
+	progress := progressFromContext(ctx)
+	output := loadOutput{
+		loadedItems: make([]string, 0, len(items)),
+		failedItems: make([]models.FailedItem, 0),
+		warnings:    make([]models.TransformWarning, 0),
+	}
 
-	if len(jopitItems) > 0 {
-		upsertResponse, upsertErr := s.itemsClient.BulkUpsertItems(ctx, jopitItems)
+	if len(items) > 0 && ctx.Err() == nil {
+		progress.StageStarted(models.EtlStageLoad, len(items))
+	}
+
+	for start := 0; start < len(items) && ctx.Err() == nil; start += etlLoadChunkSize {
+		chunk := items[start:min(start+etlLoadChunkSize, len(items))]
+
+		upsertResponse, upsertErr := s.itemsClient.BulkUpsertItems(ctx, chunk)
 		if upsertErr != nil {
-			// If bulk upsert fails entirely, mark all as failed
-			for _, item := range jopitItems {
-				failedItems = append(failedItems, FailedItem{
+			// If the chunk upsert fails entirely, mark all its items as failed
+			for _, item := range chunk {
+				failed := models.FailedItem{
 					ExternalID:   item.Source.ExternalID,
 					Title:        item.Name,
-					FailureStage: "load",
+					FailureStage: models.EtlStageLoad,
 					ErrorMessage: upsertErr.Message(),
+				}
+				output.failedItems = append(output.failedItems, failed)
+				progress.ItemFailed(failed)
+			}
+			continue
+		}
+
+		output.createdCount += int(upsertResponse.CreatedCount)
+		output.updatedCount += int(upsertResponse.UpdatedCount)
+		for _, item := range chunk {
+			output.loadedItems = append(output.loadedItems, item.Source.ExternalID)
+			progress.ItemSucceeded(item.Source.ExternalID)
+		}
+
+		if pricesErr := s.pricesClient.BulkUpsertPrices(ctx, itemPrices(chunk)); pricesErr != nil {
+			for _, item := range chunk {
+				output.warnings = append(output.warnings, models.TransformWarning{
+					ExternalID: item.Source.ExternalID,
+					Title:      item.Name,
+					Code:       models.LoadWarningPriceNotSaved,
+					Message:    pricesErr.Message(),
 				})
 			}
-			jopitItems = []models.Item{} // Clear successful items
-		} else {
-			createdCount = upsertResponse.CreatedCount
-			updatedCount = upsertResponse.UpdatedCount
 		}
 	}
 
-	result := &ETLResult{
-		BatchID:      batchID,
-		TotalItems:   len(meliItems),
-		CreatedCount: int(createdCount),
-		UpdatedCount: int(updatedCount),
-		FailureCount: len(failedItems),
-		FailedItems:  failedItems,
+	return output
+}
+
+// itemPrices returns the price of every item, tied to the item's id
+func itemPrices(items []models.Item) []models.Price {
+	prices := make([]models.Price, 0, len(items))
+	for _, item := range items {
+		price := item.Price
+		price.ItemID = item.ID
+		if price.ShopID == "" {
+			price.ShopID = item.ShopID
+		}
+		prices = append(prices, price)
+	}
+	return prices
+}
+
+// restorablePrices returns the prices of the snapshotted items that carry one
+func restorablePrices(items []models.Item) []models.Price {
+	prices := make([]models.Price, 0, len(items))
+	for _, price := range itemPrices(items) {
+		if price.Amount > 0 {
+			prices = append(prices, price)
+		}
+	}
+	return prices
+}
+
+// finishResult turns a run result into its error: cancelled runs keep their partial result,
+// and a run only fails when every item failed
+func finishResult(ctx context.Context, result *ETLResult) (*ETLResult, apierrors.ApiError) {
+	// A cancelled run keeps its partial result, LoadedItems tells what already reached Jopit
+	if ctx.Err() != nil {
+		return result, cancelledError(ctx)
 	}
 
 	// Return error only if ALL items failed
 	successCount := result.CreatedCount + result.UpdatedCount
-	if successCount == 0 && result.FailureCount > 0 {
+	if successCount == 0 && result.FailureCount > 0 && result.SkippedCount == 0 {
 		return result, apierrors.NewApiError(
 			fmt.Sprintf("all %d items failed to load", result.FailureCount),
 			"etl_failed",
@@ -228,19 +545,349 @@ func (s *etlService) LoadMercadoLibre(ctx context.Context) (*ETLResult, apierror
 		)
 	}
 
-	// If at least one succeeded, return 200 (handled in handler)
 	return result, nil
 }
 
+// meliTransformOutput is what the extract and transform stages of a MercadoLibre run produce
+type meliTransformOutput struct {
+	// totalItems counts the extracted items and the ones that failed to extract
+	totalItems   int
+	meliItems    []dto.MeliItemResponse
+	jopitItems   []models.Item
+	failedItems  []models.FailedItem
+	warnings     []models.TransformWarning
+	skippedCount int
+	cursor       models.SyncCursor
+}
+
+// LoadMercadoLibre performs full ETL from MercadoLibre to Jopit Items
+func (s *etlService) LoadMercadoLibre(ctx context.Context, options models.EtlLoadOptions) (*ETLResult, apierrors.ApiError) {
+	// Get shop and user info
+	shopID, err := s.getShopID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	userID := fmt.Sprint(ctx.Value(goauth.FirebaseUserID))
+	batchID := utils.NewBatchID(models.MeliSourceType)
+
+	output, err := s.extractAndTransformMeli(ctx, shopID, userID, batchID, options)
+	if err != nil {
+		return nil, err
+	}
+	meliItems, jopitItems, failedItems := output.meliItems, output.jopitItems, output.failedItems
+
+	s.saveArtifact(ctx, shopID, batchID, models.EtlArtifactExtracted, meliItems)
+
+	if err := s.prepareLoad(ctx, shopID, batchID, models.MeliSourceType, jopitItems); err != nil {
+		return nil, err
+	}
+	s.saveArtifact(ctx, shopID, batchID, models.EtlArtifactTransformed, jopitItems)
+
+	// STEP 3: LOAD
+	loaded := s.loadItems(ctx, jopitItems)
+	failedItems = append(failedItems, loaded.failedItems...)
+
+	result := &ETLResult{
+		BatchID:      batchID,
+		TotalItems:   output.totalItems,
+		CreatedCount: loaded.createdCount,
+		UpdatedCount: loaded.updatedCount,
+		FailureCount: len(failedItems),
+		FailedItems:  failedItems,
+		LoadedItems:  loaded.loadedItems,
+		SkippedCount: output.skippedCount,
+		Warnings:     append(output.warnings, loaded.warnings...),
+	}
+
+	s.saveArtifact(ctx, shopID, batchID, models.EtlArtifactFailed, failedItems)
+	s.saveSyncCursor(ctx, output.cursor, meliItems, loaded.loadedItems)
+
+	// If at least one succeeded, return 200 (handled in handler)
+	return finishResult(ctx, result)
+}
+
+// SyncMercadoLibreItem re-imports a single MercadoLibre item of the seller owning the credentials.
+// It fails with a conflict while another load or job is writing the shop's items.
+func (s *etlService) SyncMercadoLibreItem(ctx context.Context, credentials models.MercadoLibreCredential, meliItemID string) apierrors.ApiError {
+	unlock, err := s.lockShop(ctx, credentials.ShopID, fmt.Sprintf("notification:%s", meliItemID))
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	meliItem, err := s.mercadoLibreService.GetItemWithCredentials(ctx, credentials, meliItemID)
+	if err != nil {
+		return err
+	}
+
+	if meliItem.SellerID != credentials.UserIDMeli {
+		return apierrors.NewApiError(fmt.Sprintf("item %s does not belong to seller %d", meliItemID, credentials.UserIDMeli), "forbidden", http.StatusForbidden, apierrors.CauseList{})
+	}
+
+	// A notification sync loads a single item without a snapshot, so its batch cannot be rolled back
+	batchID := utils.NewBatchID(models.MeliSourceType)
+
+	config, err := s.meliTransformConfig(ctx, credentials.ShopID, credentials.UserID, credentials.Site(), batchID)
+	if err != nil {
+		return err
+	}
+
+	sizeCharts := newRunSizeCharts(s.sizeCharts, credentials.AccessToken)
+	jopitItem, _, transformErr := s.transformMeliItem(ctx, meliItem, config, sizeCharts)
+	if transformErr != nil {
+		return apierrors.NewApiError(fmt.Sprintf("error transforming item %s", meliItemID), "etl_failed", http.StatusInternalServerError, apierrors.CauseList{transformErr.Error()})
+	}
+
+	// Items imported before ids were derived keep their id
+	existingItem, err := s.itemsClient.GetItemBySource(ctx, credentials.ShopID, models.MeliSourceType, meliItem.ID)
+	if err != nil {
+		return err
+	}
+	jopitItems := []models.Item{jopitItem}
+	if existingItem != nil {
+		utils.ReconcileItemIdentity([]models.Item{*existingItem}, jopitItems)
+	}
+
+	if _, err := s.itemsClient.BulkUpsertItems(ctx, jopitItems); err != nil {
+		return err
+	}
+	if err := s.pricesClient.BulkUpsertPrices(ctx, itemPrices(jopitItems)); err != nil {
+		return err
+	}
+
+	// Keep the incremental cursor in sync so the next incremental run does not import the item again
+	cursor := s.getSyncCursor(ctx, credentials.ShopID)
+	if cursor.Items == nil {
+		cursor.Items = map[string]string{}
+	}
+	cursor.Items[meliItem.ID] = meliItem.LastUpdated
+	cursor.UpdatedAt = time.Now().UTC()
+	_ = s.syncCursors.Save(ctx, cursor)
+
+	return nil
+}
+
+// PreviewMercadoLibre runs the extract and transform stages without loading anything, returning the
+// transformed items and how each of them would change the shop's current Jopit catalog. The preview is
+// also saved as the preview artifact of its batch.
+func (s *etlService) PreviewMercadoLibre(ctx context.Context, options models.EtlLoadOptions) (*ETLPreview, apierrors.ApiError) {
+	shopID, err := s.getShopID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	userID := fmt.Sprint(ctx.Value(goauth.FirebaseUserID))
+	batchID := utils.NewBatchID(models.MeliSourceType)
+
+	output, err := s.extractAndTransformMeli(ctx, shopID, userID, batchID, options)
+	if err != nil {
+		return nil, err
+	}
+
+	existingItems, err := s.itemsClient.GetItemsByShopID(ctx, shopID)
+	if err != nil {
+		return nil, err
+	}
+	utils.ReconcileItemIdentity(existingItems, output.jopitItems)
+
+	// Prices live in the prices API, without them every existing item would diff as a price change
+	if err := s.attachCurrentPrices(ctx, existingItems); err != nil {
+		return nil, err
+	}
+
+	preview := &ETLPreview{
+		BatchID:      batchID,
+		TotalItems:   output.totalItems,
+		SkippedCount: output.skippedCount,
+		FailureCount: len(output.failedItems),
+		Items:        output.jopitItems,
+		FailedItems:  output.failedItems,
+		Warnings:     output.warnings,
+		Diff:         utils.DiffItems(existingItems, output.jopitItems),
+	}
+
+	for _, diff := range preview.Diff {
+		switch diff.Action {
+		case models.ItemDiffCreate:
+			preview.CreateCount++
+		case models.ItemDiffUpdate:
+			preview.UpdateCount++
+		default:
+			preview.UnchangedCount++
+		}
+	}
+
+	// The preview is too large for the job record, it is downloaded as an artifact of its batch
+	s.saveArtifact(ctx, shopID, batchID, models.EtlArtifactPreview, preview)
+
+	return preview, nil
+}
+
+// extractAndTransformMeli fetches the seller's MercadoLibre catalog and converts it to Jopit items,
+// items failing to transform are reported instead of aborting the run
+func (s *etlService) extractAndTransformMeli(ctx context.Context, shopID, userID, batchID string, options models.EtlLoadOptions) (*meliTransformOutput, apierrors.ApiError) {
+	progress := progressFromContext(ctx)
+
+	// Credentials are resolved once and renewed between stages when their token is about to expire
+	credentials, err := s.mercadoLibreService.GetCredentials(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// STEP 1: EXTRACT - Get all MercadoLibre items with pagination
+	progress.StageStarted(models.EtlStageExtract, 0)
+	meliItems, extractFailures, err := s.mercadoLibreService.GetUserItemsDetailsWithPagination(ctx, credentials, 50) // 50 items per page
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, cancelledError(ctx)
+		}
+		return nil, err
+	}
+
+	if len(meliItems) == 0 && len(extractFailures) == 0 {
+		return nil, apierrors.NewApiError("no items found from MercadoLibre", "not_found", 404, apierrors.CauseList{})
+	}
+
+	// Incremental runs only re-import the items updated at MercadoLibre since the last sync
+	cursor := s.getSyncCursor(ctx, shopID)
+	itemsToImport := meliItems
+	skippedCount := 0
+	if options.Incremental {
+		itemsToImport, skippedCount = utils.FilterUpdatedMeliItems(meliItems, cursor.Items)
+	}
+
+	credentials, err = s.mercadoLibreService.RenewCredentials(ctx, credentials)
+	if err != nil {
+		return nil, err
+	}
+
+	// Details take a call per item, so they are only fetched for the items about to be transformed
+	if err := s.mercadoLibreService.AttachItemsDetails(ctx, credentials, itemsToImport); err != nil {
+		return nil, err
+	}
+
+	config, err := s.meliTransformConfig(ctx, shopID, userID, credentials.Site(), batchID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Size charts are read from MercadoLibre while transforming
+	credentials, err = s.mercadoLibreService.RenewCredentials(ctx, credentials)
+	if err != nil {
+		return nil, err
+	}
+
+	// STEP 2: TRANSFORM - Convert MercadoLibre items to Jopit format
+	progress.StageStarted(models.EtlStageTransform, len(itemsToImport))
+	jopitItems := make([]models.Item, 0, len(itemsToImport))
+	failedItems := make([]models.FailedItem, 0, len(extractFailures))
+	warnings := make([]models.TransformWarning, 0)
+	sizeCharts := newRunSizeCharts(s.sizeCharts, credentials.AccessToken)
+
+	for _, failed := range extractFailures {
+		failedItems = append(failedItems, failed)
+		progress.ItemFailed(failed)
+	}
+
+	for _, meliItem := range itemsToImport {
+		// Stop transforming as soon as the run is cancelled
+		if ctx.Err() != nil {
+			break
+		}
+
+		// Transform with error handling
+		jopitItem, itemWarnings, transformErr := s.transformMeliItem(ctx, meliItem, config, sizeCharts)
+
+		if transformErr != nil {
+			// Log failure and continue
+			failed := models.FailedItem{
+				ExternalID:   meliItem.ID,
+				Title:        meliItem.Title,
+				FailureStage: models.EtlStageTransform,
+				ErrorMessage: transformErr.Error(),
+			}
+			failedItems = append(failedItems, failed)
+			progress.ItemFailed(failed)
+			continue
+		}
+
+		jopitItems = append(jopitItems, jopitItem)
+		warnings = append(warnings, itemWarnings...)
+		progress.ItemTransformed(meliItem.ID)
+	}
+
+	return &meliTransformOutput{
+		totalItems:   len(meliItems) + len(extractFailures),
+		meliItems:    meliItems,
+		jopitItems:   jopitItems,
+		failedItems:  failedItems,
+		warnings:     warnings,
+		skippedCount: skippedCount,
+		cursor:       cursor,
+	}, nil
+}
+
+// saveArtifact keeps a run artifact for download, a failed write must not fail the run
+func (s *etlService) saveArtifact(ctx context.Context, shopID, batchID, kind string, value interface{}) {
+	_ = s.artifacts.Save(context.WithoutCancel(ctx), shopID, batchID, kind, value)
+}
+
+// getSyncCursor returns the shop's MercadoLibre sync cursor, an empty one on the first sync
+func (s *etlService) getSyncCursor(ctx context.Context, shopID string) models.SyncCursor {
+	cursor, err := s.syncCursors.GetByShopID(ctx, shopID, models.MeliSourceType)
+	if err != nil {
+		return models.SyncCursor{ShopID: shopID, SourceType: models.MeliSourceType, Items: map[string]string{}}
+	}
+
+	return cursor
+}
+
+// saveSyncCursor records the last_updated of the loaded items, also for cancelled runs since those items did reach Jopit
+func (s *etlService) saveSyncCursor(ctx context.Context, cursor models.SyncCursor, meliItems []dto.MeliItemResponse, loadedItems []string) {
+	now := time.Now().UTC()
+
+	cursor.Items = utils.MergeSyncedItems(meliItems, cursor.Items, loadedItems)
+	cursor.UpdatedAt = now
+	if ctx.Err() == nil {
+		cursor.LastRunAt = now
+	}
+
+	// A failed cursor write only makes the next incremental run re-import more items
+	_ = s.syncCursors.Save(context.WithoutCancel(ctx), cursor)
+}
+
+// meliTransformConfig loads the shop data every item of a MercadoLibre run is transformed with, siteID is the
+// site of the seller's MercadoLibre account
+func (s *etlService) meliTransformConfig(ctx context.Context, shopID, userID, siteID, batchID string) (utils.MeliTransformConfig, apierrors.ApiError) {
+	settings, err := s.settings.GetByShopID(ctx, shopID)
+	if err != nil {
+		return utils.MeliTransformConfig{}, err
+	}
+
+	categoryMappings, err := s.categoryMappings.GetByShopID(ctx, shopID)
+	if err != nil {
+		return utils.MeliTransformConfig{}, err
+	}
+
+	return utils.MeliTransformConfig{
+		ShopID:           shopID,
+		UserID:           userID,
+		BatchID:          batchID,
+		Settings:         settings,
+		CategoryMappings: categoryMappings,
+		FxRates:          s.fxRates,
+		SiteID:           siteID,
+	}, nil
+}
+
 // transformMeliItem safely transforms a single MercadoLibre item with error recovery
 func (s *etlService) transformMeliItem(
 	ctx context.Context,
 	meliItem dto.MeliItemResponse,
-	shopID, userID, batchID string,
-) (models.Item, error) {
-	var result models.Item
-	var transformErr error
-
+	config utils.MeliTransformConfig,
+	sizeCharts *runSizeCharts,
+) (result models.Item, warnings []models.TransformWarning, transformErr error) {
 	// Recover from panics during transformation
 	defer func() {
 		if r := recover(); r != nil {
@@ -252,17 +899,14 @@ func (s *etlService) transformMeliItem(
 	sizeChartID := utils.ExtractSizeChartID(meliItem.Attributes)
 	var sizeChart *dto.MeliSizeChartResponse
 
-	// Fetch size chart if ID exists
+	// Fetch size chart if ID exists, items sharing a chart fetch it once per run
 	if sizeChartID != "" {
-		chart, chartErr := s.mercadoLibreService.GetSizeChart(ctx, sizeChartID)
-		if chartErr == nil {
-			sizeChart = &chart
-		}
 		// Continue even if size chart fetch fails
+		sizeChart = sizeCharts.get(ctx, sizeChartID)
 	}
 
 	// Transform item
-	result = utils.TransformMeliItemToJopitItem(meliItem, shopID, userID, batchID, sizeChart)
+	result, warnings = utils.TransformMeliItemToJopitItem(meliItem, sizeChart, config)
 
-	return result, transformErr
+	return result, warnings, nil
 }
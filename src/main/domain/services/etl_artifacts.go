@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jopitnow/go-jopit-toolkit/goutils/apierrors"
+	"github.com/jopitnow/go-jopit-toolkit/goutils/logger"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/clients"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/repositories"
+)
+
+// Expired batches are looked for this often
+const etlArtifactsCleanupInterval = time.Hour
+
+// EtlArtifactsService keeps the raw extract, the transform output and the failures of every run, by shop and batch.
+// Batches are deleted once they are older than the retention, after that they can no longer be downloaded or rolled back.
+type EtlArtifactsService interface {
+	Save(ctx context.Context, shopID string, batchID string, kind string, value interface{}) apierrors.ApiError
+	Open(ctx context.Context, batchID string, kind string) (io.ReadCloser, int64, apierrors.ApiError)
+	Load(ctx context.Context, shopID string, batchID string, kind string, value interface{}) apierrors.ApiError
+}
+
+type etlArtifactsService struct {
+	store       repositories.ArtifactStore
+	shopsClient clients.ShopClient
+	retention   time.Duration
+}
+
+// NewEtlArtifactsService creates the artifacts service and, with a positive retention, starts the loop deleting
+// expired batches. Every replica runs the loop, deleting a batch twice is harmless.
+func NewEtlArtifactsService(store repositories.ArtifactStore, shopsClient clients.ShopClient, retention time.Duration) EtlArtifactsService {
+	s := &etlArtifactsService{
+		store:       store,
+		shopsClient: shopsClient,
+		retention:   retention,
+	}
+
+	if retention > 0 {
+		go s.cleanupLoop()
+	}
+
+	return s
+}
+
+func (s *etlArtifactsService) Save(ctx context.Context, shopID string, batchID string, kind string, value interface{}) apierrors.ApiError {
+	content, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return apierrors.NewApiError("error encoding etl artifact", "internal_server_error", http.StatusInternalServerError, apierrors.CauseList{err.Error()})
+	}
+
+	return s.store.Save(ctx, artifactKey(shopID, batchID, kind), content)
+}
+
+// Open returns an artifact of the requesting user's shop, artifacts of other shops are never reachable
+func (s *etlArtifactsService) Open(ctx context.Context, batchID string, kind string) (io.ReadCloser, int64, apierrors.ApiError) {
+	if !models.IsEtlArtifact(kind) {
+		return nil, 0, apierrors.NewApiError(fmt.Sprintf("unknown artifact %q, expected %s, %s, %s, %s or %s", kind, models.EtlArtifactExtracted, models.EtlArtifactTransformed, models.EtlArtifactFailed, models.EtlArtifactSnapshot, models.EtlArtifactPreview), "bad_request", http.StatusBadRequest, apierrors.CauseList{})
+	}
+
+	if err := validateBatchID(batchID); err != nil {
+		return nil, 0, err
+	}
+
+	shop, err := s.shopsClient.GetShopByUserID(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return s.store.Open(ctx, artifactKey(shop.ID, batchID, kind))
+}
+
+// Load decodes an artifact of a shop into value, for the service itself rather than for download
+func (s *etlArtifactsService) Load(ctx context.Context, shopID string, batchID string, kind string, value interface{}) apierrors.ApiError {
+	if err := validateBatchID(batchID); err != nil {
+		return err
+	}
+
+	artifact, _, err := s.store.Open(ctx, artifactKey(shopID, batchID, kind))
+	if err != nil {
+		return err
+	}
+	defer artifact.Close()
+
+	if err := json.NewDecoder(artifact).Decode(value); err != nil {
+		return apierrors.NewApiError("error decoding etl artifact", "internal_server_error", http.StatusInternalServerError, apierrors.CauseList{err.Error()})
+	}
+
+	return nil
+}
+
+func (s *etlArtifactsService) cleanupLoop() {
+	ticker := time.NewTicker(etlArtifactsCleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		s.deleteExpired(context.Background())
+		<-ticker.C
+	}
+}
+
+func (s *etlArtifactsService) deleteExpired(ctx context.Context) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Errorf("panic deleting expired etl artifacts", fmt.Errorf("%v", r))
+		}
+	}()
+
+	if _, err := s.store.DeleteOlderThan(ctx, time.Now().Add(-s.retention)); err != nil {
+		logger.Errorf("error deleting expired etl artifacts", err)
+	}
+}
+
+func validateBatchID(batchID string) apierrors.ApiError {
+	if batchID == "" || strings.ContainsAny(batchID, "/\\") || batchID == "." || batchID == ".." {
+		return apierrors.NewApiError("invalid batch id", "bad_request", http.StatusBadRequest, apierrors.CauseList{})
+	}
+	return nil
+}
+
+func artifactKey(shopID string, batchID string, kind string) string {
+	return fmt.Sprintf("%s/%s/%s.json", shopID, batchID, kind)
+}
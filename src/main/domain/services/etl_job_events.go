@@ -0,0 +1,60 @@
+package services
+
+import (
+	"sync"
+
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models"
+)
+
+const etlJobEventsBufferSize = 64
+
+// etlJobEventBroker fans out the events of the jobs running on this replica to their subscribers
+type etlJobEventBroker struct {
+	mu          sync.Mutex
+	subscribers map[string]map[chan models.EtlJobEvent]struct{}
+}
+
+func newEtlJobEventBroker() *etlJobEventBroker {
+	return &etlJobEventBroker{
+		subscribers: make(map[string]map[chan models.EtlJobEvent]struct{}),
+	}
+}
+
+func (b *etlJobEventBroker) subscribe(jobID string) (<-chan models.EtlJobEvent, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ch := make(chan models.EtlJobEvent, etlJobEventsBufferSize)
+	if b.subscribers[jobID] == nil {
+		b.subscribers[jobID] = make(map[chan models.EtlJobEvent]struct{})
+	}
+	b.subscribers[jobID][ch] = struct{}{}
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+
+			delete(b.subscribers[jobID], ch)
+			if len(b.subscribers[jobID]) == 0 {
+				delete(b.subscribers, jobID)
+			}
+		})
+	}
+
+	return ch, unsubscribe
+}
+
+func (b *etlJobEventBroker) publish(event models.EtlJobEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subscribers[event.JobID] {
+		// Slow subscribers lose events instead of blocking the pipeline, they catch up with the next snapshot
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
@@ -0,0 +1,579 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/jopitnow/go-jopit-toolkit/goauth"
+	"github.com/jopitnow/go-jopit-toolkit/goutils/apierrors"
+	"github.com/jopitnow/go-jopit-toolkit/goutils/logger"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/clients"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/repositories"
+)
+
+const (
+	// Persist item level progress every N processed items to avoid one write per item
+	etlJobProgressFlushEvery = 25
+
+	// Job records list at most this many loaded and failed items and warnings, the batch artifacts keep them all
+	etlJobMaxListedItems = 100
+
+	// Replicas renew the heartbeat of the jobs they hold, jobs that missed a few heartbeats were lost with their replica
+	etlJobHeartbeatEvery = time.Minute
+	etlJobStaleAfter     = 5 * etlJobHeartbeatEvery
+
+	// A shop lock outlives a crashed replica by at most this long, running jobs renew it every third of it
+	etlShopLockTTL = 10 * time.Minute
+)
+
+// errEtlShopLockLost cancels a run whose shop lease could not be renewed, another run may take the shop after it expires
+var errEtlShopLockLost = errors.New("etl job lost the lock of its shop, please start it again")
+
+// errEtlJobTakenAway cancels a run whose job record was finished elsewhere, like a job failed as stale by another replica
+var errEtlJobTakenAway = errors.New("etl job was finished by another replica, please start it again")
+
+type EtlJobsService interface {
+	EnqueueMercadoLibre(ctx context.Context, options models.EtlLoadOptions) (models.EtlJob, apierrors.ApiError)
+	EnqueueMercadoLibrePreview(ctx context.Context, options models.EtlLoadOptions) (models.EtlJob, apierrors.ApiError)
+	EnqueueScheduled(ctx context.Context, schedule models.EtlSchedule) (models.EtlJob, apierrors.ApiError)
+	Get(ctx context.Context, jobID string) (models.EtlJob, apierrors.ApiError)
+	GetByUserID(ctx context.Context) ([]models.EtlJob, apierrors.ApiError)
+	Subscribe(ctx context.Context, jobID string) (models.EtlJob, <-chan models.EtlJobEvent, func(), apierrors.ApiError)
+	Cancel(ctx context.Context, jobID string) (models.EtlJob, apierrors.ApiError)
+}
+
+type etlJobRequest struct {
+	ctx context.Context
+	job models.EtlJob
+}
+
+type etlJobsService struct {
+	repository  repositories.EtlJobsRepository
+	locks       repositories.EtlLocksRepository
+	etlService  EtlService
+	shopsClient clients.ShopClient
+	queue       chan etlJobRequest
+	events      *etlJobEventBroker
+	runningMu   sync.Mutex
+	running     map[string]context.CancelFunc
+	// held are the jobs queued or running on this replica, guarded by runningMu
+	held map[string]struct{}
+}
+
+// NewEtlJobsService creates the jobs service and starts its worker pool. Queued jobs only live in memory, the jobs
+// lost by a replica that stopped are failed once their heartbeat goes stale.
+func NewEtlJobsService(
+	repository repositories.EtlJobsRepository,
+	locks repositories.EtlLocksRepository,
+	etlService EtlService,
+	shopsClient clients.ShopClient,
+	workers int,
+	queueSize int,
+) EtlJobsService {
+	s := &etlJobsService{
+		repository:  repository,
+		locks:       locks,
+		etlService:  etlService,
+		shopsClient: shopsClient,
+		queue:       make(chan etlJobRequest, queueSize),
+		events:      newEtlJobEventBroker(),
+		running:     make(map[string]context.CancelFunc),
+		held:        make(map[string]struct{}),
+	}
+
+	s.failStale(context.Background())
+
+	for i := 0; i < workers; i++ {
+		go s.worker()
+	}
+	go s.heartbeat()
+
+	return s
+}
+
+// heartbeat keeps the jobs of this replica alive and fails the ones every other replica stopped renewing
+func (s *etlJobsService) heartbeat() {
+	ticker := time.NewTicker(etlJobHeartbeatEvery)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		ctx := context.Background()
+		if jobIDs := s.heldJobIDs(); len(jobIDs) > 0 {
+			if err := s.repository.Heartbeat(ctx, jobIDs); err != nil {
+				logger.Errorf("error renewing the heartbeat of the etl jobs", err)
+			}
+		}
+		s.failStale(ctx)
+	}
+}
+
+// failStale fails the queued and running jobs whose replica stopped, otherwise they stay unfinished forever and keep
+// their shop busy. Their shop locks expire on their own.
+func (s *etlJobsService) failStale(ctx context.Context) {
+	before := time.Now().UTC().Add(-etlJobStaleAfter)
+	if _, err := s.repository.FailStale(ctx, before, "etl job lost by a replica that stopped, please start it again"); err != nil {
+		logger.Errorf("error failing the stale etl jobs", err)
+	}
+}
+
+func (s *etlJobsService) heldJobIDs() []string {
+	s.runningMu.Lock()
+	defer s.runningMu.Unlock()
+
+	jobIDs := make([]string, 0, len(s.held))
+	for jobID := range s.held {
+		jobIDs = append(jobIDs, jobID)
+	}
+	return jobIDs
+}
+
+func (s *etlJobsService) setHeld(jobID string, held bool) {
+	s.runningMu.Lock()
+	defer s.runningMu.Unlock()
+
+	if held {
+		s.held[jobID] = struct{}{}
+	} else {
+		delete(s.held, jobID)
+	}
+}
+
+func (s *etlJobsService) EnqueueMercadoLibre(ctx context.Context, options models.EtlLoadOptions) (models.EtlJob, apierrors.ApiError) {
+	return s.enqueueForUser(ctx, models.EtlJobTypeMercadoLibre, options)
+}
+
+// EnqueueMercadoLibrePreview queues a run that stops before loading. The finished job has the counts of what a load
+// would do, the transformed items and their diff are downloaded as the preview artifact of the job's batch.
+func (s *etlJobsService) EnqueueMercadoLibrePreview(ctx context.Context, options models.EtlLoadOptions) (models.EtlJob, apierrors.ApiError) {
+	return s.enqueueForUser(ctx, models.EtlJobTypePreview, options)
+}
+
+// enqueueForUser queues a job for the shop of the requesting user
+func (s *etlJobsService) enqueueForUser(ctx context.Context, jobType string, options models.EtlLoadOptions) (models.EtlJob, apierrors.ApiError) {
+	shop, err := s.shopsClient.GetShopByUserID(ctx)
+	if err != nil {
+		return models.EtlJob{}, err
+	}
+
+	now := time.Now().UTC()
+	job := models.EtlJob{
+		ShopID:    shop.ID,
+		UserID:    fmt.Sprint(ctx.Value(goauth.FirebaseUserID)),
+		Type:      jobType,
+		Status:    models.EtlJobStatusQueued,
+		Options:   options,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	return s.enqueue(ctx, job)
+}
+
+// EnqueueScheduled queues the run of a schedule, the shop and user come from the schedule since
+// there is no user request behind it
+func (s *etlJobsService) EnqueueScheduled(ctx context.Context, schedule models.EtlSchedule) (models.EtlJob, apierrors.ApiError) {
+	now := time.Now().UTC()
+	job := models.EtlJob{
+		ShopID:     schedule.ShopID,
+		UserID:     schedule.UserID,
+		Type:       schedule.JobType(),
+		Status:     models.EtlJobStatusQueued,
+		Options:    schedule.Options,
+		ScheduleID: schedule.ID,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+
+	return s.enqueue(ctx, job)
+}
+
+func (s *etlJobsService) enqueue(ctx context.Context, job models.EtlJob) (models.EtlJob, apierrors.ApiError) {
+	job.HeartbeatAt = job.CreatedAt
+	jobID, err := s.repository.Create(ctx, job)
+	if err != nil {
+		return models.EtlJob{}, err
+	}
+	job.ID = jobID
+	s.setHeld(job.ID, true)
+
+	// The job outlives the HTTP request, keep its values but drop its cancellation
+	select {
+	case s.queue <- etlJobRequest{ctx: context.WithoutCancel(ctx), job: job}:
+	default:
+		s.setHeld(job.ID, false)
+		job.Status = models.EtlJobStatusFailed
+		job.Error = "etl job queue is full"
+		s.save(ctx, &job)
+		return models.EtlJob{}, apierrors.NewApiError("etl job queue is full, please try again later", "service_unavailable", http.StatusServiceUnavailable, apierrors.CauseList{})
+	}
+
+	return job, nil
+}
+
+func (s *etlJobsService) Get(ctx context.Context, jobID string) (models.EtlJob, apierrors.ApiError) {
+	job, err := s.repository.Get(ctx, jobID)
+	if err != nil {
+		return models.EtlJob{}, err
+	}
+
+	// Jobs are only visible to the user that started them
+	if job.UserID != fmt.Sprint(ctx.Value(goauth.FirebaseUserID)) {
+		return models.EtlJob{}, apierrors.NewApiError("etl job not found", "not_found", http.StatusNotFound, apierrors.CauseList{})
+	}
+
+	return job, nil
+}
+
+func (s *etlJobsService) GetByUserID(ctx context.Context) ([]models.EtlJob, apierrors.ApiError) {
+	return s.repository.GetByUserID(ctx, fmt.Sprint(ctx.Value(goauth.FirebaseUserID)))
+}
+
+// Subscribe returns the current state of the job and a channel with its live events.
+// Only events of jobs running on this replica are delivered, callers should poll Get as a fallback.
+func (s *etlJobsService) Subscribe(ctx context.Context, jobID string) (models.EtlJob, <-chan models.EtlJobEvent, func(), apierrors.ApiError) {
+	// Subscribe before reading the job so no event is lost in between
+	events, unsubscribe := s.events.subscribe(jobID)
+
+	job, err := s.Get(ctx, jobID)
+	if err != nil {
+		unsubscribe()
+		return models.EtlJob{}, nil, nil, err
+	}
+
+	return job, events, unsubscribe, nil
+}
+
+// Cancel stops a queued or running job. The flag is persisted so the replica running the job
+// stops it at its next stage, the local run (if any) is cancelled right away.
+func (s *etlJobsService) Cancel(ctx context.Context, jobID string) (models.EtlJob, apierrors.ApiError) {
+	job, err := s.Get(ctx, jobID)
+	if err != nil {
+		return models.EtlJob{}, err
+	}
+
+	if job.IsFinished() {
+		return models.EtlJob{}, apierrors.NewApiError(fmt.Sprintf("etl job already %s", job.Status), "conflict", http.StatusConflict, apierrors.CauseList{})
+	}
+
+	if err := s.repository.RequestCancel(ctx, jobID); err != nil {
+		return models.EtlJob{}, err
+	}
+	job.CancelRequested = true
+
+	s.runningMu.Lock()
+	if cancel, ok := s.running[jobID]; ok {
+		cancel()
+	}
+	s.runningMu.Unlock()
+
+	return job, nil
+}
+
+func (s *etlJobsService) worker() {
+	for request := range s.queue {
+		s.run(request.ctx, request.job)
+	}
+}
+
+func (s *etlJobsService) run(parent context.Context, job models.EtlJob) {
+	ctx, cancelCause := context.WithCancelCause(parent)
+	cancel := func() { cancelCause(nil) }
+	defer cancel()
+
+	s.runningMu.Lock()
+	s.running[job.ID] = cancel
+	s.runningMu.Unlock()
+
+	defer func() {
+		s.runningMu.Lock()
+		delete(s.running, job.ID)
+		delete(s.held, job.ID)
+		s.runningMu.Unlock()
+	}()
+
+	// Jobs persist the parent context, a cancelled one would make the final save fail
+	tracker := &jobProgressTracker{service: s, ctx: parent, job: &job, cancel: cancelCause}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tracker.finish(nil, apierrors.NewApiError(fmt.Sprintf("panic during etl job: %v", r), "internal_server_error", http.StatusInternalServerError, apierrors.CauseList{}))
+		}
+	}()
+
+	// The job could have been cancelled while waiting in the queue
+	if tracker.cancelRequested() {
+		tracker.finish(nil, cancelledError(ctx))
+		return
+	}
+
+	// Two replicas never import the same shop at the same time, the job id identifies the holder.
+	// Previews write no items, so they run alongside imports.
+	if job.Type != models.EtlJobTypePreview {
+		lockKey := etlShopLockKey(job.ShopID)
+		acquired, err := s.locks.Acquire(parent, lockKey, job.ID, etlShopLockTTL)
+		if err != nil {
+			tracker.finish(nil, err)
+			return
+		}
+		if !acquired {
+			tracker.finish(nil, apierrors.NewApiError("another etl job is already running for this shop", "conflict", http.StatusConflict, apierrors.CauseList{}))
+			return
+		}
+		defer s.locks.Release(parent, lockKey, job.ID)
+
+		stopRenewing := s.renewLock(parent, lockKey, job.ID, cancelCause)
+		defer stopRenewing()
+	}
+
+	tracker.start()
+
+	result, err := s.load(WithShopID(WithProgressReporter(withShopLockHeld(ctx), tracker), job.ShopID), job)
+	switch {
+	case err != nil && errors.Is(context.Cause(ctx), errEtlShopLockLost):
+		err = apierrors.NewApiError(errEtlShopLockLost.Error(), "conflict", http.StatusConflict, apierrors.CauseList{})
+	case err != nil && errors.Is(context.Cause(ctx), errEtlJobTakenAway):
+		err = apierrors.NewApiError(errEtlJobTakenAway.Error(), "conflict", http.StatusConflict, apierrors.CauseList{})
+	case err != nil && ctx.Err() != nil:
+		// A call interrupted by the cancellation fails with its own error, the job was still cancelled
+		err = cancelledError(ctx)
+	}
+
+	tracker.finish(result, err)
+}
+
+func (s *etlJobsService) load(ctx context.Context, job models.EtlJob) (*ETLResult, apierrors.ApiError) {
+	switch job.Type {
+	case models.EtlJobTypeApi:
+		return s.etlService.LoadApi(ctx)
+	case models.EtlJobTypePreview:
+		preview, err := s.etlService.PreviewMercadoLibre(ctx, job.Options)
+		if err != nil {
+			return nil, err
+		}
+		return previewResult(preview), nil
+	default:
+		return s.etlService.LoadMercadoLibre(ctx, job.Options)
+	}
+}
+
+// previewResult keeps what fits in the job record, the created and updated counts are what the load would do
+func previewResult(preview *ETLPreview) *ETLResult {
+	return &ETLResult{
+		BatchID:      preview.BatchID,
+		TotalItems:   preview.TotalItems,
+		CreatedCount: preview.CreateCount,
+		UpdatedCount: preview.UpdateCount,
+		FailureCount: preview.FailureCount,
+		SkippedCount: preview.SkippedCount,
+		FailedItems:  preview.FailedItems,
+		Warnings:     preview.Warnings,
+	}
+}
+
+// etlShopLockKey is the lock held by whatever is writing a shop's items, runs and rollbacks alike
+func etlShopLockKey(shopID string) string {
+	return fmt.Sprintf("shop:%s", shopID)
+}
+
+// renewLock keeps the lease of a running job alive until the returned func is called. A lease that cannot be renewed
+// cancels the run with errEtlShopLockLost, so it stops writing before another run takes the shop.
+func (s *etlJobsService) renewLock(ctx context.Context, key string, owner string, lost context.CancelCauseFunc) func() {
+	done := make(chan struct{})
+	ticker := time.NewTicker(etlShopLockTTL / 3)
+
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if acquired, err := s.locks.Acquire(ctx, key, owner, etlShopLockTTL); err != nil || !acquired {
+					lost(errEtlShopLockLost)
+					return
+				}
+			}
+		}
+	}()
+
+	return func() { close(done) }
+}
+
+// save writes the job record and reports whether the job is still queued or running in it. Only finished records
+// are never written again, any other failed write is ignored: progress persistence must not abort the run.
+func (s *etlJobsService) save(ctx context.Context, job *models.EtlJob) bool {
+	job.UpdatedAt = time.Now().UTC()
+	// The whole record is written, an older heartbeat would make the job look stale
+	job.HeartbeatAt = job.UpdatedAt
+	err := s.repository.Update(ctx, *job)
+	return err == nil || err.Status() != http.StatusNotFound
+}
+
+// jobProgressTracker is the ProgressReporter that keeps the persisted job record up to date
+type jobProgressTracker struct {
+	mu           sync.Mutex
+	service      *etlJobsService
+	ctx          context.Context
+	job          *models.EtlJob
+	cancel       context.CancelCauseFunc
+	unsaved      int
+	stageCurrent int
+	stageTotal   int
+}
+
+func (t *jobProgressTracker) start() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now().UTC()
+	t.job.Status = models.EtlJobStatusRunning
+	t.job.StartedAt = &now
+	t.save()
+}
+
+func (t *jobProgressTracker) StageStarted(stage string, total int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// Cancellations requested on another replica are picked up between stages
+	if t.cancelRequested() {
+		t.cancel(nil)
+	}
+
+	t.job.Stage = stage
+	if stage == models.EtlStageTransform {
+		t.job.TotalItems = total
+	}
+	t.stageCurrent = 0
+	t.stageTotal = total
+	t.unsaved = 0
+	t.save()
+
+	t.publish(models.EtlJobEvent{Type: models.EtlJobEventStage, Total: total})
+}
+
+func (t *jobProgressTracker) PageExtracted(page int, extracted int, total int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.publish(models.EtlJobEvent{Type: models.EtlJobEventPage, Page: page, Current: extracted, Total: total})
+}
+
+func (t *jobProgressTracker) ItemTransformed(externalID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.stageCurrent++
+	t.publishItem(externalID, models.EtlItemTransformed, nil)
+}
+
+func (t *jobProgressTracker) ItemSucceeded(externalID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.job.ProcessedItems++
+	if len(t.job.LoadedItems) < etlJobMaxListedItems {
+		t.job.LoadedItems = append(t.job.LoadedItems, externalID)
+	}
+	t.stageCurrent++
+	t.publishItem(externalID, models.EtlItemLoaded, nil)
+	t.flush()
+}
+
+func (t *jobProgressTracker) ItemFailed(item models.FailedItem) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.job.ProcessedItems++
+	t.job.FailureCount++
+	if len(t.job.FailedItems) < etlJobMaxListedItems {
+		t.job.FailedItems = append(t.job.FailedItems, item)
+	}
+	t.stageCurrent++
+	t.publishItem(item.ExternalID, models.EtlItemFailed, &item)
+	t.flush()
+}
+
+func (t *jobProgressTracker) publishItem(externalID string, status string, failure *models.FailedItem) {
+	t.publish(models.EtlJobEvent{
+		Type:       models.EtlJobEventItem,
+		Current:    t.stageCurrent,
+		Total:      t.stageTotal,
+		ExternalID: externalID,
+		ItemStatus: status,
+		Failure:    failure,
+	})
+}
+
+func (t *jobProgressTracker) publish(event models.EtlJobEvent) {
+	event.JobID = t.job.ID
+	event.Stage = t.job.Stage
+	event.Timestamp = time.Now().UTC()
+	t.service.events.publish(event)
+}
+
+func (t *jobProgressTracker) cancelRequested() bool {
+	current, err := t.service.repository.Get(t.ctx, t.job.ID)
+	return err == nil && current.CancelRequested
+}
+
+func (t *jobProgressTracker) flush() {
+	t.unsaved++
+	if t.unsaved >= etlJobProgressFlushEvery {
+		t.unsaved = 0
+		t.save()
+	}
+}
+
+// save persists the progress, stopping the run when its job record was finished elsewhere
+func (t *jobProgressTracker) save() {
+	if !t.service.save(t.ctx, t.job) {
+		t.cancel(errEtlJobTakenAway)
+	}
+}
+
+// firstListedItems keeps the part of a result list that fits in the job record
+func firstListedItems[T any](items []T) []T {
+	return items[:min(len(items), etlJobMaxListedItems)]
+}
+
+func (t *jobProgressTracker) finish(result *ETLResult, err apierrors.ApiError) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if result != nil {
+		t.job.BatchID = result.BatchID
+		t.job.TotalItems = result.TotalItems
+		t.job.CreatedCount = result.CreatedCount
+		t.job.UpdatedCount = result.UpdatedCount
+		t.job.FailureCount = result.FailureCount
+		t.job.SkippedCount = result.SkippedCount
+		t.job.FailedItems = firstListedItems(result.FailedItems)
+		t.job.Warnings = firstListedItems(result.Warnings)
+		t.job.LoadedItems = firstListedItems(result.LoadedItems)
+		t.job.ProcessedItems = len(result.LoadedItems) + result.FailureCount
+	}
+
+	switch {
+	case err != nil && err.Code() == EtlCancelledErrorCode:
+		t.job.Status = models.EtlJobStatusCancelled
+		t.job.CancelRequested = true
+	case err != nil:
+		t.job.Status = models.EtlJobStatusFailed
+		t.job.Error = err.Message()
+	default:
+		t.job.Status = models.EtlJobStatusCompleted
+	}
+
+	now := time.Now().UTC()
+	t.job.FinishedAt = &now
+	t.service.save(t.ctx, t.job)
+
+	job := *t.job
+	t.publish(models.EtlJobEvent{Type: models.EtlJobEventFinished, Job: &job})
+}
@@ -0,0 +1,50 @@
+package services
+
+import (
+	"context"
+
+	"github.com/jopitnow/go-jopit-toolkit/goutils/apierrors"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models"
+)
+
+const (
+	// Non standard status used by proxies when the client closes the request, used here for cancelled runs
+	StatusClientClosedRequest = 499
+	EtlCancelledErrorCode     = "cancelled"
+)
+
+type progressReporterKey struct{}
+
+// ProgressReporter receives progress notifications from a running ETL pipeline
+type ProgressReporter interface {
+	StageStarted(stage string, total int)
+	PageExtracted(page int, extracted int, total int)
+	ItemTransformed(externalID string)
+	ItemSucceeded(externalID string)
+	ItemFailed(item models.FailedItem)
+}
+
+// WithProgressReporter attaches a ProgressReporter to the context consumed by the ETL pipeline
+func WithProgressReporter(ctx context.Context, reporter ProgressReporter) context.Context {
+	return context.WithValue(ctx, progressReporterKey{}, reporter)
+}
+
+func progressFromContext(ctx context.Context) ProgressReporter {
+	if reporter, ok := ctx.Value(progressReporterKey{}).(ProgressReporter); ok && reporter != nil {
+		return reporter
+	}
+	return noopProgressReporter{}
+}
+
+type noopProgressReporter struct{}
+
+func (noopProgressReporter) StageStarted(stage string, total int)             {}
+func (noopProgressReporter) PageExtracted(page int, extracted int, total int) {}
+func (noopProgressReporter) ItemTransformed(externalID string)                {}
+func (noopProgressReporter) ItemSucceeded(externalID string)                  {}
+func (noopProgressReporter) ItemFailed(item models.FailedItem)                {}
+
+// cancelledError builds the error returned by pipeline steps interrupted by a cancelled context
+func cancelledError(ctx context.Context) apierrors.ApiError {
+	return apierrors.NewApiError("etl run cancelled", EtlCancelledErrorCode, StatusClientClosedRequest, apierrors.CauseList{context.Cause(ctx)})
+}
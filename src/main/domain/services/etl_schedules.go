@@ -0,0 +1,260 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"time"
+
+	"github.com/jopitnow/go-jopit-toolkit/goauth"
+	"github.com/jopitnow/go-jopit-toolkit/goutils/apierrors"
+	"github.com/jopitnow/go-jopit-toolkit/goutils/logger"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/clients"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models/dto"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/repositories"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/utils"
+)
+
+const (
+	// Shorter intervals would keep the shop busy importing and hit the source rate limits
+	etlScheduleMinInterval = 15
+	// Due schedules fetched per tick, the rest are picked up on the next ones
+	etlScheduleDueBatch = 100
+)
+
+type EtlSchedulesService interface {
+	Create(ctx context.Context, input dto.EtlScheduleRequest) (models.EtlSchedule, apierrors.ApiError)
+	Get(ctx context.Context, scheduleID string) (models.EtlSchedule, apierrors.ApiError)
+	GetByUserID(ctx context.Context) ([]models.EtlSchedule, apierrors.ApiError)
+	Update(ctx context.Context, scheduleID string, input dto.EtlScheduleRequest) (models.EtlSchedule, apierrors.ApiError)
+	Delete(ctx context.Context, scheduleID string) apierrors.ApiError
+}
+
+type etlSchedulesService struct {
+	repository   repositories.EtlSchedulesRepository
+	jobsService  EtlJobsService
+	shopsClient  clients.ShopClient
+	pollInterval time.Duration
+	maxJitter    time.Duration
+}
+
+// NewEtlSchedulesService creates the schedules service and starts the loop that triggers the due schedules.
+// Every replica runs the loop, each run is claimed by a single one.
+func NewEtlSchedulesService(
+	repository repositories.EtlSchedulesRepository,
+	jobsService EtlJobsService,
+	shopsClient clients.ShopClient,
+	pollInterval time.Duration,
+	maxJitter time.Duration,
+) EtlSchedulesService {
+	s := &etlSchedulesService{
+		repository:   repository,
+		jobsService:  jobsService,
+		shopsClient:  shopsClient,
+		pollInterval: pollInterval,
+		maxJitter:    maxJitter,
+	}
+
+	go s.loop()
+
+	return s
+}
+
+func (s *etlSchedulesService) Create(ctx context.Context, input dto.EtlScheduleRequest) (models.EtlSchedule, apierrors.ApiError) {
+	shop, err := s.shopsClient.GetShopByUserID(ctx)
+	if err != nil {
+		return models.EtlSchedule{}, err
+	}
+
+	schedules, err := s.GetByUserID(ctx)
+	if err != nil {
+		return models.EtlSchedule{}, err
+	}
+
+	// One schedule per source, a second one would only run into the shop lock
+	for _, existing := range schedules {
+		if existing.ShopID == shop.ID && existing.Source == input.Source {
+			return models.EtlSchedule{}, apierrors.NewApiError(fmt.Sprintf("the shop already has a %s schedule", input.Source), "conflict", http.StatusConflict, apierrors.CauseList{existing.ID})
+		}
+	}
+
+	now := time.Now().UTC()
+	schedule := input.ToModel()
+	schedule.ShopID = shop.ID
+	schedule.UserID = fmt.Sprint(ctx.Value(goauth.FirebaseUserID))
+	schedule.CreatedAt = now
+	schedule.UpdatedAt = now
+
+	if err := s.scheduleNextRun(&schedule, now); err != nil {
+		return models.EtlSchedule{}, err
+	}
+
+	scheduleID, err := s.repository.Create(ctx, schedule)
+	if err != nil {
+		return models.EtlSchedule{}, err
+	}
+	schedule.ID = scheduleID
+
+	return schedule, nil
+}
+
+func (s *etlSchedulesService) Get(ctx context.Context, scheduleID string) (models.EtlSchedule, apierrors.ApiError) {
+	schedule, err := s.repository.Get(ctx, scheduleID)
+	if err != nil {
+		return models.EtlSchedule{}, err
+	}
+
+	// Schedules are only visible to the user that created them
+	if schedule.UserID != fmt.Sprint(ctx.Value(goauth.FirebaseUserID)) {
+		return models.EtlSchedule{}, apierrors.NewApiError("etl schedule not found", "not_found", http.StatusNotFound, apierrors.CauseList{})
+	}
+
+	return schedule, nil
+}
+
+func (s *etlSchedulesService) GetByUserID(ctx context.Context) ([]models.EtlSchedule, apierrors.ApiError) {
+	return s.repository.GetByUserID(ctx, fmt.Sprint(ctx.Value(goauth.FirebaseUserID)))
+}
+
+func (s *etlSchedulesService) Update(ctx context.Context, scheduleID string, input dto.EtlScheduleRequest) (models.EtlSchedule, apierrors.ApiError) {
+	schedule, err := s.Get(ctx, scheduleID)
+	if err != nil {
+		return models.EtlSchedule{}, err
+	}
+
+	if input.Source != schedule.Source {
+		return models.EtlSchedule{}, apierrors.NewApiError("the source of a schedule cannot be changed", "bad_request", http.StatusBadRequest, apierrors.CauseList{})
+	}
+
+	updated := input.ToModel()
+
+	now := time.Now().UTC()
+	schedule.Cron = updated.Cron
+	schedule.IntervalMinutes = updated.IntervalMinutes
+	schedule.Options = updated.Options
+	schedule.Enabled = updated.Enabled
+	schedule.UpdatedAt = now
+
+	if err := s.scheduleNextRun(&schedule, now); err != nil {
+		return models.EtlSchedule{}, err
+	}
+
+	if err := s.repository.Update(ctx, schedule); err != nil {
+		return models.EtlSchedule{}, err
+	}
+
+	return schedule, nil
+}
+
+func (s *etlSchedulesService) Delete(ctx context.Context, scheduleID string) apierrors.ApiError {
+	if _, err := s.Get(ctx, scheduleID); err != nil {
+		return err
+	}
+
+	return s.repository.Delete(ctx, scheduleID)
+}
+
+// scheduleNextRun validates the schedule timing and sets its next run
+func (s *etlSchedulesService) scheduleNextRun(schedule *models.EtlSchedule, now time.Time) apierrors.ApiError {
+	if (schedule.Cron == "") == (schedule.IntervalMinutes == 0) {
+		return apierrors.NewApiError("exactly one of cron or interval_minutes is required", "bad_request", http.StatusBadRequest, apierrors.CauseList{})
+	}
+
+	gap, err := utils.ScheduleMinGap(schedule.Cron, schedule.IntervalMinutes)
+	if err != nil {
+		return apierrors.NewApiError(err.Error(), "bad_request", http.StatusBadRequest, apierrors.CauseList{})
+	}
+
+	if gap < etlScheduleMinInterval*time.Minute {
+		return apierrors.NewApiError(fmt.Sprintf("runs must be at least %d minutes apart", etlScheduleMinInterval), "bad_request", http.StatusBadRequest, apierrors.CauseList{})
+	}
+
+	nextRunAt, err := s.nextRun(*schedule, now)
+	if err != nil {
+		return apierrors.NewApiError(err.Error(), "bad_request", http.StatusBadRequest, apierrors.CauseList{})
+	}
+
+	schedule.NextRunAt = nextRunAt
+
+	return nil
+}
+
+// nextRun adds a random jitter to the schedule's next time so shops sharing a schedule do not all start at once.
+// The jitter stays under half the gap between runs, so a delayed run never passes the following one.
+func (s *etlSchedulesService) nextRun(schedule models.EtlSchedule, after time.Time) (time.Time, error) {
+	next, err := utils.NextScheduleRun(schedule.Cron, schedule.IntervalMinutes, after)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	gap, err := utils.ScheduleMinGap(schedule.Cron, schedule.IntervalMinutes)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if jitter := min(s.maxJitter, gap/2); jitter > 0 {
+		next = next.Add(rand.N(jitter))
+	}
+
+	// Mongo keeps milliseconds, truncating keeps the stored value equal to the one used to claim the run
+	return next.Truncate(time.Millisecond), nil
+}
+
+func (s *etlSchedulesService) loop() {
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		s.triggerDue(context.Background())
+	}
+}
+
+func (s *etlSchedulesService) triggerDue(ctx context.Context) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Errorf("panic triggering etl schedules", fmt.Errorf("%v", r))
+		}
+	}()
+
+	now := time.Now().UTC()
+
+	due, err := s.repository.GetDue(ctx, now, etlScheduleDueBatch)
+	if err != nil {
+		logger.Errorf("error getting due etl schedules", err)
+		return
+	}
+
+	for _, schedule := range due {
+		s.trigger(ctx, schedule, now)
+	}
+}
+
+func (s *etlSchedulesService) trigger(ctx context.Context, schedule models.EtlSchedule, now time.Time) {
+	// Intervals count from now, a schedule that was down for a while runs once instead of catching up
+	nextRunAt, nextErr := s.nextRun(schedule, now)
+	if nextErr != nil {
+		// The timing was validated on write, disable the schedule instead of retrying it every tick
+		schedule.Enabled = false
+		schedule.LastError = nextErr.Error()
+		_ = s.repository.Update(ctx, schedule)
+		return
+	}
+
+	claimed, err := s.repository.ClaimRun(ctx, schedule, nextRunAt, now)
+	if err != nil || !claimed {
+		return
+	}
+
+	// The run is made on behalf of the schedule owner
+	jobCtx := context.WithValue(ctx, goauth.FirebaseUserID, schedule.UserID)
+
+	runError := ""
+	job, err := s.jobsService.EnqueueScheduled(jobCtx, schedule)
+	if err != nil {
+		runError = err.Message()
+	}
+
+	_ = s.repository.RecordRun(ctx, schedule.ID, job.ID, runError)
+}
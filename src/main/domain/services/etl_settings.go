@@ -0,0 +1,76 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/jopitnow/go-jopit-toolkit/goauth"
+	"github.com/jopitnow/go-jopit-toolkit/goutils/apierrors"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/clients"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models/dto"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/repositories"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/utils"
+)
+
+type EtlSettingsService interface {
+	Get(ctx context.Context) (models.EtlSettings, apierrors.ApiError)
+	Update(ctx context.Context, input dto.EtlSettingsRequest) (models.EtlSettings, apierrors.ApiError)
+	GetByShopID(ctx context.Context, shopID string) (models.EtlSettings, apierrors.ApiError)
+}
+
+type etlSettingsService struct {
+	repository  repositories.EtlSettingsRepository
+	shopsClient clients.ShopClient
+}
+
+func NewEtlSettingsService(repository repositories.EtlSettingsRepository, shopsClient clients.ShopClient) EtlSettingsService {
+	return &etlSettingsService{
+		repository:  repository,
+		shopsClient: shopsClient,
+	}
+}
+
+// Get returns the settings of the user's shop, empty settings when it never saved any
+func (s *etlSettingsService) Get(ctx context.Context) (models.EtlSettings, apierrors.ApiError) {
+	shop, err := s.shopsClient.GetShopByUserID(ctx)
+	if err != nil {
+		return models.EtlSettings{}, err
+	}
+
+	return s.GetByShopID(ctx, shop.ID)
+}
+
+func (s *etlSettingsService) Update(ctx context.Context, input dto.EtlSettingsRequest) (models.EtlSettings, apierrors.ApiError) {
+	shop, err := s.shopsClient.GetShopByUserID(ctx)
+	if err != nil {
+		return models.EtlSettings{}, err
+	}
+
+	settings := input.ToModel()
+	if settings.BaseCurrency != "" && !utils.IsKnownCurrency(settings.BaseCurrency) {
+		return models.EtlSettings{}, apierrors.NewApiError(fmt.Sprintf("base currency %s is not supported", settings.BaseCurrency), "bad_request", http.StatusBadRequest, apierrors.CauseList{})
+	}
+
+	settings.ShopID = shop.ID
+	settings.UserID = fmt.Sprint(ctx.Value(goauth.FirebaseUserID))
+	settings.UpdatedAt = time.Now().UTC()
+
+	if err := s.repository.Save(ctx, settings); err != nil {
+		return models.EtlSettings{}, err
+	}
+
+	return settings, nil
+}
+
+// GetByShopID returns the settings used by the runs of a shop, empty settings when it never saved any
+func (s *etlSettingsService) GetByShopID(ctx context.Context, shopID string) (models.EtlSettings, apierrors.ApiError) {
+	settings, err := s.repository.GetByShopID(ctx, shopID)
+	if err != nil && err.Status() == http.StatusNotFound {
+		return models.EtlSettings{ShopID: shopID}, nil
+	}
+
+	return settings, err
+}
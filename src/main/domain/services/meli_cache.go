@@ -0,0 +1,51 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/jopitnow/go-jopit-toolkit/goutils/apierrors"
+	"github.com/jopitnow/go-jopit-toolkit/goutils/logger"
+)
+
+// meliCache reads MercadoLibre resources through a Mongo cache. Entries are refreshed from MercadoLibre once
+// they are older than ttl, a stale entry is still returned when MercadoLibre can't be reached.
+type meliCache[T any] struct {
+	// kind names the cached resource in logs
+	kind string
+	ttl  time.Duration
+	// get returns the cached entry and when it was fetched, a not found error when there is none
+	get  func(ctx context.Context, id string) (T, time.Time, apierrors.ApiError)
+	save func(ctx context.Context, id string, value T, fetchedAt time.Time) apierrors.ApiError
+}
+
+func (c meliCache[T]) read(ctx context.Context, id string, fetch func(ctx context.Context) (T, apierrors.ApiError)) (T, apierrors.ApiError) {
+	var zero T
+
+	cached, fetchedAt, err := c.get(ctx, id)
+	if err != nil && err.Status() != http.StatusNotFound {
+		return zero, err
+	}
+	found := err == nil
+
+	if found && time.Since(fetchedAt) < c.ttl {
+		return cached, nil
+	}
+
+	value, err := fetch(ctx)
+	if err != nil {
+		if found {
+			return cached, nil
+		}
+		return zero, err
+	}
+
+	// The cache only saves calls, a failed write doesn't fail the read
+	if err := c.save(ctx, id, value, time.Now().UTC()); err != nil {
+		logger.Errorf(fmt.Sprintf("error caching MercadoLibre %s %s", c.kind, id), err)
+	}
+
+	return value, nil
+}
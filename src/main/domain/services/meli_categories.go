@@ -0,0 +1,60 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/jopitnow/go-jopit-toolkit/goutils/apierrors"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/clients"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/repositories"
+)
+
+// MeliCategoriesService reads MercadoLibre categories through a Mongo cache, categories change rarely
+type MeliCategoriesService interface {
+	GetCategory(ctx context.Context, categoryID string) (models.MeliCategory, apierrors.ApiError)
+}
+
+type meliCategoriesService struct {
+	meliClient clients.MercadoLibreClient
+	cache      meliCache[models.MeliCategory]
+}
+
+func NewMeliCategoriesService(repository repositories.MeliCategoriesRepository, meliClient clients.MercadoLibreClient, ttl time.Duration) MeliCategoriesService {
+	return &meliCategoriesService{
+		meliClient: meliClient,
+		cache: meliCache[models.MeliCategory]{
+			kind: "category",
+			ttl:  ttl,
+			get: func(ctx context.Context, categoryID string) (models.MeliCategory, time.Time, apierrors.ApiError) {
+				category, err := repository.Get(ctx, categoryID)
+				return category, category.FetchedAt, err
+			},
+			save: func(ctx context.Context, categoryID string, category models.MeliCategory, fetchedAt time.Time) apierrors.ApiError {
+				category.FetchedAt = fetchedAt
+				return repository.Save(ctx, category)
+			},
+		},
+	}
+}
+
+// GetCategory returns the category with its path from the root, read through the cache
+func (s *meliCategoriesService) GetCategory(ctx context.Context, categoryID string) (models.MeliCategory, apierrors.ApiError) {
+	return s.cache.read(ctx, categoryID, func(ctx context.Context) (models.MeliCategory, apierrors.ApiError) {
+		response, err := s.meliClient.GetCategory(ctx, categoryID)
+		if err != nil {
+			return models.MeliCategory{}, err
+		}
+
+		category := models.MeliCategory{
+			ID:           categoryID,
+			Name:         response.Name,
+			PathFromRoot: make([]models.MeliCategoryNode, 0, len(response.PathFromRoot)),
+		}
+		for _, node := range response.PathFromRoot {
+			category.PathFromRoot = append(category.PathFromRoot, models.MeliCategoryNode{ID: node.ID, Name: node.Name})
+		}
+
+		return category, nil
+	})
+}
@@ -0,0 +1,182 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jopitnow/go-jopit-toolkit/goauth"
+	"github.com/jopitnow/go-jopit-toolkit/goutils/apierrors"
+	"github.com/jopitnow/go-jopit-toolkit/goutils/logger"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models/dto"
+)
+
+const (
+	MeliNotificationTopicItems = "items"
+	meliItemsResourcePrefix    = "/items/"
+	meliNotificationRetryDelay = 30 * time.Second
+	// meliNotificationMaxAttempts caps how many times an item waiting on a busy shop is tried, about 5 minutes
+	meliNotificationMaxAttempts = 10
+)
+
+type MercadoLibreNotificationsService interface {
+	Receive(ctx context.Context, notification dto.MeliNotification) apierrors.ApiError
+}
+
+// meliNotificationRequest only keeps ids, credentials are resolved when the item is processed so a request
+// that waited in the queue or for a busy shop never syncs with an expired token
+type meliNotificationRequest struct {
+	meliUserID int64
+	meliItemID string
+	attempts   int
+}
+
+type mercadoLibreNotificationsService struct {
+	credentialsService MercadoLibreCredentialsService
+	etlService         EtlService
+	applicationID      string
+	queue              chan meliNotificationRequest
+	pendingMu          sync.Mutex
+	pending            map[string]struct{}
+}
+
+// NewMercadoLibreNotificationsService creates the notifications service and starts its worker pool
+func NewMercadoLibreNotificationsService(
+	credentialsService MercadoLibreCredentialsService,
+	etlService EtlService,
+	applicationID string,
+	workers int,
+	queueSize int,
+) MercadoLibreNotificationsService {
+	s := &mercadoLibreNotificationsService{
+		credentialsService: credentialsService,
+		etlService:         etlService,
+		applicationID:      applicationID,
+		queue:              make(chan meliNotificationRequest, queueSize),
+		pending:            make(map[string]struct{}),
+	}
+
+	for i := 0; i < workers; i++ {
+		go s.worker()
+	}
+
+	return s
+}
+
+// Receive validates a MercadoLibre notification and queues the changed item for re-import.
+// MercadoLibre expects a fast answer, the item itself is processed by the workers.
+func (s *mercadoLibreNotificationsService) Receive(ctx context.Context, notification dto.MeliNotification) apierrors.ApiError {
+	if s.applicationID != "" && fmt.Sprint(notification.ApplicationID) != s.applicationID {
+		return apierrors.NewApiError("notification does not belong to this application", "forbidden", http.StatusForbidden, apierrors.CauseList{})
+	}
+
+	// Other topics (orders, questions, ...) are acknowledged but not handled by the ETL
+	if notification.Topic != MeliNotificationTopicItems {
+		return nil
+	}
+
+	meliItemID, ok := parseMeliItemResource(notification.Resource)
+	if !ok {
+		return apierrors.NewApiError(fmt.Sprintf("invalid items resource %q", notification.Resource), "bad_request", http.StatusBadRequest, apierrors.CauseList{})
+	}
+
+	// Only sellers that linked their account can trigger an import. Notifications of sellers that unlinked it
+	// are acknowledged, MercadoLibre would otherwise keep sending them.
+	_, err := s.credentialsService.GetCredentialsByMeliUserID(ctx, notification.UserID)
+	if err != nil && err.Status() == http.StatusNotFound {
+		logger.Errorf(fmt.Sprintf("ignoring notification of item %s from unknown MercadoLibre user %d", meliItemID, notification.UserID), err)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return s.enqueue(meliNotificationRequest{meliUserID: notification.UserID, meliItemID: meliItemID})
+}
+
+// enqueue hands the item to the workers. MercadoLibre usually sends several notifications per change, so each
+// item is queued only once.
+func (s *mercadoLibreNotificationsService) enqueue(request meliNotificationRequest) apierrors.ApiError {
+	s.pendingMu.Lock()
+	defer s.pendingMu.Unlock()
+
+	if _, queued := s.pending[request.meliItemID]; queued {
+		return nil
+	}
+
+	select {
+	case s.queue <- request:
+		s.pending[request.meliItemID] = struct{}{}
+	default:
+		return apierrors.NewApiError("notifications queue is full, please try again later", "service_unavailable", http.StatusServiceUnavailable, apierrors.CauseList{})
+	}
+
+	return nil
+}
+
+func (s *mercadoLibreNotificationsService) worker() {
+	for request := range s.queue {
+		s.process(request)
+	}
+}
+
+func (s *mercadoLibreNotificationsService) process(request meliNotificationRequest) {
+	// Drop the item from the pending set first, a change arriving while it is processed queues it again
+	s.pendingMu.Lock()
+	delete(s.pending, request.meliItemID)
+	s.pendingMu.Unlock()
+
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Errorf(fmt.Sprintf("panic syncing MercadoLibre item %s", request.meliItemID), fmt.Errorf("%v", r))
+		}
+	}()
+
+	err := s.sync(request)
+	if err == nil {
+		return
+	}
+
+	// A load or job is writing the shop's items, sync the item once it is done rather than race it
+	request.attempts++
+	if err.Status() == http.StatusConflict && request.attempts < meliNotificationMaxAttempts {
+		time.AfterFunc(meliNotificationRetryDelay, func() {
+			if err := s.enqueue(request); err != nil {
+				logger.Errorf(fmt.Sprintf("error requeueing MercadoLibre item %s", request.meliItemID), err)
+			}
+		})
+		return
+	}
+
+	logger.Errorf(fmt.Sprintf("error syncing MercadoLibre item %s after %d attempts", request.meliItemID, request.attempts), err)
+}
+
+// sync re-imports the item with the seller's credentials, refreshed if their token is about to expire
+func (s *mercadoLibreNotificationsService) sync(request meliNotificationRequest) apierrors.ApiError {
+	credentials, err := s.credentialsService.GetCredentialsByMeliUserID(context.Background(), request.meliUserID)
+	if err != nil {
+		return err
+	}
+
+	// MercadoLibre calls are made on behalf of the seller that owns the item
+	ctx := context.WithValue(context.Background(), goauth.FirebaseUserID, credentials.UserID)
+
+	return s.etlService.SyncMercadoLibreItem(ctx, credentials, request.meliItemID)
+}
+
+// parseMeliItemResource extracts the item id from resources like /items/MLA123456
+func parseMeliItemResource(resource string) (string, bool) {
+	if !strings.HasPrefix(resource, meliItemsResourcePrefix) {
+		return "", false
+	}
+
+	meliItemID := strings.SplitN(strings.TrimPrefix(resource, meliItemsResourcePrefix), "/", 2)[0]
+	if meliItemID == "" {
+		return "", false
+	}
+
+	return meliItemID, true
+}
@@ -0,0 +1,80 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/jopitnow/go-jopit-toolkit/goutils/apierrors"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/clients"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models/dto"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/repositories"
+)
+
+// MeliSizeChartsService reads MercadoLibre size charts through a Mongo cache, so runs don't fetch the same
+// charts over and over
+type MeliSizeChartsService interface {
+	GetSizeChart(ctx context.Context, chartID string, accessToken string) (dto.MeliSizeChartResponse, apierrors.ApiError)
+}
+
+type meliSizeChartsService struct {
+	meliClient clients.MercadoLibreClient
+	cache      meliCache[dto.MeliSizeChartResponse]
+}
+
+func NewMeliSizeChartsService(repository repositories.MeliSizeChartsRepository, meliClient clients.MercadoLibreClient, ttl time.Duration) MeliSizeChartsService {
+	return &meliSizeChartsService{
+		meliClient: meliClient,
+		cache: meliCache[dto.MeliSizeChartResponse]{
+			kind: "size chart",
+			ttl:  ttl,
+			get: func(ctx context.Context, chartID string) (dto.MeliSizeChartResponse, time.Time, apierrors.ApiError) {
+				cached, err := repository.Get(ctx, chartID)
+				return cached.Chart, cached.FetchedAt, err
+			},
+			save: func(ctx context.Context, chartID string, chart dto.MeliSizeChartResponse, fetchedAt time.Time) apierrors.ApiError {
+				return repository.Save(ctx, dto.MeliCachedSizeChart{ID: chartID, Chart: chart, FetchedAt: fetchedAt})
+			},
+		},
+	}
+}
+
+// GetSizeChart returns the chart read through the cache
+func (s *meliSizeChartsService) GetSizeChart(ctx context.Context, chartID string, accessToken string) (dto.MeliSizeChartResponse, apierrors.ApiError) {
+	return s.cache.read(ctx, chartID, func(ctx context.Context) (dto.MeliSizeChartResponse, apierrors.ApiError) {
+		return s.meliClient.GetSizeChart(ctx, chartID, accessToken)
+	})
+}
+
+// runSizeCharts memoizes the size charts of one run. Charts that could not be fetched are remembered too, so a
+// failing chart is only asked for once.
+type runSizeCharts struct {
+	service     MeliSizeChartsService
+	accessToken string
+	charts      map[string]*dto.MeliSizeChartResponse
+}
+
+func newRunSizeCharts(service MeliSizeChartsService, accessToken string) *runSizeCharts {
+	return &runSizeCharts{
+		service:     service,
+		accessToken: accessToken,
+		charts:      map[string]*dto.MeliSizeChartResponse{},
+	}
+}
+
+// get returns the chart, nil when it can't be fetched
+func (r *runSizeCharts) get(ctx context.Context, chartID string) *dto.MeliSizeChartResponse {
+	if chart, ok := r.charts[chartID]; ok {
+		return chart
+	}
+
+	var chart *dto.MeliSizeChartResponse
+	if response, err := r.service.GetSizeChart(ctx, chartID, r.accessToken); err == nil {
+		chart = &response
+	}
+
+	// A cancelled call says nothing about the chart, don't remember it
+	if ctx.Err() == nil {
+		r.charts[chartID] = chart
+	}
+	return chart
+}
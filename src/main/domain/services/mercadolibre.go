@@ -4,35 +4,54 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"sync"
 
 	"github.com/jopitnow/go-jopit-toolkit/goauth"
 	"github.com/jopitnow/go-jopit-toolkit/goutils/apierrors"
 	"github.com/jopitnow/jopit-api-etl/src/main/domain/clients"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models"
 	"github.com/jopitnow/jopit-api-etl/src/main/domain/models/dto"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/utils"
+)
+
+const (
+	// meliSearchOffsetLimit is the most results MercadoLibre serves through offset paging
+	meliSearchOffsetLimit = 1000
+	// meliScanPageSize is the page size of scan searches, MercadoLibre's maximum
+	meliScanPageSize = 100
 )
 
 type MercadoLibreService interface {
 	GetItem(ctx context.Context, meliItemID string) (dto.MeliItemResponse, apierrors.ApiError)
+	GetItemWithCredentials(ctx context.Context, credentials models.MercadoLibreCredential, meliItemID string) (dto.MeliItemResponse, apierrors.ApiError)
 	GetItems(ctx context.Context, meliItemIDs []string) ([]dto.MeliItemResponse, apierrors.ApiError)
 	GetUserItems(ctx context.Context) (dto.MeliUserItemsSearchResponse, apierrors.ApiError)
 	SearchItemsBySeller(ctx context.Context, siteID string) (dto.MeliUserItemsSearchResponse, apierrors.ApiError)
-	GetSizeChart(ctx context.Context, chartID string) (dto.MeliSizeChartResponse, apierrors.ApiError)
+	GetCredentials(ctx context.Context) (models.MercadoLibreCredential, apierrors.ApiError)
+	RenewCredentials(ctx context.Context, credentials models.MercadoLibreCredential) (models.MercadoLibreCredential, apierrors.ApiError)
 	GetUserItemsDetails(ctx context.Context) ([]dto.MeliItemResponse, apierrors.ApiError)
-	GetUserItemsDetailsWithPagination(ctx context.Context, pageSize int) ([]dto.MeliItemResponse, apierrors.ApiError)
+	GetUserItemsDetailsWithPagination(ctx context.Context, credentials models.MercadoLibreCredential, pageSize int) ([]dto.MeliItemResponse, []models.FailedItem, apierrors.ApiError)
+	AttachItemsDetails(ctx context.Context, credentials models.MercadoLibreCredential, items []dto.MeliItemResponse) apierrors.ApiError
 }
 
 type mercadoLibreService struct {
 	meliClient         clients.MercadoLibreClient
 	credentialsService MercadoLibreCredentialsService
+	descriptionWorkers int
+	multigetWorkers    int
 }
 
 func NewMercadoLibreService(
 	meliClient clients.MercadoLibreClient,
 	credentialsService MercadoLibreCredentialsService,
+	descriptionWorkers int,
+	multigetWorkers int,
 ) MercadoLibreService {
 	return &mercadoLibreService{
 		meliClient:         meliClient,
 		credentialsService: credentialsService,
+		descriptionWorkers: max(descriptionWorkers, 1),
+		multigetWorkers:    max(multigetWorkers, 1),
 	}
 }
 
@@ -45,12 +64,19 @@ func (s *mercadoLibreService) GetItem(ctx context.Context, meliItemID string) (d
 		return dto.MeliItemResponse{}, err
 	}
 
+	return s.GetItemWithCredentials(ctx, credentials, meliItemID)
+}
+
+// GetItemWithCredentials fetches an item with its description and sale prices using credentials already resolved
+func (s *mercadoLibreService) GetItemWithCredentials(ctx context.Context, credentials models.MercadoLibreCredential, meliItemID string) (dto.MeliItemResponse, apierrors.ApiError) {
 	// Call MercadoLibre API
 	item, err := s.meliClient.GetItem(ctx, meliItemID, credentials.AccessToken)
 	if err != nil {
 		return dto.MeliItemResponse{}, err
 	}
 
+	s.attachItemDetails(ctx, &item, credentials.AccessToken)
+
 	return item, nil
 }
 
@@ -64,7 +90,7 @@ func (s *mercadoLibreService) GetItems(ctx context.Context, meliItemIDs []string
 	}
 
 	// Call MercadoLibre API
-	items, err := s.meliClient.GetItems(ctx, meliItemIDs, credentials.AccessToken)
+	items, _, err := s.fetchItems(ctx, meliItemIDs, credentials.AccessToken)
 	if err != nil {
 		return nil, err
 	}
@@ -141,36 +167,167 @@ func (s *mercadoLibreService) GetUserItemsDetails(ctx context.Context) ([]dto.Me
 	}
 
 	// Step 2: Batch fetch full details for all items using MercadoLibre's multi-get endpoint
-	itemsDetails, err := s.meliClient.GetItems(ctx, searchResult.Results, credentials.AccessToken)
+	itemsDetails, _, err := s.fetchItems(ctx, searchResult.Results, credentials.AccessToken)
 	if err != nil {
 		return []dto.MeliItemResponse{}, err
 	}
 
+	// Step 3: Descriptions and sale prices are only served one item at a time
+	s.attachDetails(ctx, itemsDetails, credentials.AccessToken)
+
 	return itemsDetails, nil
 
 }
 
-func (s *mercadoLibreService) GetUserItemsDetailsWithPagination(ctx context.Context, pageSize int) ([]dto.MeliItemResponse, apierrors.ApiError) {
+// GetCredentials returns the credentials of the user in the context, refreshing the token when it expired.
+// Runs resolve them once and pass them to every call, renewing them through RenewCredentials.
+func (s *mercadoLibreService) GetCredentials(ctx context.Context) (models.MercadoLibreCredential, apierrors.ApiError) {
 	userID := fmt.Sprint(ctx.Value(goauth.FirebaseUserID))
+	return s.credentialsService.GetCredentialsByUserID(ctx, userID)
+}
 
-	// Get credentials with auto-refresh
-	credentials, err := s.credentialsService.GetCredentialsByUserID(ctx, userID)
-	if err != nil {
-		return []dto.MeliItemResponse{}, err
+// RenewCredentials returns the credentials of a run as they are, or resolved again when their token is about to
+// expire. Runs call it between stages, so a long run never reaches MercadoLibre with an expired token.
+func (s *mercadoLibreService) RenewCredentials(ctx context.Context, credentials models.MercadoLibreCredential) (models.MercadoLibreCredential, apierrors.ApiError) {
+	if !credentials.NeedsRefresh() {
+		return credentials, nil
 	}
 
+	return s.credentialsService.GetCredentialsByUserID(ctx, credentials.UserID)
+}
+
+// GetUserItemsDetailsWithPagination fetches every item of the seller. Items MercadoLibre could not return are
+// reported as extract failures rather than failing the whole extraction. Descriptions and sale prices are not
+// attached, callers pick the items worth the extra calls and pass them to AttachItemsDetails.
+func (s *mercadoLibreService) GetUserItemsDetailsWithPagination(ctx context.Context, credentials models.MercadoLibreCredential, pageSize int) ([]dto.MeliItemResponse, []models.FailedItem, apierrors.ApiError) {
 	if credentials.UserIDMeli == 0 {
-		return []dto.MeliItemResponse{}, apierrors.NewApiError("seller_id not found in credentials", "bad_request", http.StatusBadRequest, apierrors.CauseList{})
+		return []dto.MeliItemResponse{}, nil, apierrors.NewApiError("seller_id not found in credentials", "bad_request", http.StatusBadRequest, apierrors.CauseList{})
 	}
 
 	// Step 1: Fetch all item IDs with pagination
+	allItemIDs, err := s.searchUserItemIDs(ctx, credentials, pageSize)
+	if err != nil {
+		return []dto.MeliItemResponse{}, nil, err
+	}
+
+	if len(allItemIDs) == 0 {
+		return []dto.MeliItemResponse{}, nil, nil
+	}
+
+	// Step 2: Batch fetch full details for all items using MercadoLibre's multi-get endpoint
+	itemsDetails, failedItems, err := s.fetchItems(ctx, allItemIDs, credentials.AccessToken)
+	if err != nil {
+		return []dto.MeliItemResponse{}, nil, err
+	}
+
+	return itemsDetails, failedItems, nil
+}
+
+// AttachItemsDetails fetches the descriptions and sale prices of the items in place, they are only served one
+// item at a time. Only a cancelled context fails the call.
+func (s *mercadoLibreService) AttachItemsDetails(ctx context.Context, credentials models.MercadoLibreCredential, items []dto.MeliItemResponse) apierrors.ApiError {
+	s.attachDetails(ctx, items, credentials.AccessToken)
+	if ctx.Err() != nil {
+		return cancelledError(ctx)
+	}
+
+	return nil
+}
+
+// fetchItems gets the details of the items through the multiget endpoint, in chunks of MeliMultigetMaxIDs with at
+// most multigetWorkers calls in flight. Ids MercadoLibre did not return an item for, or whose chunk failed, come
+// back as extract failures. The call only fails when it is cancelled or every chunk failed.
+func (s *mercadoLibreService) fetchItems(ctx context.Context, meliItemIDs []string, accessToken string) ([]dto.MeliItemResponse, []models.FailedItem, apierrors.ApiError) {
+	chunks := make([][]string, 0, len(meliItemIDs)/clients.MeliMultigetMaxIDs+1)
+	for start := 0; start < len(meliItemIDs); start += clients.MeliMultigetMaxIDs {
+		chunks = append(chunks, meliItemIDs[start:min(start+clients.MeliMultigetMaxIDs, len(meliItemIDs))])
+	}
+
+	results := make([][]dto.MeliMultigetResult, len(chunks))
+	errs := make([]apierrors.ApiError, len(chunks))
+	slots := make(chan struct{}, s.multigetWorkers)
+	var wg sync.WaitGroup
+
+dispatch:
+	for i := range chunks {
+		select {
+		case <-ctx.Done():
+			break dispatch
+		case slots <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			results[i], errs[i] = s.meliClient.GetItems(ctx, chunks[i], accessToken)
+		}(i)
+	}
+
+	wg.Wait()
+	if ctx.Err() != nil {
+		return nil, nil, cancelledError(ctx)
+	}
+
+	items := make([]dto.MeliItemResponse, 0, len(meliItemIDs))
+	failedItems := make([]models.FailedItem, 0)
+	failedChunks := 0
+
+	for i, chunk := range chunks {
+		if errs[i] != nil {
+			failedChunks++
+			for _, meliItemID := range chunk {
+				failedItems = append(failedItems, models.FailedItem{
+					ExternalID:   meliItemID,
+					FailureStage: models.EtlStageExtract,
+					ErrorMessage: errs[i].Message(),
+				})
+			}
+			continue
+		}
+
+		for _, result := range results[i] {
+			if result.Code != http.StatusOK {
+				failedItems = append(failedItems, models.FailedItem{
+					ExternalID:   result.ID,
+					FailureStage: models.EtlStageExtract,
+					ErrorMessage: result.Message,
+				})
+				continue
+			}
+			items = append(items, result.Item)
+		}
+	}
+
+	if len(chunks) > 0 && failedChunks == len(chunks) {
+		return nil, nil, errs[0]
+	}
+
+	return items, failedItems, nil
+}
+
+// searchUserItemIDs lists the ids of every item of the seller. Offset paging stops at meliSearchOffsetLimit
+// results, so catalogs bigger than that are listed again with a scan search.
+func (s *mercadoLibreService) searchUserItemIDs(ctx context.Context, credentials models.MercadoLibreCredential, pageSize int) ([]string, apierrors.ApiError) {
+	progress := progressFromContext(ctx)
+
 	var allItemIDs []string
 	offset := 0
+	page := 0
 
 	for {
+		if ctx.Err() != nil {
+			return nil, cancelledError(ctx)
+		}
+
 		searchResult, err := s.meliClient.GetUserItemsWithPagination(ctx, credentials.UserIDMeli, credentials.AccessToken, offset, pageSize)
 		if err != nil {
-			return []dto.MeliItemResponse{}, err
+			return nil, err
+		}
+
+		if searchResult.Paging.Total > meliSearchOffsetLimit {
+			return s.scanUserItemIDs(ctx, credentials, searchResult.Paging.Total)
 		}
 
 		if len(searchResult.Results) == 0 {
@@ -179,40 +336,98 @@ func (s *mercadoLibreService) GetUserItemsDetailsWithPagination(ctx context.Cont
 
 		allItemIDs = append(allItemIDs, searchResult.Results...)
 		offset += pageSize
+		page++
+		progress.PageExtracted(page, len(allItemIDs), searchResult.Paging.Total)
 
-		// Check if we've received fewer results than requested (last page)
-		if len(searchResult.Results) < pageSize {
+		// Check if we've received fewer results than requested (last page). A full last page ends the listing too,
+		// MercadoLibre rejects offsets past the limit.
+		if len(searchResult.Results) < pageSize || len(allItemIDs) >= searchResult.Paging.Total {
 			break
 		}
 	}
 
-	if len(allItemIDs) == 0 {
-		return []dto.MeliItemResponse{}, nil
-	}
+	return allItemIDs, nil
+}
 
-	// Step 2: Batch fetch full details for all items using MercadoLibre's multi-get endpoint
-	itemsDetails, err := s.meliClient.GetItems(ctx, allItemIDs, credentials.AccessToken)
-	if err != nil {
-		return []dto.MeliItemResponse{}, err
+// scanUserItemIDs lists the ids of every item of the seller with a scan search. Scan pages can repeat ids
+// across pages, so they are deduplicated.
+func (s *mercadoLibreService) scanUserItemIDs(ctx context.Context, credentials models.MercadoLibreCredential, total int) ([]string, apierrors.ApiError) {
+	progress := progressFromContext(ctx)
+
+	allItemIDs := make([]string, 0, total)
+	seen := make(map[string]bool, total)
+	scrollID := ""
+	page := 0
+
+	for {
+		if ctx.Err() != nil {
+			return nil, cancelledError(ctx)
+		}
+
+		searchResult, err := s.meliClient.ScanUserItems(ctx, credentials.UserIDMeli, credentials.AccessToken, scrollID, meliScanPageSize)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(searchResult.Results) == 0 {
+			break
+		}
+
+		for _, itemID := range searchResult.Results {
+			if !seen[itemID] {
+				seen[itemID] = true
+				allItemIDs = append(allItemIDs, itemID)
+			}
+		}
+		page++
+		progress.PageExtracted(page, len(allItemIDs), total)
+
+		if searchResult.ScrollID == "" {
+			break
+		}
+		scrollID = searchResult.ScrollID
 	}
 
-	return itemsDetails, nil
+	return allItemIDs, nil
 }
 
-func (s *mercadoLibreService) GetSizeChart(ctx context.Context, chartID string) (dto.MeliSizeChartResponse, apierrors.ApiError) {
-	userID := fmt.Sprint(ctx.Value(goauth.FirebaseUserID))
+// attachDetails fetches the description of every item, and the sale prices of discounted ones, with at most
+// descriptionWorkers items in flight. Details that cannot be fetched are left empty: the transform falls back to
+// brand and title for the description and to the item's own prices for the sale.
+func (s *mercadoLibreService) attachDetails(ctx context.Context, items []dto.MeliItemResponse, accessToken string) {
+	slots := make(chan struct{}, s.descriptionWorkers)
+	var wg sync.WaitGroup
+
+	for i := range items {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return
+		case slots <- struct{}{}:
+		}
 
-	// Get credentials with auto-refresh
-	credentials, err := s.credentialsService.GetCredentialsByUserID(ctx, userID)
-	if err != nil {
-		return dto.MeliSizeChartResponse{}, err
+		wg.Add(1)
+		go func(item *dto.MeliItemResponse) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			s.attachItemDetails(ctx, item, accessToken)
+		}(&items[i])
 	}
 
-	// Call MercadoLibre API to get size chart
-	sizeChart, err := s.meliClient.GetSizeChart(ctx, chartID, credentials.AccessToken)
-	if err != nil {
-		return dto.MeliSizeChartResponse{}, err
+	wg.Wait()
+}
+
+func (s *mercadoLibreService) attachItemDetails(ctx context.Context, item *dto.MeliItemResponse, accessToken string) {
+	if description, err := s.meliClient.GetItemDescription(ctx, item.ID, accessToken); err == nil {
+		item.Description = &description
 	}
 
-	return sizeChart, nil
+	// Only discounted items have a promotion to read the window of
+	if !utils.IsMeliItemDiscounted(*item) {
+		return
+	}
+	if prices, err := s.meliClient.GetItemPrices(ctx, item.ID, accessToken); err == nil {
+		item.SalePrices = prices.Prices
+	}
 }
@@ -17,7 +17,8 @@ import (
 type MercadoLibreCredentialsService interface {
 	GetCredentialsByShopID(ctx context.Context, shopID string) (models.MercadoLibreCredential, apierrors.ApiError)
 	GetCredentialsByUserID(ctx context.Context, userID string) (models.MercadoLibreCredential, apierrors.ApiError)
-	GetOAuthURL(ctx context.Context) (models.MercadoLibreURL, apierrors.ApiError)
+	GetCredentialsByMeliUserID(ctx context.Context, meliUserID int64) (models.MercadoLibreCredential, apierrors.ApiError)
+	GetOAuthURL(ctx context.Context, siteID string) (models.MercadoLibreURL, apierrors.ApiError)
 	CreateOAuthCredentials(ctx context.Context, input dto.MercadoLibreAuthRedirectDTO) apierrors.ApiError
 	DeleteCredentials(ctx context.Context, userID string) apierrors.ApiError
 }
@@ -58,13 +59,18 @@ func (s *mercadoLibreCredentialsService) GetCredentialsByUserID(ctx context.Cont
 	return s.checkAndRefreshToken(ctx, credentials)
 }
 
-func (s *mercadoLibreCredentialsService) checkAndRefreshToken(ctx context.Context, credentials models.MercadoLibreCredential) (models.MercadoLibreCredential, apierrors.ApiError) {
-	now := time.Now().UTC()
-	expirationTime := time.Duration(credentials.ExpiresIn) * time.Second
-	// Refresh 1 hour before expiration
-	expirationDate := credentials.UpdatedAt.Add(expirationTime - 1*time.Hour)
+// GetCredentialsByMeliUserID resolves the seller behind a MercadoLibre user id
+func (s *mercadoLibreCredentialsService) GetCredentialsByMeliUserID(ctx context.Context, meliUserID int64) (models.MercadoLibreCredential, apierrors.ApiError) {
+	credentials, err := s.repository.GetCredentialsByMeliUserID(ctx, meliUserID)
+	if err != nil {
+		return models.MercadoLibreCredential{}, err
+	}
+
+	return s.checkAndRefreshToken(ctx, credentials)
+}
 
-	if now.After(expirationDate) {
+func (s *mercadoLibreCredentialsService) checkAndRefreshToken(ctx context.Context, credentials models.MercadoLibreCredential) (models.MercadoLibreCredential, apierrors.ApiError) {
+	if credentials.NeedsRefresh() {
 		refresh, err := s.refreshOAuthCredentials(ctx, credentials)
 		if err != nil {
 			return models.MercadoLibreCredential{}, err
@@ -76,8 +82,8 @@ func (s *mercadoLibreCredentialsService) checkAndRefreshToken(ctx context.Contex
 	return credentials, nil
 }
 
-func (s *mercadoLibreCredentialsService) GetOAuthURL(ctx context.Context) (models.MercadoLibreURL, apierrors.ApiError) {
-	return s.authClient.GetOAuthURL(ctx)
+func (s *mercadoLibreCredentialsService) GetOAuthURL(ctx context.Context, siteID string) (models.MercadoLibreURL, apierrors.ApiError) {
+	return s.authClient.GetOAuthURL(ctx, siteID)
 }
 
 func (s *mercadoLibreCredentialsService) CreateOAuthCredentials(ctx context.Context, input dto.MercadoLibreAuthRedirectDTO) apierrors.ApiError {
@@ -103,6 +109,7 @@ func (s *mercadoLibreCredentialsService) CreateOAuthCredentials(ctx context.Cont
 		ExpiresIn:    response.ExpiresIn,
 		Scope:        response.Scope,
 		UserIDMeli:   response.UserID,
+		SiteID:       s.sellerSite(ctx, response.AccessToken, input.SiteID),
 		UpdatedAt:    time.Now().UTC(),
 		CreatedAt:    time.Now().UTC(),
 	}
@@ -130,6 +137,20 @@ func (s *mercadoLibreCredentialsService) CreateOAuthCredentials(ctx context.Cont
 	return nil
 }
 
+// sellerSite is the site of the account the seller connected, read from MercadoLibre. The site the seller was
+// sent to authorize on is used when the account can't be read.
+func (s *mercadoLibreCredentialsService) sellerSite(ctx context.Context, accessToken string, requestedSiteID string) string {
+	user, err := s.authClient.GetCurrentUser(ctx, accessToken)
+	if err == nil && clients.IsSupportedMeliSite(user.SiteID) {
+		return user.SiteID
+	}
+
+	if clients.IsSupportedMeliSite(requestedSiteID) {
+		return requestedSiteID
+	}
+	return models.DefaultMeliSiteID
+}
+
 func (s *mercadoLibreCredentialsService) DeleteCredentials(ctx context.Context, userID string) apierrors.ApiError {
 	return s.repository.DeleteCredentials(ctx, userID)
 }
@@ -150,6 +171,7 @@ func (s *mercadoLibreCredentialsService) refreshOAuthCredentials(ctx context.Con
 		ExpiresIn:    response.ExpiresIn,
 		Scope:        response.Scope,
 		UserIDMeli:   response.UserID,
+		SiteID:       credentials.SiteID,
 		UpdatedAt:    time.Now().UTC(),
 		CreatedAt:    credentials.CreatedAt,
 	}
@@ -0,0 +1,98 @@
+package utils
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models"
+)
+
+// NewBatchID returns a unique id for a run, prefixed by its source so batches are easy to tell apart
+func NewBatchID(sourceType string) string {
+	return fmt.Sprintf("%s-%s-%s", sourceType, time.Now().UTC().Format("20060102T150405"), generateBatchID(6))
+}
+
+// SnapshotBatch records which of the items a run is about to load are new to the shop and the current state
+// of the ones it overwrites, matching them by external id like DiffItems does
+func SnapshotBatch(batchID string, shopID string, sourceType string, existingItems []models.Item, items []models.Item) models.EtlBatchSnapshot {
+	existingByExternalID := make(map[string]models.Item, len(existingItems))
+	for _, item := range existingItems {
+		if item.Source != nil && item.Source.ExternalID != "" && item.Source.SourceType == sourceType {
+			existingByExternalID[item.Source.ExternalID] = item
+		}
+	}
+
+	snapshot := models.EtlBatchSnapshot{
+		BatchID:    batchID,
+		ShopID:     shopID,
+		SourceType: sourceType,
+		Created:    make([]string, 0),
+		Updated:    make([]models.Item, 0),
+		CreatedAt:  time.Now().UTC(),
+	}
+
+	for _, item := range items {
+		if item.Source == nil {
+			continue
+		}
+
+		if existing, ok := existingByExternalID[item.Source.ExternalID]; ok {
+			snapshot.Updated = append(snapshot.Updated, existing)
+			continue
+		}
+		snapshot.Created = append(snapshot.Created, item.Source.ExternalID)
+	}
+
+	return snapshot
+}
+
+// PlanRollback compares a batch snapshot with the shop's current items. Items still owned by the batch are
+// restored to their before-image or, when the batch created them, deleted. Items a later run changed are skipped.
+// A before-image without batch id is restored under the rollback batch id, an empty one would leave the item
+// tagged with the batch being rolled back.
+func PlanRollback(snapshot models.EtlBatchSnapshot, currentItems []models.Item) (restore []models.Item, deleted []string, skipped []string) {
+	currentBatch := make(map[string]string, len(currentItems))
+	for _, item := range currentItems {
+		if item.Source != nil && item.Source.ExternalID != "" && item.Source.SourceType == snapshot.SourceType {
+			currentBatch[item.Source.ExternalID] = item.Source.BatchID
+		}
+	}
+
+	restore = make([]models.Item, 0, len(snapshot.Updated))
+	deleted = make([]string, 0, len(snapshot.Created))
+	skipped = make([]string, 0)
+
+	for _, before := range snapshot.Updated {
+		externalID := before.Source.ExternalID
+		if currentBatch[externalID] != snapshot.BatchID {
+			skipped = append(skipped, externalID)
+			continue
+		}
+		if before.Source.BatchID == "" {
+			source := *before.Source
+			source.BatchID = RollbackBatchID(snapshot.BatchID)
+			before.Source = &source
+		}
+		restore = append(restore, before)
+	}
+
+	for _, externalID := range snapshot.Created {
+		batchID, ok := currentBatch[externalID]
+		if !ok {
+			// Never loaded, or already removed
+			continue
+		}
+		if batchID != snapshot.BatchID {
+			skipped = append(skipped, externalID)
+			continue
+		}
+		deleted = append(deleted, externalID)
+	}
+
+	return restore, deleted, skipped
+}
+
+// RollbackBatchID is the batch id items restored by the rollback of a batch carry when they had none
+func RollbackBatchID(batchID string) string {
+	return batchID + "-rollback"
+}
@@ -0,0 +1,280 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models"
+)
+
+const (
+	ancestorMappingConfidence = 0.9
+	domainMappingConfidence   = 0.75
+	nameMatchConfidence       = 0.6
+	// minNameScore is the least path similarity a Jopit category needs to be suggested
+	minNameScore = 0.5
+)
+
+var (
+	accentReplacer = strings.NewReplacer("á", "a", "é", "e", "í", "i", "ó", "o", "ú", "u", "ü", "u", "ñ", "n", "ç", "c", "ã", "a", "õ", "o", "â", "a", "ê", "e", "ô", "o")
+	nameStopwords  = map[string]bool{"de": true, "del": true, "y": true, "e": true, "para": true, "con": true, "sin": true, "el": true, "la": true, "los": true, "las": true, "en": true, "a": true, "otros": true, "otras": true, "and": true, "for": true}
+)
+
+// CategoryUsage is a MercadoLibre category found in a shop's imported items
+type CategoryUsage struct {
+	CategoryID string
+	DomainID   string
+	ItemsCount int
+}
+
+// CategoryUsages counts the MercadoLibre categories of the imported items, most used first
+func CategoryUsages(items []models.Item) []CategoryUsage {
+	byCategory := map[string]*CategoryUsage{}
+	for _, item := range items {
+		if item.Source == nil || item.Source.SourceType != models.MeliSourceType {
+			continue
+		}
+
+		categoryID := item.Source.TransformMetadata["original_category_id"]
+		if categoryID == "" {
+			continue
+		}
+
+		usage, ok := byCategory[categoryID]
+		if !ok {
+			usage = &CategoryUsage{CategoryID: categoryID, DomainID: item.Source.TransformMetadata["original_domain_id"]}
+			byCategory[categoryID] = usage
+		}
+		usage.ItemsCount++
+	}
+
+	usages := make([]CategoryUsage, 0, len(byCategory))
+	for _, usage := range byCategory {
+		usages = append(usages, *usage)
+	}
+
+	sort.Slice(usages, func(i, j int) bool {
+		if usages[i].ItemsCount != usages[j].ItemsCount {
+			return usages[i].ItemsCount > usages[j].ItemsCount
+		}
+		return usages[i].CategoryID < usages[j].CategoryID
+	})
+
+	return usages
+}
+
+// SuggestCategoryMapping proposes a Jopit category for an unmapped MercadoLibre category. In order of confidence
+// it reuses the mapping of an ancestor category, the mapping most categories of the same domain in the shop's
+// catalog share, or the Jopit category whose name best matches the category path and domain.
+func SuggestCategoryMapping(usage CategoryUsage, category models.MeliCategory, usages []CategoryUsage, mappings []models.CategoryMapping, jopitCategories []models.ItemCategory) models.CategoryMappingSuggestion {
+	suggestion := models.CategoryMappingSuggestion{
+		MeliCategoryID:   usage.CategoryID,
+		MeliDomainID:     usage.DomainID,
+		MeliCategoryPath: category.PathNames(),
+		ItemsCount:       usage.ItemsCount,
+	}
+
+	if mapping, ancestor, ok := ancestorMapping(category, mappings); ok {
+		suggestion.Suggested = suggestedMapping(usage.CategoryID, mapping.ToItemCategory())
+		suggestion.Confidence = ancestorMappingConfidence
+		suggestion.Reason = fmt.Sprintf("parent category %q is mapped to %s", ancestor.Name, categoryLabel(mapping.ToItemCategory()))
+		return suggestion
+	}
+
+	if target, siblings, share, ok := domainMapping(usage, usages, mappings); ok {
+		suggestion.Suggested = suggestedMapping(usage.CategoryID, target)
+		suggestion.Confidence = round2(domainMappingConfidence * share)
+		suggestion.Reason = fmt.Sprintf("%d other categories of domain %s are mapped to %s", siblings, usage.DomainID, categoryLabel(target))
+		return suggestion
+	}
+
+	if target, score, ok := bestNameMatch(category.PathNames(), usage.DomainID, jopitCategories); ok {
+		suggestion.Suggested = suggestedMapping(usage.CategoryID, target)
+		suggestion.Confidence = round2(nameMatchConfidence * score)
+		suggestion.Reason = fmt.Sprintf("category path %q matches %s", strings.Join(category.PathNames(), " > "), categoryLabel(target))
+	}
+
+	return suggestion
+}
+
+// ancestorMapping finds the category mapping of the closest ancestor of the category
+func ancestorMapping(category models.MeliCategory, mappings []models.CategoryMapping) (models.CategoryMapping, models.MeliCategoryNode, bool) {
+	for i := len(category.PathFromRoot) - 1; i >= 0; i-- {
+		node := category.PathFromRoot[i]
+		if node.ID == category.ID {
+			continue
+		}
+		if mapping, ok := ResolveCategoryMapping(mappings, node.ID, ""); ok {
+			return mapping, node, true
+		}
+	}
+	return models.CategoryMapping{}, models.MeliCategoryNode{}, false
+}
+
+// domainMapping finds the Jopit category most of the shop's mapped categories of the same domain point to,
+// weighted by their items. share is the part of those items that points to it.
+func domainMapping(usage CategoryUsage, usages []CategoryUsage, mappings []models.CategoryMapping) (target models.ItemCategory, siblings int, share float64, found bool) {
+	if usage.DomainID == "" {
+		return models.ItemCategory{}, 0, 0, false
+	}
+
+	type tally struct {
+		category models.ItemCategory
+		siblings int
+		items    int
+	}
+	tallies := map[string]*tally{}
+	total := 0
+	for _, other := range usages {
+		if other.CategoryID == usage.CategoryID || other.DomainID != usage.DomainID {
+			continue
+		}
+		mapping, ok := ResolveCategoryMapping(mappings, other.CategoryID, "")
+		if !ok {
+			continue
+		}
+
+		key := mapping.CategoryID + "/" + mapping.SubcategoryID
+		if tallies[key] == nil {
+			tallies[key] = &tally{category: mapping.ToItemCategory()}
+		}
+		tallies[key].siblings++
+		tallies[key].items += other.ItemsCount
+		total += other.ItemsCount
+	}
+
+	var best *tally
+	bestKey := ""
+	for key, candidate := range tallies {
+		if best == nil || candidate.items > best.items || (candidate.items == best.items && key < bestKey) {
+			best, bestKey = candidate, key
+		}
+	}
+	if best == nil || total == 0 {
+		return models.ItemCategory{}, 0, 0, false
+	}
+
+	return best.category, best.siblings, float64(best.items) / float64(total), true
+}
+
+// bestNameMatch scores every Jopit category against the category path. A subcategory counts mostly by its
+// own name and a little by its parent's, so the most specific matching entry wins.
+func bestNameMatch(path []string, domainID string, jopitCategories []models.ItemCategory) (models.ItemCategory, float64, bool) {
+	var best models.ItemCategory
+	bestScore := 0.0
+	for _, candidate := range jopitCategories {
+		score := nameScore(candidate.Name, path, domainID)
+		if candidate.Subcategory != nil {
+			score = 0.8*nameScore(candidate.Subcategory.Name, path, domainID) + 0.2*score
+		}
+		if score > bestScore {
+			best, bestScore = candidate, score
+		}
+	}
+
+	if bestScore < minNameScore {
+		return models.ItemCategory{}, 0, false
+	}
+	return best, bestScore, true
+}
+
+// nameScore is the share of the name's words found in the path, weighted down the further the matching node
+// is from the leaf. The domain, usually English, weighs half.
+func nameScore(name string, path []string, domainID string) float64 {
+	tokens := nameTokens(name)
+	if len(tokens) == 0 {
+		return 0
+	}
+
+	best := 0.0
+	for distance := 0; distance < len(path); distance++ {
+		weight := 1 - 0.2*float64(distance)
+		if weight < 0.2 {
+			weight = 0.2
+		}
+		best = max(best, weight*tokenOverlap(tokens, nameTokenSet(path[len(path)-1-distance])))
+	}
+
+	if domainID != "" {
+		domain := domainID
+		if index := strings.Index(domain, "-"); index >= 0 {
+			domain = domain[index+1:]
+		}
+		best = max(best, 0.5*tokenOverlap(tokens, nameTokenSet(domain)))
+	}
+
+	return best
+}
+
+func tokenOverlap(tokens []string, set map[string]bool) float64 {
+	matched := 0
+	for _, token := range tokens {
+		if set[token] {
+			matched++
+		}
+	}
+	return float64(matched) / float64(len(tokens))
+}
+
+func nameTokenSet(value string) map[string]bool {
+	set := map[string]bool{}
+	for _, token := range nameTokens(value) {
+		set[token] = true
+	}
+	return set
+}
+
+// nameTokens splits a category name into lowercase words without accents, stopwords nor plurals
+func nameTokens(value string) []string {
+	value = accentReplacer.Replace(strings.ToLower(value))
+	words := strings.FieldsFunc(value, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	tokens := make([]string, 0, len(words))
+	for _, word := range words {
+		if nameStopwords[word] {
+			continue
+		}
+		tokens = append(tokens, singular(word))
+	}
+	return tokens
+}
+
+// singular strips Spanish and English plurals: remeras, pantalones, shoes
+func singular(word string) string {
+	if len(word) > 4 && strings.HasSuffix(word, "es") && !strings.ContainsRune("aeiou", rune(word[len(word)-3])) {
+		return word[:len(word)-2]
+	}
+	if len(word) > 3 && strings.HasSuffix(word, "s") {
+		return word[:len(word)-1]
+	}
+	return word
+}
+
+func suggestedMapping(meliCategoryID string, target models.ItemCategory) *models.CategoryMapping {
+	mapping := &models.CategoryMapping{
+		MeliCategoryID: meliCategoryID,
+		CategoryID:     target.ID,
+		CategoryName:   target.Name,
+	}
+	if target.Subcategory != nil {
+		mapping.SubcategoryID = target.Subcategory.ID
+		mapping.SubcategoryName = target.Subcategory.Name
+	}
+	return mapping
+}
+
+func categoryLabel(category models.ItemCategory) string {
+	if category.Subcategory != nil {
+		return category.Name + " > " + category.Subcategory.Name
+	}
+	return category.Name
+}
+
+func round2(value float64) float64 {
+	return math.Round(value*100) / 100
+}
@@ -0,0 +1,186 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models/dto"
+)
+
+var hexColorRegex = regexp.MustCompile(`^#?([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// meliColorValueHexes are MercadoLibre's standard COLOR values, shared by every site
+var meliColorValueHexes = map[string]string{
+	"52049":  "#000000", // Negro
+	"52055":  "#FFFFFF", // Blanco
+	"283165": "#808080", // Gris
+	"51993":  "#FF0000", // Rojo
+	"52028":  "#0000FF", // Azul
+	"52014":  "#008000", // Verde
+	"51994":  "#FFC0CB", // Rosa
+}
+
+// colorNameHexes is the curated dictionary of color names, Spanish and English, keyed by normalizeName
+var colorNameHexes = colorDictionary(map[string][]string{
+	"#000000": {"negro", "black"},
+	"#FFFFFF": {"blanco", "white"},
+	"#808080": {"gris", "gray", "grey"},
+	"#D3D3D3": {"gris claro", "light gray", "light grey"},
+	"#505050": {"gris oscuro", "dark gray", "dark grey"},
+	"#B5B5B5": {"gris melange", "melange"},
+	"#C0C0C0": {"plata", "plateado", "silver"},
+	"#383838": {"grafito", "graphite"},
+	"#FF0000": {"rojo", "red"},
+	"#800020": {"bordo", "bordeaux", "burgundy"},
+	"#722F37": {"vino", "wine"},
+	"#0000FF": {"azul", "blue"},
+	"#000080": {"azul marino", "marino", "navy", "navy blue"},
+	"#0055A4": {"azul francia"},
+	"#0047FF": {"azul electrico"},
+	"#00008B": {"azul oscuro", "dark blue"},
+	"#87CEEB": {"celeste", "light blue", "sky blue"},
+	"#40E0D0": {"turquesa", "turquoise"},
+	"#00FFFF": {"aqua", "cian", "cyan"},
+	"#1B4D5C": {"petroleo", "petrol"},
+	"#008000": {"verde", "green"},
+	"#006400": {"verde oscuro", "dark green"},
+	"#90EE90": {"verde claro", "light green"},
+	"#7FFFD4": {"verde agua", "aguamarina", "aquamarine"},
+	"#4B5320": {"verde militar", "militar", "army green"},
+	"#808000": {"oliva", "verde oliva", "olive"},
+	"#98FF98": {"menta", "mint"},
+	"#FFFF00": {"amarillo", "yellow"},
+	"#E1AD01": {"mostaza", "mustard"},
+	"#FFD700": {"dorado", "oro", "gold"},
+	"#FFA500": {"naranja", "orange"},
+	"#FF7F50": {"coral"},
+	"#FA8072": {"salmon"},
+	"#FFC0CB": {"rosa", "rosado", "pink"},
+	"#FFD1DC": {"rosa claro", "light pink"},
+	"#FF00FF": {"fucsia", "fuchsia", "magenta"},
+	"#8A2BE2": {"violeta", "violet"},
+	"#C8A2C8": {"lila", "lilac"},
+	"#E6E6FA": {"lavanda", "lavender"},
+	"#800080": {"morado", "purpura", "purple"},
+	"#8B4513": {"marron", "brown"},
+	"#7B3F00": {"chocolate"},
+	"#C19A6B": {"camel"},
+	"#A0522D": {"suela"},
+	"#A67B5B": {"tostado"},
+	"#D2B48C": {"tan"},
+	"#F5F5DC": {"beige"},
+	"#C2B280": {"arena", "sand"},
+	"#F5F0E1": {"crudo", "natural", "ecru"},
+	"#E3DAC9": {"hueso", "bone"},
+	"#FFFFF0": {"marfil", "ivory"},
+	"#FFFDD0": {"crema", "cream"},
+	"#E3BC9A": {"nude"},
+	"#C3B091": {"khaki", "kaki", "caqui"},
+	"#483C32": {"topo", "taupe"},
+	"#5D7BA8": {"jean", "denim"},
+})
+
+func colorDictionary(namesByHex map[string][]string) map[string]string {
+	dictionary := map[string]string{}
+	for hex, names := range namesByHex {
+		for _, name := range names {
+			dictionary[name] = hex
+		}
+	}
+	return dictionary
+}
+
+// ColorResolver finds the hex code of a variant color. A shop's own colors win over MercadoLibre's value
+// metadata, its standard value IDs and the curated dictionary, in that order.
+type ColorResolver struct {
+	shopColors map[string]string
+}
+
+// NewColorResolver builds a resolver extended with a shop's color name to hex dictionary
+func NewColorResolver(shopColors map[string]string) ColorResolver {
+	normalized := make(map[string]string, len(shopColors))
+	for name, hex := range shopColors {
+		if color, ok := NormalizeHexColor(hex); ok {
+			normalized[normalizeName(name)] = color
+		}
+	}
+	return ColorResolver{shopColors: normalized}
+}
+
+// Resolve returns the hex code of a COLOR attribute, known is false when nothing matched
+func (r ColorResolver) Resolve(attribute dto.MeliAttribute) (hex string, known bool) {
+	name := normalizeName(attribute.ValueName)
+	if name == "" && len(attribute.Values) > 0 {
+		name = normalizeName(attribute.Values[0].Name)
+	}
+
+	if hex, ok := r.shopColors[name]; ok {
+		return hex, true
+	}
+
+	for _, value := range attribute.Values {
+		if rgb, ok := value.Metadata["rgb"].(string); ok {
+			if hex, ok := NormalizeHexColor(rgb); ok {
+				return hex, true
+			}
+		}
+	}
+
+	if attribute.ValueID != nil {
+		if hex, ok := meliColorValueHexes[*attribute.ValueID]; ok {
+			return hex, true
+		}
+	}
+
+	return r.ResolveName(name)
+}
+
+// ResolveName returns the hex code of a color name. Names not in the dictionaries resolve by their first known
+// word, singular, so "Negros con vivos blancos" is black.
+func (r ColorResolver) ResolveName(name string) (string, bool) {
+	name = normalizeName(name)
+	if name == "" {
+		return "", false
+	}
+
+	for _, dictionary := range []map[string]string{r.shopColors, colorNameHexes} {
+		if hex, ok := dictionary[name]; ok {
+			return hex, true
+		}
+	}
+
+	for _, word := range strings.Fields(name) {
+		for _, dictionary := range []map[string]string{r.shopColors, colorNameHexes} {
+			if hex, ok := dictionary[word]; ok {
+				return hex, true
+			}
+			if hex, ok := dictionary[singular(word)]; ok {
+				return hex, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// NormalizeHexColor returns a hex color as #RRGGBB, accepting 3 or 6 digits with or without the #
+func NormalizeHexColor(value string) (string, bool) {
+	match := hexColorRegex.FindStringSubmatch(strings.TrimSpace(value))
+	if match == nil {
+		return "", false
+	}
+
+	digits := strings.ToUpper(match[1])
+	if len(digits) == 3 {
+		digits = string([]byte{digits[0], digits[0], digits[1], digits[1], digits[2], digits[2]})
+	}
+	return "#" + digits, true
+}
+
+// normalizeName lowercases a name and drops accents and punctuation
+func normalizeName(name string) string {
+	name = accentReplacer.Replace(strings.ToLower(name))
+	return strings.Join(strings.FieldsFunc(name, func(r rune) bool {
+		return !('a' <= r && r <= 'z') && !('0' <= r && r <= '9')
+	}), " ")
+}
@@ -0,0 +1,245 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronSearchYears bounds the search for expressions that can never match, like February 30th
+const cronSearchYears = 5
+
+var cronMacros = map[string]string{
+	"@hourly":  "0 * * * *",
+	"@daily":   "0 0 * * *",
+	"@weekly":  "0 0 * * 0",
+	"@monthly": "0 0 1 * *",
+}
+
+// CronSchedule is a parsed standard cron expression: minute hour day-of-month month day-of-week
+type CronSchedule struct {
+	minute        uint64
+	hour          uint64
+	dayOfMonth    uint64
+	month         uint64
+	dayOfWeek     uint64
+	domRestricted bool
+	dowRestricted bool
+}
+
+type cronField struct {
+	name string
+	min  int
+	max  int
+}
+
+var cronFields = []cronField{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12},
+	{name: "day of week", min: 0, max: 7},
+}
+
+// ParseCron parses a 5 field cron expression supporting *, lists, ranges, steps and the @hourly, @daily, @weekly and @monthly macros
+func ParseCron(expr string) (CronSchedule, error) {
+	expr = strings.TrimSpace(expr)
+	if macro, ok := cronMacros[expr]; ok {
+		expr = macro
+	}
+
+	parts := strings.Fields(expr)
+	if len(parts) != len(cronFields) {
+		return CronSchedule{}, fmt.Errorf("cron expression %q must have %d fields", expr, len(cronFields))
+	}
+
+	bits := make([]uint64, len(cronFields))
+	for i, field := range cronFields {
+		value, err := parseCronField(parts[i], field)
+		if err != nil {
+			return CronSchedule{}, err
+		}
+		bits[i] = value
+	}
+
+	// Sunday can be written as 0 or 7
+	if bits[4]&(1<<7) != 0 {
+		bits[4] = bits[4]&^(1<<7) | 1
+	}
+
+	return CronSchedule{
+		minute:        bits[0],
+		hour:          bits[1],
+		dayOfMonth:    bits[2],
+		month:         bits[3],
+		dayOfWeek:     bits[4],
+		domRestricted: parts[2] != "*",
+		dowRestricted: parts[4] != "*",
+	}, nil
+}
+
+func parseCronField(value string, field cronField) (uint64, error) {
+	var bits uint64
+
+	for _, part := range strings.Split(value, ",") {
+		rangeExpr, stepExpr, hasStep := strings.Cut(part, "/")
+
+		step := 1
+		if hasStep {
+			parsed, err := strconv.Atoi(stepExpr)
+			if err != nil || parsed <= 0 {
+				return 0, fmt.Errorf("invalid step %q in cron %s field", stepExpr, field.name)
+			}
+			step = parsed
+		}
+
+		start, end := field.min, field.max
+		switch {
+		case rangeExpr == "*":
+		case strings.Contains(rangeExpr, "-"):
+			from, to, _ := strings.Cut(rangeExpr, "-")
+			var err error
+			if start, err = parseCronValue(from, field); err != nil {
+				return 0, err
+			}
+			if end, err = parseCronValue(to, field); err != nil {
+				return 0, err
+			}
+			if start > end {
+				return 0, fmt.Errorf("invalid range %q in cron %s field", rangeExpr, field.name)
+			}
+		default:
+			parsed, err := parseCronValue(rangeExpr, field)
+			if err != nil {
+				return 0, err
+			}
+			start = parsed
+			// A single value only spans to the end of the field when it has a step, like 5/15
+			if !hasStep {
+				end = parsed
+			}
+		}
+
+		for i := start; i <= end; i += step {
+			bits |= 1 << uint(i)
+		}
+	}
+
+	return bits, nil
+}
+
+func parseCronValue(value string, field cronField) (int, error) {
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed < field.min || parsed > field.max {
+		return 0, fmt.Errorf("invalid value %q in cron %s field, expected %d-%d", value, field.name, field.min, field.max)
+	}
+	return parsed, nil
+}
+
+// Next returns the first time strictly after the given one matching the schedule, evaluated in UTC.
+// The zero time is returned when the expression never matches.
+func (c CronSchedule) Next(after time.Time) time.Time {
+	t := after.UTC().Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(cronSearchYears, 0, 0)
+
+	for t.Before(limit) {
+		if c.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if !c.matchesDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if c.hour&(1<<uint(t.Hour())) == 0 {
+			t = t.Truncate(time.Hour).Add(time.Hour)
+			continue
+		}
+		if c.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+
+	return time.Time{}
+}
+
+// MinGap returns the shortest time between two consecutive runs. Only the minutes and hours are considered,
+// restricted days can only make the gaps longer.
+func (c CronSchedule) MinGap() time.Duration {
+	times := make([]int, 0)
+	for hour := 0; hour < 24; hour++ {
+		if c.hour&(1<<uint(hour)) == 0 {
+			continue
+		}
+		for minute := 0; minute < 60; minute++ {
+			if c.minute&(1<<uint(minute)) != 0 {
+				times = append(times, hour*60+minute)
+			}
+		}
+	}
+
+	if len(times) == 0 {
+		return 0
+	}
+
+	// From the last run of a day to the first one of the next
+	gap := 24*60 - times[len(times)-1] + times[0]
+	for i := 1; i < len(times); i++ {
+		gap = min(gap, times[i]-times[i-1])
+	}
+
+	return time.Duration(gap) * time.Minute
+}
+
+// matchesDay follows the usual cron rule: when both day fields are restricted either of them can match
+func (c CronSchedule) matchesDay(t time.Time) bool {
+	domMatch := c.dayOfMonth&(1<<uint(t.Day())) != 0
+	dowMatch := c.dayOfWeek&(1<<uint(t.Weekday())) != 0
+
+	if c.domRestricted && c.dowRestricted {
+		return domMatch || dowMatch
+	}
+	return domMatch && dowMatch
+}
+
+// NextScheduleRun returns the next run after the given time for a cron expression or, when empty, a minutes interval
+func NextScheduleRun(cron string, intervalMinutes int, after time.Time) (time.Time, error) {
+	if cron == "" {
+		if intervalMinutes <= 0 {
+			return time.Time{}, fmt.Errorf("either a cron expression or a positive interval is required")
+		}
+		return after.UTC().Add(time.Duration(intervalMinutes) * time.Minute), nil
+	}
+
+	schedule, err := ParseCron(cron)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	next := schedule.Next(after)
+	if next.IsZero() {
+		return time.Time{}, fmt.Errorf("cron expression %q never matches", cron)
+	}
+
+	return next, nil
+}
+
+// ScheduleMinGap returns the shortest time between two runs of a cron expression or, when empty, a minutes interval
+func ScheduleMinGap(cron string, intervalMinutes int) (time.Duration, error) {
+	if cron == "" {
+		if intervalMinutes <= 0 {
+			return 0, fmt.Errorf("either a cron expression or a positive interval is required")
+		}
+		return time.Duration(intervalMinutes) * time.Minute, nil
+	}
+
+	schedule, err := ParseCron(cron)
+	if err != nil {
+		return 0, err
+	}
+
+	return schedule.MinGap(), nil
+}
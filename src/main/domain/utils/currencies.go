@@ -0,0 +1,93 @@
+package utils
+
+import (
+	"math"
+
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models"
+)
+
+// registeredCurrency is a currency as prices are shown in it, Decimals is how many places amounts are rounded to
+type registeredCurrency struct {
+	models.Currency
+	Decimals int
+}
+
+// currencies holds every currency a MercadoLibre site lists items in, symbols follow MercadoLibre's own
+var currencies = map[string]registeredCurrency{
+	"ARS": {Currency: models.Currency{ID: "ARS", Symbol: "$", DecimalDivider: ",", ThousandsDivider: "."}, Decimals: 2},
+	"BOB": {Currency: models.Currency{ID: "BOB", Symbol: "Bs", DecimalDivider: ",", ThousandsDivider: "."}, Decimals: 2},
+	"BRL": {Currency: models.Currency{ID: "BRL", Symbol: "R$", DecimalDivider: ",", ThousandsDivider: "."}, Decimals: 2},
+	"CLP": {Currency: models.Currency{ID: "CLP", Symbol: "$", DecimalDivider: ",", ThousandsDivider: "."}, Decimals: 0},
+	"COP": {Currency: models.Currency{ID: "COP", Symbol: "$", DecimalDivider: ",", ThousandsDivider: "."}, Decimals: 0},
+	"CRC": {Currency: models.Currency{ID: "CRC", Symbol: "₡", DecimalDivider: ",", ThousandsDivider: "."}, Decimals: 2},
+	"DOP": {Currency: models.Currency{ID: "DOP", Symbol: "RD$", DecimalDivider: ".", ThousandsDivider: ","}, Decimals: 2},
+	"EUR": {Currency: models.Currency{ID: "EUR", Symbol: "€", DecimalDivider: ",", ThousandsDivider: "."}, Decimals: 2},
+	"GTQ": {Currency: models.Currency{ID: "GTQ", Symbol: "Q", DecimalDivider: ".", ThousandsDivider: ","}, Decimals: 2},
+	"HNL": {Currency: models.Currency{ID: "HNL", Symbol: "L", DecimalDivider: ".", ThousandsDivider: ","}, Decimals: 2},
+	"MXN": {Currency: models.Currency{ID: "MXN", Symbol: "$", DecimalDivider: ".", ThousandsDivider: ","}, Decimals: 2},
+	"NIO": {Currency: models.Currency{ID: "NIO", Symbol: "C$", DecimalDivider: ".", ThousandsDivider: ","}, Decimals: 2},
+	"PAB": {Currency: models.Currency{ID: "PAB", Symbol: "B/.", DecimalDivider: ".", ThousandsDivider: ","}, Decimals: 2},
+	"PEN": {Currency: models.Currency{ID: "PEN", Symbol: "S/", DecimalDivider: ".", ThousandsDivider: ","}, Decimals: 2},
+	"PYG": {Currency: models.Currency{ID: "PYG", Symbol: "₲", DecimalDivider: ",", ThousandsDivider: "."}, Decimals: 0},
+	"USD": {Currency: models.Currency{ID: "USD", Symbol: "U$S", DecimalDivider: ".", ThousandsDivider: ","}, Decimals: 2},
+	"UYU": {Currency: models.Currency{ID: "UYU", Symbol: "$", DecimalDivider: ",", ThousandsDivider: "."}, Decimals: 2},
+	"VES": {Currency: models.Currency{ID: "VES", Symbol: "Bs.", DecimalDivider: ",", ThousandsDivider: "."}, Decimals: 2},
+}
+
+// siteCurrencies is the local currency of each MercadoLibre site, items may still be listed in USD
+var siteCurrencies = map[string]string{
+	"MLA": "ARS", // Argentina
+	"MBO": "BOB", // Bolivia
+	"MLB": "BRL", // Brasil
+	"MLC": "CLP", // Chile
+	"MCO": "COP", // Colombia
+	"MCR": "CRC", // Costa Rica
+	"MRD": "DOP", // República Dominicana
+	"MEC": "USD", // Ecuador
+	"MGT": "GTQ", // Guatemala
+	"MHN": "HNL", // Honduras
+	"MLM": "MXN", // México
+	"MNI": "NIO", // Nicaragua
+	"MPA": "PAB", // Panamá
+	"MPE": "PEN", // Perú
+	"MPY": "PYG", // Paraguay
+	"MSV": "USD", // El Salvador
+	"MLU": "UYU", // Uruguay
+	"MLV": "VES", // Venezuela
+}
+
+// SiteCurrency returns the local currency of a MercadoLibre site, empty for unknown sites
+func SiteCurrency(siteID string) string {
+	return siteCurrencies[siteID]
+}
+
+// IsKnownCurrency tells whether prices can be shown in a currency
+func IsKnownCurrency(currencyID string) bool {
+	_, ok := currencies[currencyID]
+	return ok
+}
+
+// LookupCurrency returns how prices in a currency are shown. Unknown currencies use their ID as symbol.
+func LookupCurrency(currencyID string) models.Currency {
+	if currency, ok := currencies[currencyID]; ok {
+		return currency.Currency
+	}
+
+	return models.Currency{
+		ID:               currencyID,
+		Symbol:           currencyID,
+		DecimalDivider:   ".",
+		ThousandsDivider: ",",
+	}
+}
+
+// roundAmount rounds an amount to the places its currency shows, 2 for unknown currencies
+func roundAmount(amount float64, currencyID string) float64 {
+	decimals := 2
+	if currency, ok := currencies[currencyID]; ok {
+		decimals = currency.Decimals
+	}
+
+	scale := math.Pow(10, float64(decimals))
+	return math.Round(amount*scale) / scale
+}
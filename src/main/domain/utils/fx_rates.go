@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// FxRateProvider converts between currencies, Rate is how many units of to one unit of from buys.
+// It is asked once per converted item, implementations backed by a remote source should cache.
+type FxRateProvider interface {
+	Rate(from, to string) (float64, bool)
+}
+
+// StaticFxRates is the file read by NewStaticFxRateProvider, every rate is the units of the currency one
+// unit of Base buys, e.g. {"base": "USD", "rates": {"ARS": 1180.5, "UYU": 40.1}}
+type StaticFxRates struct {
+	Base  string             `json:"base"`
+	Rates map[string]float64 `json:"rates"`
+}
+
+type staticFxRateProvider struct {
+	rates map[string]float64
+}
+
+// NewStaticFxRateProvider loads fixed rates from a JSON file, any pair of currencies in it can be converted
+func NewStaticFxRateProvider(path string) (FxRateProvider, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading fx rates file %s: %w", path, err)
+	}
+
+	var file StaticFxRates
+	if err := json.Unmarshal(raw, &file); err != nil {
+		return nil, fmt.Errorf("parsing fx rates file %s: %w", path, err)
+	}
+
+	return NewStaticFxRates(file)
+}
+
+// NewStaticFxRates builds a provider from rates already in memory
+func NewStaticFxRates(file StaticFxRates) (FxRateProvider, error) {
+	base := strings.ToUpper(strings.TrimSpace(file.Base))
+	if base == "" {
+		return nil, fmt.Errorf("fx rates have no base currency")
+	}
+
+	rates := map[string]float64{base: 1}
+	for currencyID, rate := range file.Rates {
+		if rate <= 0 {
+			return nil, fmt.Errorf("fx rate for %s must be positive, got %v", currencyID, rate)
+		}
+		rates[strings.ToUpper(strings.TrimSpace(currencyID))] = rate
+	}
+
+	return &staticFxRateProvider{rates: rates}, nil
+}
+
+func (p *staticFxRateProvider) Rate(from, to string) (float64, bool) {
+	fromRate, ok := p.rates[from]
+	if !ok {
+		return 0, false
+	}
+	toRate, ok := p.rates[to]
+	if !ok {
+		return 0, false
+	}
+	return toRate / fromRate, true
+}
@@ -0,0 +1,41 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models"
+)
+
+// ItemID derives the Jopit id of an imported item from where it comes from, so every import of the same
+// source item gets the same id
+func ItemID(shopID string, sourceType string, externalID string) string {
+	return deterministicObjectID("item", shopID, sourceType, externalID)
+}
+
+// deterministicObjectID hashes the parts into a 24 hex characters id, shaped like a Mongo ObjectID
+func deterministicObjectID(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(sum[:12])
+}
+
+// ReconcileItemIdentity gives the items already in the shop, matched by source type and external id, the id of
+// the existing item. Items imported before ids were derived keep theirs, so references to them survive.
+func ReconcileItemIdentity(existingItems []models.Item, items []models.Item) {
+	existingIDs := make(map[string]string, len(existingItems))
+	for _, item := range existingItems {
+		if item.ID != "" && item.Source != nil && item.Source.ExternalID != "" {
+			existingIDs[item.Source.SourceType+"\x00"+item.Source.ExternalID] = item.ID
+		}
+	}
+
+	for i := range items {
+		if items[i].Source == nil {
+			continue
+		}
+		if id, ok := existingIDs[items[i].Source.SourceType+"\x00"+items[i].Source.ExternalID]; ok {
+			items[i].ID = id
+		}
+	}
+}
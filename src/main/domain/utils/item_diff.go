@@ -0,0 +1,157 @@
+package utils
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models"
+)
+
+// DiffItems compares the transformed items with the shop's current items imported from the same source,
+// matching them by external id. Identity and import bookkeeping fields (ids, source) are not compared.
+func DiffItems(existingItems []models.Item, transformedItems []models.Item) []models.ItemDiff {
+	existingByExternalID := make(map[string]models.Item, len(existingItems))
+	for _, item := range existingItems {
+		if item.Source != nil && item.Source.ExternalID != "" {
+			existingByExternalID[item.Source.ExternalID] = item
+		}
+	}
+
+	diffs := make([]models.ItemDiff, 0, len(transformedItems))
+	for _, item := range transformedItems {
+		externalID := ""
+		if item.Source != nil {
+			externalID = item.Source.ExternalID
+		}
+
+		diff := models.ItemDiff{ExternalID: externalID, Name: item.Name, Action: models.ItemDiffCreate}
+
+		existing, ok := existingByExternalID[externalID]
+		if ok && existing.Source.SourceType == sourceTypeOf(item) {
+			diff.ItemID = existing.ID
+			diff.Changes = diffItemFields(existing, item)
+			diff.Action = models.ItemDiffUnchanged
+			if len(diff.Changes) > 0 {
+				diff.Action = models.ItemDiffUpdate
+			}
+		}
+
+		diffs = append(diffs, diff)
+	}
+
+	return diffs
+}
+
+func sourceTypeOf(item models.Item) string {
+	if item.Source == nil {
+		return ""
+	}
+	return item.Source.SourceType
+}
+
+// itemField is a compared field of an item, before and after the run
+type itemField struct {
+	name   string
+	before interface{}
+	after  interface{}
+}
+
+func diffItemFields(before models.Item, after models.Item) []models.ItemFieldChange {
+	fields := []itemField{
+		{"name", before.Name, after.Name},
+		{"description", before.Description, after.Description},
+		{"status", before.Status, after.Status},
+		{"category", categoryNames(before.Category), categoryNames(after.Category)},
+		{"delivery", before.Delivery, after.Delivery},
+		{"attributes", before.Attributes, after.Attributes},
+		{"size_guide", before.SizeGuide, after.SizeGuide},
+		{"variants", before.Variants, after.Variants},
+	}
+
+	// An item without a known price is not reported as a price change
+	if hasPrice(before.Price) {
+		fields = append(fields, itemField{"price", priceAmount(before.Price), priceAmount(after.Price)})
+	}
+
+	changes := make([]models.ItemFieldChange, 0)
+	for _, field := range fields {
+		if !sameJSON(field.before, field.after) {
+			changes = append(changes, models.ItemFieldChange{Field: field.name, Before: field.before, After: field.after})
+		}
+	}
+
+	return changes
+}
+
+// categoryNames leaves out the category ids, they are assigned by the items API
+func categoryNames(category models.ItemCategory) map[string]string {
+	names := map[string]string{"name": category.Name}
+	if category.Subcategory != nil {
+		names["subcategory"] = category.Subcategory.Name
+	}
+	return names
+}
+
+// hasPrice reports whether the price was loaded from the prices API, items come from the items API without one
+func hasPrice(price models.Price) bool {
+	return price.Amount > 0 || price.Currency.ID != ""
+}
+
+// priceAmount leaves out the price ids, they are assigned by the prices API
+func priceAmount(price models.Price) map[string]interface{} {
+	return map[string]interface{}{
+		"amount":      price.Amount,
+		"list_amount": price.ListAmount,
+		"promotion":   price.Promotion,
+		"currency":    price.Currency.ID,
+	}
+}
+
+// sameJSON compares values the way the items API stores them, so nil and empty slices or omitted fields are equal
+func sameJSON(a interface{}, b interface{}) bool {
+	aJSON, aErr := json.Marshal(a)
+	bJSON, bErr := json.Marshal(b)
+	if aErr != nil || bErr != nil {
+		return reflect.DeepEqual(a, b)
+	}
+
+	var aValue, bValue interface{}
+	if json.Unmarshal(aJSON, &aValue) != nil || json.Unmarshal(bJSON, &bValue) != nil {
+		return string(aJSON) == string(bJSON)
+	}
+
+	return reflect.DeepEqual(normalizeJSON(aValue), normalizeJSON(bValue))
+}
+
+// normalizeJSON drops nulls, empty strings, empty lists and empty objects
+func normalizeJSON(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		normalized := make(map[string]interface{}, len(v))
+		for key, inner := range v {
+			if n := normalizeJSON(inner); n != nil {
+				normalized[key] = n
+			}
+		}
+		if len(normalized) == 0 {
+			return nil
+		}
+		return normalized
+	case []interface{}:
+		if len(v) == 0 {
+			return nil
+		}
+		normalized := make([]interface{}, len(v))
+		for i, inner := range v {
+			normalized[i] = normalizeJSON(inner)
+		}
+		return normalized
+	case string:
+		if v == "" {
+			return nil
+		}
+		return v
+	default:
+		return v
+	}
+}
@@ -0,0 +1,166 @@
+package utils
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models/dto"
+)
+
+// Jopit package defaults, used when neither the item nor the shop settings have the data
+const (
+	defaultPackageWeight = 500 // grams
+	defaultPackageLength = 30  // centimeters
+	defaultPackageHeight = 5   // centimeters
+	defaultPackageWidth  = 25  // centimeters
+)
+
+// Values of the package_dimensions transform metadata
+const (
+	PackageDimensionsSource    = "source"
+	PackageDimensionsPartial   = "partial"
+	PackageDimensionsDefaulted = "defaulted"
+)
+
+var (
+	numberUnitPattern         = regexp.MustCompile(`^\s*([0-9]+(?:[.,][0-9]+)?)\s*([a-zA-Z"]*)\s*$`)
+	shippingDimensionsPattern = regexp.MustCompile(`^\s*([0-9.]+)x([0-9.]+)x([0-9.]+),([0-9.]+)\s*$`)
+)
+
+// Conversion factors to grams and centimeters
+var (
+	weightUnits = map[string]float64{"": 1, "g": 1, "gr": 1, "kg": 1000, "mg": 0.001, "lb": 453.59237, "oz": 28.349523}
+	lengthUnits = map[string]float64{"": 1, "cm": 1, "mm": 0.1, "m": 100, "in": 2.54, "\"": 2.54, "ft": 30.48}
+)
+
+// mapPackageDimensions resolves each measure from the SELLER_PACKAGE_* attributes, then from the shipping
+// dimensions string ("HxWxL,weight" in cm and g), then from the shop defaults and last from the Jopit defaults.
+// metadata tells where the values came from, for the transform metadata.
+func mapPackageDimensions(meliItem dto.MeliItemResponse, shopDefaults *models.Dimensions) (models.Dimensions, map[string]string) {
+	shipping, hasShipping := parseShippingDimensions(meliItem.Shipping.Dimensions)
+
+	defaults := models.Dimensions{
+		Weight: defaultPackageWeight,
+		Length: defaultPackageLength,
+		Height: defaultPackageHeight,
+		Width:  defaultPackageWidth,
+	}
+	defaultsOrigin := "jopit"
+	if shopDefaults != nil {
+		defaults = *shopDefaults
+		defaultsOrigin = "shop"
+	}
+
+	defaulted := make([]string, 0)
+	resolve := func(field string, attributeID string, units map[string]float64, shippingValue int, defaultValue int) int {
+		if value, ok := parseNumberUnitAttribute(meliItem.Attributes, attributeID, units); ok {
+			return value
+		}
+		if hasShipping && shippingValue > 0 {
+			return shippingValue
+		}
+		defaulted = append(defaulted, field)
+		return defaultValue
+	}
+
+	dimensions := models.Dimensions{
+		Weight: resolve("weight", "SELLER_PACKAGE_WEIGHT", weightUnits, shipping.Weight, defaults.Weight),
+		Length: resolve("length", "SELLER_PACKAGE_LENGTH", lengthUnits, shipping.Length, defaults.Length),
+		Height: resolve("height", "SELLER_PACKAGE_HEIGHT", lengthUnits, shipping.Height, defaults.Height),
+		Width:  resolve("width", "SELLER_PACKAGE_WIDTH", lengthUnits, shipping.Width, defaults.Width),
+	}
+
+	metadata := map[string]string{"package_dimensions": PackageDimensionsSource}
+	if len(defaulted) > 0 {
+		metadata["package_dimensions"] = PackageDimensionsPartial
+		if len(defaulted) == 4 {
+			metadata["package_dimensions"] = PackageDimensionsDefaulted
+		}
+		metadata["package_dimensions_defaulted"] = strings.Join(defaulted, ",")
+		metadata["package_dimensions_defaults"] = defaultsOrigin
+	}
+
+	return dimensions, metadata
+}
+
+// parseNumberUnitAttribute reads a number_unit attribute converted with units, from its struct or from its value name
+func parseNumberUnitAttribute(attributes []dto.MeliAttribute, attributeID string, units map[string]float64) (int, bool) {
+	for _, attr := range attributes {
+		if attr.ID != attributeID {
+			continue
+		}
+
+		if number, unit, ok := numberUnitStruct(attr.ValueStruct); ok {
+			return convertMeasure(number, unit, units)
+		}
+		for _, value := range attr.Values {
+			if number, unit, ok := numberUnitStruct(value.Struct); ok {
+				return convertMeasure(number, unit, units)
+			}
+		}
+
+		matches := numberUnitPattern.FindStringSubmatch(attr.ValueName)
+		if matches == nil {
+			return 0, false
+		}
+		number, err := strconv.ParseFloat(strings.Replace(matches[1], ",", ".", 1), 64)
+		if err != nil {
+			return 0, false
+		}
+		return convertMeasure(number, matches[2], units)
+	}
+
+	return 0, false
+}
+
+func numberUnitStruct(value interface{}) (float64, string, bool) {
+	fields, ok := value.(map[string]interface{})
+	if !ok {
+		return 0, "", false
+	}
+
+	number, ok := fields["number"].(float64)
+	if !ok {
+		return 0, "", false
+	}
+
+	unit, _ := fields["unit"].(string)
+	return number, unit, true
+}
+
+// convertMeasure rounds up, a package quoted smaller than it is gets rejected by the carrier
+func convertMeasure(number float64, unit string, units map[string]float64) (int, bool) {
+	factor, ok := units[strings.ToLower(unit)]
+	if !ok || number <= 0 {
+		return 0, false
+	}
+
+	return int(math.Ceil(number*factor - 1e-9)), true
+}
+
+// parseShippingDimensions reads MercadoLibre's "HxWxL,weight" shipping dimensions, in centimeters and grams
+func parseShippingDimensions(value interface{}) (models.Dimensions, bool) {
+	text, ok := value.(string)
+	if !ok {
+		return models.Dimensions{}, false
+	}
+
+	matches := shippingDimensionsPattern.FindStringSubmatch(text)
+	if matches == nil {
+		return models.Dimensions{}, false
+	}
+
+	measures := make([]int, 4)
+	for i, match := range matches[1:] {
+		number, err := strconv.ParseFloat(match, 64)
+		if err != nil {
+			return models.Dimensions{}, false
+		}
+		measures[i] = int(math.Ceil(number))
+	}
+
+	return models.Dimensions{Height: measures[0], Width: measures[1], Length: measures[2], Weight: measures[3]}, true
+}
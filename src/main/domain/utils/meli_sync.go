@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"time"
+
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models/dto"
+)
+
+// FilterUpdatedMeliItems splits the extracted items into the ones changed since the last sync and the unchanged count
+func FilterUpdatedMeliItems(meliItems []dto.MeliItemResponse, synced map[string]string) ([]dto.MeliItemResponse, int) {
+	updated := make([]dto.MeliItemResponse, 0, len(meliItems))
+	skipped := 0
+
+	for _, meliItem := range meliItems {
+		if IsMeliItemUpdated(meliItem.LastUpdated, synced[meliItem.ID]) {
+			updated = append(updated, meliItem)
+			continue
+		}
+		skipped++
+	}
+
+	return updated, skipped
+}
+
+// IsMeliItemUpdated reports whether the item's last_updated is newer than the one recorded at the last sync
+func IsMeliItemUpdated(lastUpdated string, syncedLastUpdated string) bool {
+	if syncedLastUpdated == "" || lastUpdated == "" {
+		return true
+	}
+
+	current, err := time.Parse(time.RFC3339, lastUpdated)
+	if err != nil {
+		return lastUpdated != syncedLastUpdated
+	}
+
+	synced, err := time.Parse(time.RFC3339, syncedLastUpdated)
+	if err != nil {
+		return lastUpdated != syncedLastUpdated
+	}
+
+	return current.After(synced)
+}
+
+// MergeSyncedItems builds the next cursor items: loaded items take their new last_updated, the rest keep the
+// previous value so failed or skipped items are evaluated again. Items no longer in the catalog are dropped.
+func MergeSyncedItems(meliItems []dto.MeliItemResponse, synced map[string]string, loadedIDs []string) map[string]string {
+	loaded := make(map[string]bool, len(loadedIDs))
+	for _, id := range loadedIDs {
+		loaded[id] = true
+	}
+
+	merged := make(map[string]string, len(meliItems))
+	for _, meliItem := range meliItems {
+		if loaded[meliItem.ID] {
+			merged[meliItem.ID] = meliItem.LastUpdated
+			continue
+		}
+		if previous, ok := synced[meliItem.ID]; ok {
+			merged[meliItem.ID] = previous
+		}
+	}
+
+	return merged
+}
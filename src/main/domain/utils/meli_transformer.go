@@ -2,49 +2,99 @@ package utils
 
 import (
 	"fmt"
+	"html"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/jopitnow/jopit-api-etl/src/main/domain/models"
 	"github.com/jopitnow/jopit-api-etl/src/main/domain/models/dto"
-	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-// TransformMeliItemToJopitItem converts a MercadoLibre item to Jopit format
+// MeliTransformConfig is what transforming a MercadoLibre item needs besides the item, the same for a whole run
+type MeliTransformConfig struct {
+	ShopID   string
+	UserID   string
+	BatchID  string
+	Settings models.EtlSettings
+	// CategoryMappings are the shop's own mappings followed by the global ones
+	CategoryMappings []models.CategoryMapping
+	// FxRates convert prices to Settings.BaseCurrency, nil leaves them in the item's currency
+	FxRates FxRateProvider
+	// SiteID is the seller's MercadoLibre site, used for items that don't say which site they are listed on
+	SiteID string
+}
+
+// TransformMeliItemToJopitItem converts a MercadoLibre item to Jopit format, warnings report the data
+// that could not be mapped and was left empty or defaulted
 func TransformMeliItemToJopitItem(
 	meliItem dto.MeliItemResponse,
-	shopID string,
-	userID string,
-	batchID string,
 	sizeChart *dto.MeliSizeChartResponse,
-) models.Item {
+	config MeliTransformConfig,
+) (models.Item, []models.TransformWarning) {
+	warnings := make([]models.TransformWarning, 0)
+	dimensions, dimensionsMetadata := mapPackageDimensions(meliItem, config.Settings.DefaultDimensions)
+
+	category, mapped := mapCategory(meliItem, config.CategoryMappings)
+	if !mapped {
+		warnings = append(warnings, models.TransformWarning{
+			ExternalID: meliItem.ID,
+			Title:      meliItem.Title,
+			Code:       models.TransformWarningUnmappedCategory,
+			Message:    fmt.Sprintf("no Jopit category mapped for MercadoLibre category %s (domain %s), left without category", meliItem.CategoryID, meliItem.DomainID),
+		})
+	}
+
+	variants, unknownColors := mapVariants(meliItem, NewColorResolver(config.Settings.ColorHexes))
+	for _, color := range unknownColors {
+		warnings = append(warnings, models.TransformWarning{
+			ExternalID: meliItem.ID,
+			Title:      meliItem.Title,
+			Code:       models.TransformWarningUnknownColor,
+			Message:    fmt.Sprintf("no hex code known for color %q, add it to the shop's etl settings", color),
+		})
+	}
 
 	item := models.Item{
-		ID:          primitive.NewObjectID().Hex(),
-		ShopID:      shopID,
-		UserID:      userID,
+		ID:          ItemID(config.ShopID, models.MeliSourceType, meliItem.ID),
+		ShopID:      config.ShopID,
+		UserID:      config.UserID,
 		Name:        meliItem.Title,
 		Description: extractDescription(meliItem),
 		Status:      mapStatus(meliItem.Status),
-		Category:    mapCategory(meliItem.CategoryID, meliItem.DomainID),
-		Delivery:    mapDelivery(meliItem.Shipping),
+		Category:    category,
+		Delivery:    models.Delivery{Dimensions: dimensions},
 		Attributes:  extractAttributes(meliItem.Attributes, meliItem.Condition, meliItem.SaleTerms),
-		Variants:    mapVariants(meliItem.Variations, meliItem.Pictures),
-		Price:       mapPrice(meliItem, shopID),
+		Variants:    variants,
+		Price:       mapPrice(meliItem, config),
 		Source: &models.Source{
-			SourceType:        "meli",
+			SourceType:        models.MeliSourceType,
 			ExternalID:        meliItem.ID,
-			ExternalSKU:       extractExternalSKU(meliItem.Variations),
-			BatchID:           batchID,
+			ExternalSKU:       extractExternalSKU(meliItem),
+			BatchID:           config.BatchID,
 			ImportedAt:        time.Now(),
 			EtlVersion:        "1.0.0",
-			TransformMetadata: mapTransformMetadata(meliItem),
+			TransformMetadata: mapTransformMetadata(meliItem, config),
 		},
 	}
 
+	for key, value := range dimensionsMetadata {
+		item.Source.TransformMetadata[key] = value
+	}
+
+	if !convertToBaseCurrency(&item, config) {
+		warnings = append(warnings, models.TransformWarning{
+			ExternalID: meliItem.ID,
+			Title:      meliItem.Title,
+			Code:       models.TransformWarningMissingFxRate,
+			Message:    fmt.Sprintf("no exchange rate from %s to %s, prices kept in %s", item.Price.Currency.ID, config.Settings.BaseCurrency, item.Price.Currency.ID),
+		})
+	}
+
 	// Map size guide if available
 	if sizeChart != nil {
-		item.SizeGuide = mapSizeGuide(*sizeChart)
+		item.SizeGuide = MapSizeGuide(*sizeChart)
 		// Store SIZE_GRID_ID in SizeGuide
 		sizeGridID := ExtractSizeChartID(meliItem.Attributes)
 		if sizeGridID != "" {
@@ -53,7 +103,7 @@ func TransformMeliItemToJopitItem(
 	}
 
 	item.ValidateEmptySlices()
-	return item
+	return item, warnings
 }
 
 // ExtractAttributeValue extracts a specific attribute value by ID
@@ -71,8 +121,17 @@ func ExtractAttributeValue(attributes []dto.MeliAttribute, attributeID string) s
 	return ""
 }
 
-// extractDescription builds description from attributes
+// extractDescription uses the seller's description, items without one get "BRAND - Title"
 func extractDescription(meliItem dto.MeliItemResponse) string {
+	if meliItem.Description != nil {
+		if description := CleanDescription(meliItem.Description.PlainText); description != "" {
+			return description
+		}
+		if description := CleanDescription(meliItem.Description.Text); description != "" {
+			return description
+		}
+	}
+
 	brand := ExtractAttributeValue(meliItem.Attributes, "BRAND")
 	if brand != "" {
 		return fmt.Sprintf("%s - %s", brand, meliItem.Title)
@@ -80,6 +139,32 @@ func extractDescription(meliItem dto.MeliItemResponse) string {
 	return meliItem.Title
 }
 
+var (
+	descriptionLineBreaks = regexp.MustCompile(`(?i)<br\s*/?>|</(p|div|li|h[1-6]|tr)>`)
+	descriptionListItems  = regexp.MustCompile(`(?i)<li[^>]*>`)
+	descriptionTags       = regexp.MustCompile(`<[^>]*>`)
+	descriptionSpaces     = regexp.MustCompile(`[ \t\f\v\x{00a0}]+`)
+	descriptionBlankLines = regexp.MustCompile(`\n{3,}`)
+)
+
+// CleanDescription turns a plain text or HTML description into plain text: tags are removed keeping
+// line breaks, entities are decoded and runs of spaces and blank lines are collapsed
+func CleanDescription(text string) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = descriptionLineBreaks.ReplaceAllString(text, "\n")
+	text = descriptionListItems.ReplaceAllString(text, "- ")
+	text = descriptionTags.ReplaceAllString(text, "")
+	text = html.UnescapeString(text)
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(descriptionSpaces.ReplaceAllString(line, " "))
+	}
+	text = strings.Join(lines, "\n")
+
+	return strings.TrimSpace(descriptionBlankLines.ReplaceAllString(text, "\n\n"))
+}
+
 // mapStatus converts MercadoLibre status to Jopit status
 func mapStatus(meliStatus string) string {
 	switch meliStatus {
@@ -94,37 +179,46 @@ func mapStatus(meliStatus string) string {
 	}
 }
 
-// mapCategory maps MercadoLibre category to Jopit category
-func mapCategory(categoryID string, domainID string) models.ItemCategory {
-	// For now, create a generic category based on domain
-	// TODO: Implement proper category mapping from configuration
-	categoryName := "Ropa"
-	if strings.Contains(domainID, "SHOE") || strings.Contains(domainID, "FOOTWEAR") {
-		categoryName = "Calzado"
-	} else if strings.Contains(domainID, "ACCESSORIE") {
-		categoryName = "Accesorios"
+// ResolveCategoryMapping finds the mapping of a MercadoLibre category. Shop mappings win over global ones and,
+// within a scope, a mapping of the category wins over one of its domain. Domains are the same on every site,
+// so a mapping of the domain on another site (MLA-SNEAKERS for MLU-SNEAKERS) is used when the site has none.
+func ResolveCategoryMapping(mappings []models.CategoryMapping, categoryID string, domainID string) (models.CategoryMapping, bool) {
+	for _, global := range []bool{false, true} {
+		for _, mapping := range mappings {
+			if mapping.IsGlobal() == global && mapping.MeliCategoryID != "" && mapping.MeliCategoryID == categoryID {
+				return mapping, true
+			}
+		}
+		for _, mapping := range mappings {
+			if mapping.IsGlobal() == global && mapping.MeliDomainID != "" && mapping.MeliDomainID == domainID {
+				return mapping, true
+			}
+		}
+		for _, mapping := range mappings {
+			if mapping.IsGlobal() == global && mapping.MeliDomainID != "" && sameMeliDomain(mapping.MeliDomainID, domainID) {
+				return mapping, true
+			}
+		}
 	}
 
-	cat := models.ItemCategory{
-		ID:   primitive.NewObjectID().Hex(),
-		Name: categoryName,
-	}
-	cat.SetIDs()
-	return cat
+	return models.CategoryMapping{}, false
 }
 
-// mapDelivery converts shipping info to delivery format
-func mapDelivery(shipping dto.MeliShipping) models.Delivery {
-	// Default dimensions if not provided
-	return models.Delivery{
-		Fragile: false,
-		Dimensions: models.Dimensions{
-			Weight: 500, // 500g default
-			Length: 30,  // 30cm default
-			Height: 5,   // 5cm default
-			Width:  25,  // 25cm default
-		},
+// sameMeliDomain tells whether two domain ids name the same domain, whatever their site
+func sameMeliDomain(a string, b string) bool {
+	_, nameA, okA := strings.Cut(a, "-")
+	_, nameB, okB := strings.Cut(b, "-")
+	return okA && okB && nameA != "" && nameA == nameB
+}
+
+// mapCategory maps the MercadoLibre category to its configured Jopit category. Unmapped categories are left
+// empty, reported by mapped being false, so no item lands in a category Jopit does not have.
+func mapCategory(meliItem dto.MeliItemResponse, mappings []models.CategoryMapping) (category models.ItemCategory, mapped bool) {
+	if mapping, ok := ResolveCategoryMapping(mappings, meliItem.CategoryID, meliItem.DomainID); ok {
+		return mapping.ToItemCategory(), true
 	}
+
+	return models.ItemCategory{}, false
 }
 
 // extractAttributes extracts product attributes as struct and MercadoLibre-specific attributes
@@ -215,73 +309,122 @@ func extractAttributes(attributes []dto.MeliAttribute, condition string, saleTer
 	}
 }
 
-// mapVariants converts MercadoLibre variations to Jopit variants
-func mapVariants(variations []dto.MeliVariation, pictures []dto.MeliPicture) []models.Variant {
-	if len(variations) == 0 {
-		// No variations, create a single variant with all images
-		return []models.Variant{
-			{
-				ColorID:   "default",
-				ColorName: "Default",
-				ColorHex:  "#000000",
-				IsMain:    true,
-				Images:    extractImageURLs(pictures),
-				SizeStock: []models.SizeStock{},
-			},
+// mapVariants converts MercadoLibre variations to Jopit variants, unknownColors are the color names no hex was
+// found for. Items without variations get a single variant in the item's own color, if it has one.
+func mapVariants(meliItem dto.MeliItemResponse, colors ColorResolver) (variants []models.Variant, unknownColors []string) {
+	if len(meliItem.Variations) == 0 {
+		variant := models.Variant{
+			ColorID:   "default",
+			ColorName: "Default",
+			IsMain:    true,
+			Images:    extractImageURLs(meliItem.Pictures),
+			SizeStock: []models.SizeStock{},
+		}
+
+		if attr, ok := findColorAttribute(meliItem.Attributes); ok {
+			variant.ColorID, variant.ColorName = colorIDAndName(attr)
+			hex, known := colors.Resolve(attr)
+			if !known {
+				unknownColors = append(unknownColors, variant.ColorName)
+			}
+			variant.ColorHex = hex
 		}
+
+		return []models.Variant{variant}, unknownColors
 	}
 
-	// Group variations by color
-	colorMap := make(map[string]*models.Variant)
+	// Group variations by color, in the order MercadoLibre lists them so re-imports produce the same variants
+	variants = make([]models.Variant, 0)
+	colorIndex := make(map[string]int)
 
-	for _, variation := range variations {
+	for _, variation := range meliItem.Variations {
 		colorID, colorName := extractColorFromVariation(variation)
 		sizeLabel := extractSizeFromVariation(variation)
 
 		// Get or create variant for this color
-		variant, exists := colorMap[colorID]
+		index, exists := colorIndex[colorID]
 		if !exists {
-			variant = &models.Variant{
+			variant := models.Variant{
 				ColorID:   colorID,
 				ColorName: colorName,
-				ColorHex:  "#000000", // TODO: Map color names to hex
-				IsMain:    len(colorMap) == 0,
-				Images:    mapVariationImages(variation, pictures),
+				IsMain:    len(variants) == 0,
+				Images:    mapVariationImages(variation, meliItem.Pictures),
 				SizeStock: []models.SizeStock{},
 			}
-			colorMap[colorID] = variant
+
+			if attr, ok := findColorAttribute(variation.AttributeCombinations); ok {
+				hex, known := colors.Resolve(attr)
+				if !known {
+					unknownColors = append(unknownColors, colorName)
+				}
+				variant.ColorHex = hex
+			}
+
+			index = len(variants)
+			colorIndex[colorID] = index
+			variants = append(variants, variant)
 		}
+		variant := &variants[index]
 
 		// Add size stock
 		if sizeLabel != "" {
-			variant.SizeStock = append(variant.SizeStock, models.SizeStock{
+			sizeStock := models.SizeStock{
 				SizeLabel: sizeLabel,
 				Stock:     variation.AvailableQuantity,
-				SKU:       variation.UserProductID,
-			})
+				SKU:       extractVariationSKU(variation),
+			}
+			if variation.InventoryID != nil {
+				sizeStock.InventoryID = *variation.InventoryID
+			}
+			// Variations priced like the item leave the price to the item
+			if variation.Price > 0 && variation.Price != meliItem.Price {
+				price := variation.Price
+				sizeStock.Price = &price
+			}
+			variant.SizeStock = append(variant.SizeStock, sizeStock)
 		}
 	}
 
-	// Convert map to slice
-	variants := make([]models.Variant, 0, len(colorMap))
-	for _, variant := range colorMap {
-		variants = append(variants, *variant)
-	}
-
-	return variants
+	return variants, unknownColors
 }
 
 // extractColorFromVariation extracts color info from variation attributes
 func extractColorFromVariation(variation dto.MeliVariation) (string, string) {
-	for _, attr := range variation.AttributeCombinations {
-		if attr.ID == "COLOR" || attr.ID == "MAIN_COLOR" {
-			if attr.ValueID != nil && *attr.ValueID != "" {
-				return *attr.ValueID, attr.ValueName
+	if attr, ok := findColorAttribute(variation.AttributeCombinations); ok {
+		return colorIDAndName(attr)
+	}
+	return "default", "Default"
+}
+
+// findColorAttribute returns the COLOR attribute, or MAIN_COLOR for items that only have that one
+func findColorAttribute(attributes []dto.MeliAttribute) (dto.MeliAttribute, bool) {
+	for _, id := range []string{"COLOR", "MAIN_COLOR"} {
+		for _, attr := range attributes {
+			if attr.ID == id && (attr.ValueName != "" || attr.ValueID != nil) {
+				return attr, true
 			}
-			return attr.ValueName, attr.ValueName
 		}
 	}
-	return "default", "Default"
+	return dto.MeliAttribute{}, false
+}
+
+func colorIDAndName(attr dto.MeliAttribute) (string, string) {
+	if attr.ValueID != nil && *attr.ValueID != "" {
+		return *attr.ValueID, attr.ValueName
+	}
+	return attr.ValueName, attr.ValueName
+}
+
+// extractVariationSKU returns the seller's SKU of a variation, from its SELLER_SKU attribute or else the legacy
+// seller_custom_field
+func extractVariationSKU(variation dto.MeliVariation) string {
+	if sku := strings.TrimSpace(ExtractAttributeValue(variation.Attributes, "SELLER_SKU")); sku != "" {
+		return sku
+	}
+	if variation.SellerCustomField != nil {
+		return strings.TrimSpace(*variation.SellerCustomField)
+	}
+	return ""
 }
 
 // extractSizeFromVariation extracts size label from variation
@@ -328,71 +471,21 @@ func extractImageURLs(pictures []dto.MeliPicture) []models.Image {
 	return urls
 }
 
-// mapSizeGuide converts MercadoLibre size chart to Jopit format
-func mapSizeGuide(sizeChart dto.MeliSizeChartResponse) *models.SizeGuide {
-	sizes := make([]models.Size, 0, len(sizeChart.Rows))
-
-	for _, row := range sizeChart.Rows {
-		size := models.Size{}
-
-		for _, attr := range row.Attributes {
-			switch attr.ID {
-			case "SIZE":
-				if len(attr.Values) > 0 {
-					size.SizeEquivalence = attr.Values[0].Name
-				}
-			case "CHEST_CIRCUMFERENCE_FROM":
-				if len(attr.Values) > 0 && attr.Values[0].Struct != nil {
-					if ms := attr.Values[0].Struct; ms != nil {
-						size.ChestCircumference = int(ms.Number)
-					}
-				}
-			case "WAIST_CIRCUMFERENCE_FROM":
-				if len(attr.Values) > 0 && attr.Values[0].Struct != nil {
-					if ms := attr.Values[0].Struct; ms != nil {
-						size.WaistCircumference = int(ms.Number)
-					}
-				}
-			case "HIP_CIRCUMFERENCE_FROM":
-				if len(attr.Values) > 0 && attr.Values[0].Struct != nil {
-					if ms := attr.Values[0].Struct; ms != nil {
-						size.HipCircumference = int(ms.Number)
-					}
-				}
-			}
-		}
-
-		if size.SizeEquivalence != "" {
-			sizes = append(sizes, size)
-		}
-	}
-
-	// Determine body part from measure type
-	bodyPart := "upper" // default
-	if sizeChart.MeasureType == "BODY_MEASURE" {
-		bodyPart = "upper"
+// extractExternalSKU returns the seller's SKU of the item, items only carrying SKUs per variation take the first one
+func extractExternalSKU(meliItem dto.MeliItemResponse) string {
+	if sku := strings.TrimSpace(ExtractAttributeValue(meliItem.Attributes, "SELLER_SKU")); sku != "" {
+		return sku
 	}
-
-	return &models.SizeGuide{
-		Type:              "standard",
-		BodyPart:          bodyPart,
-		HasMeasurements:   len(sizes) > 0,
-		IsOneSize:         len(sizes) == 1,
-		MeasurementSource: "mercadolibre",
-		Sizes:             sizes,
-	}
-}
-
-// extractExternalSKU extracts the main SKU from variations
-func extractExternalSKU(variations []dto.MeliVariation) string {
-	if len(variations) > 0 && variations[0].UserProductID != "" {
-		return variations[0].UserProductID
+	for _, variation := range meliItem.Variations {
+		if sku := extractVariationSKU(variation); sku != "" {
+			return sku
+		}
 	}
 	return ""
 }
 
 // mapTransformMetadata creates metadata about the ETL transformation
-func mapTransformMetadata(meliItem dto.MeliItemResponse) map[string]string {
+func mapTransformMetadata(meliItem dto.MeliItemResponse, config MeliTransformConfig) map[string]string {
 	metadata := make(map[string]string)
 
 	metadata["original_domain_id"] = meliItem.DomainID
@@ -402,90 +495,153 @@ func mapTransformMetadata(meliItem dto.MeliItemResponse) map[string]string {
 	metadata["variations_count"] = fmt.Sprintf("%d", len(meliItem.Variations))
 	metadata["pictures_count"] = fmt.Sprintf("%d", len(meliItem.Pictures))
 	metadata["attributes_count"] = fmt.Sprintf("%d", len(meliItem.Attributes))
+	metadata["original_site_id"] = meliSiteID(meliItem, config)
+	metadata["original_currency"] = meliCurrencyID(meliItem, config)
+	metadata["original_amount"] = formatAmount(meliItem.Price)
 
 	return metadata
 }
 
-// mapPrice extracts price information from MercadoLibre item
-func mapPrice(meliItem dto.MeliItemResponse, shopID string) models.Price {
-	return models.Price{
-		ShopID:   shopID,
+// mapPrice extracts price information from MercadoLibre item. Amount is what the item sells at, a discounted
+// item keeps its regular price as ListAmount along with the promotion behind the discount.
+func mapPrice(meliItem dto.MeliItemResponse, config MeliTransformConfig) models.Price {
+	price := models.Price{
+		ShopID:   config.ShopID,
 		Amount:   meliItem.Price,
-		Currency: mapCurrency(meliItem.CurrencyID),
+		Currency: LookupCurrency(meliCurrencyID(meliItem, config)),
+	}
+
+	listAmount := meliListPrice(meliItem)
+	if listAmount == 0 {
+		return price
 	}
+
+	price.ListAmount = &listAmount
+	price.Promotion = meliPromotion(meliItem)
+	return price
 }
 
-// mapCurrency creates a Currency struct from MercadoLibre currency ID
-func mapCurrency(currencyID string) models.Currency {
-	// Map MercadoLibre currency codes to Jopit currency info
-	currencyMap := map[string]models.Currency{
-		"ARS": {
-			ID:               "ARS",
-			Symbol:           "$",
-			DecimalDivider:   ",",
-			ThousandsDivider: ".",
-		},
-		// "USD": {
-		// 	ID:               "USD",
-		// 	Symbol:           "$",
-		// 	DecimalDivider:   ".",
-		// 	ThousandsDivider: ",",
-		// },
-		// "BRL": {
-		// 	ID:               "BRL",
-		// 	Symbol:           "R$",
-		// 	DecimalDivider:   ",",
-		// 	ThousandsDivider: ".",
-		// },
-		// "MXN": {
-		// 	ID:               "MXN",
-		// 	Symbol:           "$",
-		// 	DecimalDivider:   ".",
-		// 	ThousandsDivider: ",",
-		// },
-		// "CLP": {
-		// 	ID:               "CLP",
-		// 	Symbol:           "$",
-		// 	DecimalDivider:   ",",
-		// 	ThousandsDivider: ".",
-		// },
-		// "UYU": {
-		// 	ID:               "UYU",
-		// 	Symbol:           "$U",
-		// 	DecimalDivider:   ",",
-		// 	ThousandsDivider: ".",
-		// },
-		// "PEN": {
-		// 	ID:               "PEN",
-		// 	Symbol:           "S/",
-		// 	DecimalDivider:   ".",
-		// 	ThousandsDivider: ",",
-		// },
-		// "COP": {
-		// 	ID:               "COP",
-		// 	Symbol:           "$",
-		// 	DecimalDivider:   ",",
-		// 	ThousandsDivider: ".",
-		// },
-		// "EUR": {
-		// 	ID:               "EUR",
-		// 	Symbol:           "€",
-		// 	DecimalDivider:   ",",
-		// 	ThousandsDivider: ".",
-		// },
-	}
-
-	if currency, exists := currencyMap[currencyID]; exists {
-		return currency
-	}
-
-	// Default fallback for unknown currencies
-	return models.Currency{
-		ID:               currencyID,
-		Symbol:           currencyID,
-		DecimalDivider:   ".",
-		ThousandsDivider: ",",
+// IsMeliItemDiscounted tells whether the item sells below its regular price, only those have a promotion
+func IsMeliItemDiscounted(meliItem dto.MeliItemResponse) bool {
+	return meliListPrice(meliItem) > 0
+}
+
+// meliListPrice is the regular price of a discounted item, 0 when the item sells at its regular price
+func meliListPrice(meliItem dto.MeliItemResponse) float64 {
+	if meliItem.OriginalPrice != nil && *meliItem.OriginalPrice > meliItem.Price {
+		return *meliItem.OriginalPrice
+	}
+	if meliItem.BasePrice > meliItem.Price {
+		return meliItem.BasePrice
 	}
+	return 0
+}
+
+// meliPromotion finds the promotion selling the item at its price, preferring one open to every buyer. Items
+// whose prices weren't fetched fall back to their first deal, without a window.
+func meliPromotion(meliItem dto.MeliItemResponse) *models.Promotion {
+	var restricted *models.Promotion
+
+	for _, salePrice := range meliItem.SalePrices {
+		if salePrice.Type != "promotion" || salePrice.Amount != meliItem.Price {
+			continue
+		}
+
+		promotion := &models.Promotion{
+			ID:       salePrice.ID,
+			Type:     salePrice.Type,
+			StartsAt: parseMeliTime(salePrice.Conditions.StartTime),
+			EndsAt:   parseMeliTime(salePrice.Conditions.EndTime),
+		}
+		if id, ok := salePrice.Metadata["promotion_id"].(string); ok && id != "" {
+			promotion.ID = id
+		}
+		if promotionType, ok := salePrice.Metadata["promotion_type"].(string); ok && promotionType != "" {
+			promotion.Type = promotionType
+		}
+
+		if len(salePrice.Conditions.ContextRestrictions) == 0 {
+			return promotion
+		}
+		if restricted == nil {
+			restricted = promotion
+		}
+	}
+
+	if restricted != nil {
+		return restricted
+	}
+	if len(meliItem.DealIDs) > 0 {
+		return &models.Promotion{ID: meliItem.DealIDs[0], Type: "deal"}
+	}
+	return nil
+}
+
+// parseMeliTime reads an optional MercadoLibre timestamp, nil when missing or malformed
+func parseMeliTime(value *string) *time.Time {
+	if value == nil || *value == "" {
+		return nil
+	}
+	parsed, err := time.Parse(time.RFC3339, *value)
+	if err != nil {
+		return nil
+	}
+	parsed = parsed.UTC()
+	return &parsed
+}
+
+// meliSiteID is the site an item is listed on, the seller's site when the item doesn't say
+func meliSiteID(meliItem dto.MeliItemResponse, config MeliTransformConfig) string {
+	if meliItem.SiteID != "" {
+		return meliItem.SiteID
+	}
+	return config.SiteID
+}
+
+// meliCurrencyID is the currency an item is listed in, its site's currency when the item doesn't say
+func meliCurrencyID(meliItem dto.MeliItemResponse, config MeliTransformConfig) string {
+	if meliItem.CurrencyID != "" {
+		return meliItem.CurrencyID
+	}
+	return SiteCurrency(meliSiteID(meliItem, config))
+}
+
+// convertToBaseCurrency moves the item's prices to the shop's base currency, the rate used is kept in the
+// transform metadata next to the original currency and amount. Without a known rate prices stay as listed.
+func convertToBaseCurrency(item *models.Item, config MeliTransformConfig) bool {
+	from, to := item.Price.Currency.ID, config.Settings.BaseCurrency
+	if to == "" || from == to || config.FxRates == nil {
+		return true
+	}
+
+	rate, ok := config.FxRates.Rate(from, to)
+	if !ok {
+		return false
+	}
+
+	item.Price.Amount = roundAmount(item.Price.Amount*rate, to)
+	if item.Price.ListAmount != nil {
+		item.Source.TransformMetadata["original_list_amount"] = formatAmount(*item.Price.ListAmount)
+		listAmount := roundAmount(*item.Price.ListAmount*rate, to)
+		item.Price.ListAmount = &listAmount
+	}
+	for i := range item.Variants {
+		for j := range item.Variants[i].SizeStock {
+			if price := item.Variants[i].SizeStock[j].Price; price != nil {
+				converted := roundAmount(*price*rate, to)
+				item.Variants[i].SizeStock[j].Price = &converted
+			}
+		}
+	}
+
+	item.Price.Currency = LookupCurrency(to)
+	item.Source.TransformMetadata["fx_rate"] = strconv.FormatFloat(rate, 'f', -1, 64)
+	return true
+}
+
+// formatAmount writes an amount without trailing zeros or exponent
+func formatAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', -1, 64)
 }
 
 // ExtractSizeChartID extracts SIZE_GRID_ID from item attributes
@@ -0,0 +1,101 @@
+package utils
+
+import (
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TokenBucket allows rate calls per second on average with bursts of up to one second worth of calls.
+// A nil bucket never makes callers wait.
+type TokenBucket struct {
+	mu          sync.Mutex
+	rate        float64
+	tokens      float64
+	last        time.Time
+	pausedUntil time.Time
+}
+
+// NewTokenBucket returns a full bucket, or nil (no limit) for a rate that is not positive
+func NewTokenBucket(rate float64) *TokenBucket {
+	if rate <= 0 {
+		return nil
+	}
+	return &TokenBucket{rate: rate, tokens: max(rate, 1), last: time.Now()}
+}
+
+// Reserve takes a token and returns how long the caller must wait before using it
+func (b *TokenBucket) Reserve() time.Duration {
+	if b == nil {
+		return 0
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	b.tokens = min(b.tokens+now.Sub(b.last).Seconds()*b.rate, max(b.rate, 1))
+	b.last = now
+	b.tokens--
+
+	var delay time.Duration
+	if b.tokens < 0 {
+		delay = time.Duration(-b.tokens / b.rate * float64(time.Second))
+	}
+	if paused := b.pausedUntil.Sub(now); paused > delay {
+		delay = paused
+	}
+	return delay
+}
+
+// Pause holds every call of the bucket for delay, e.g. after the remote throttled one of them
+func (b *TokenBucket) Pause(delay time.Duration) {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if until := time.Now().Add(delay); until.After(b.pausedUntil) {
+		b.pausedUntil = until
+	}
+}
+
+// IsRetryableStatus tells whether a call answered with status is worth retrying, 0 stands for no response at all
+func IsRetryableStatus(status int) bool {
+	return status == 0 || status == http.StatusTooManyRequests || status >= http.StatusInternalServerError
+}
+
+// RetryDelay is what the Retry-After header asks for or else a jittered exponential backoff capped at maxDelay.
+// ok is false when Retry-After asks to wait longer than maxDelay, a run is better off failing than hanging.
+func RetryDelay(retryAfter string, now time.Time, attempt int, baseDelay time.Duration, maxDelay time.Duration) (delay time.Duration, ok bool) {
+	if wait, found := ParseRetryAfter(retryAfter, now); found {
+		return wait, wait <= maxDelay
+	}
+
+	backoff := min(baseDelay<<attempt, maxDelay)
+	// Full jitter between half and the whole backoff, so parallel workers don't retry in lockstep
+	return backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1)), true
+}
+
+// ParseRetryAfter reads a Retry-After header, in seconds or as an HTTP date. Dates in the past mean no wait.
+func ParseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		return max(date.Sub(now), 0), true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,364 @@
+package utils
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models/dto"
+)
+
+// meliClothingMeasure is the measure type of charts measuring the garment instead of the body
+const meliClothingMeasure = "CLOTHING_MEASURE"
+
+// measureRegex reads a measure written as a value name, "94 cm", "90-94 cm", "35,5" or "14 a 15 in"
+var measureRegex = regexp.MustCompile(`(?i)(\d+(?:[.,]\d+)?)\s*(?:(?:-|–|a|to)\s*(\d+(?:[.,]\d+)?))?\s*(mm|cm|m|in|inch|inches|pulgadas|")?`)
+
+// sizeChartDomains tells the body part of a chart by its domain, the site prefix removed
+var sizeChartDomains = map[string]string{
+	"SNEAKERS":     models.SizeGuideBodyPartFeet,
+	"SHOES":        models.SizeGuideBodyPartFeet,
+	"BOOTS":        models.SizeGuideBodyPartFeet,
+	"ANKLE_BOOTS":  models.SizeGuideBodyPartFeet,
+	"SANDALS":      models.SizeGuideBodyPartFeet,
+	"FLIP_FLOPS":   models.SizeGuideBodyPartFeet,
+	"SLIPPERS":     models.SizeGuideBodyPartFeet,
+	"LOAFERS":      models.SizeGuideBodyPartFeet,
+	"HIGH_HEELS":   models.SizeGuideBodyPartFeet,
+	"CLOGS":        models.SizeGuideBodyPartFeet,
+	"PANTS":        models.SizeGuideBodyPartLower,
+	"JEANS":        models.SizeGuideBodyPartLower,
+	"SHORTS":       models.SizeGuideBodyPartLower,
+	"BERMUDAS":     models.SizeGuideBodyPartLower,
+	"SKIRTS":       models.SizeGuideBodyPartLower,
+	"LEGGINGS":     models.SizeGuideBodyPartLower,
+	"SWEATPANTS":   models.SizeGuideBodyPartLower,
+	"UNDERPANTS":   models.SizeGuideBodyPartLower,
+	"BOXER_SHORTS": models.SizeGuideBodyPartLower,
+	"DRESSES":      models.SizeGuideBodyPartFull,
+	"JUMPSUITS":    models.SizeGuideBodyPartFull,
+	"OVERALLS":     models.SizeGuideBodyPartFull,
+	"ROMPERS":      models.SizeGuideBodyPartFull,
+	"BODYSUITS":    models.SizeGuideBodyPartFull,
+	"SWIMSUITS":    models.SizeGuideBodyPartFull,
+	"PAJAMAS":      models.SizeGuideBodyPartFull,
+	"SPORTS_SUITS": models.SizeGuideBodyPartFull,
+	"TRACKSUITS":   models.SizeGuideBodyPartFull,
+}
+
+// oneSizeLabels are the labels of a size that fits everyone, normalized
+var oneSizeLabels = map[string]bool{
+	"u":           true,
+	"unico":       true,
+	"talle unico": true,
+	"tu":          true,
+	"one size":    true,
+	"os":          true,
+}
+
+// measureRange is a row measure, either bound can be missing
+type measureRange struct {
+	from *float64
+	to   *float64
+}
+
+func (r measureRange) value() (float64, bool) {
+	switch {
+	case r.from != nil && r.to != nil:
+		return (*r.from + *r.to) / 2, true
+	case r.from != nil:
+		return *r.from, true
+	case r.to != nil:
+		return *r.to, true
+	}
+	return 0, false
+}
+
+// MapSizeGuide converts a MercadoLibre size chart to a Jopit size guide. Ranges (_FROM and _TO) keep their
+// midpoint, measures are converted to centimeters and circumferences of a chart measuring the garment are
+// halved into the garment's flat widths.
+func MapSizeGuide(sizeChart dto.MeliSizeChartResponse) *models.SizeGuide {
+	bodyPart := sizeChartBodyPart(sizeChart)
+	women := isWomenSizeChart(sizeChart)
+	labelSystem := sizeChartLabelSystem(sizeChart, bodyPart)
+
+	sizes := make([]models.Size, 0, len(sizeChart.Rows))
+	hasMeasurements := false
+
+	for _, row := range sizeChart.Rows {
+		size, measured := mapSizeChartRow(row, sizeChart.MeasureType, women, labelSystem)
+		if size.SizeEquivalence == "" {
+			continue
+		}
+		hasMeasurements = hasMeasurements || measured
+		sizes = append(sizes, size)
+	}
+
+	guideType := strings.ToLower(sizeChart.Type)
+	if guideType == "" {
+		guideType = "standard"
+	}
+
+	return &models.SizeGuide{
+		Type:              guideType,
+		BodyPart:          bodyPart,
+		HasMeasurements:   hasMeasurements,
+		IsOneSize:         len(sizes) == 1 || (len(sizes) > 0 && oneSizeLabels[normalizeName(sizes[0].SizeEquivalence)]),
+		MeasurementSource: "mercadolibre",
+		Sizes:             sizes,
+	}
+}
+
+// mapSizeChartRow returns the size of a row, measured is false when the row only carries labels
+func mapSizeChartRow(row dto.MeliChartRow, measureType string, women bool, labelSystem string) (size models.Size, measured bool) {
+	measures := map[string]measureRange{}
+	conversions := map[string]int{}
+
+	for _, attr := range row.Attributes {
+		if len(attr.Values) == 0 {
+			continue
+		}
+		value := attr.Values[0]
+
+		switch attr.ID {
+		case "SIZE":
+			size.SizeEquivalence = strings.TrimSpace(value.Name)
+			continue
+		case "FILTRABLE_SIZE", "MANUFACTURER_SIZE":
+			if size.SizeEquivalence == "" {
+				size.SizeEquivalence = strings.TrimSpace(value.Name)
+			}
+			continue
+		case "AR_SIZE", "ARG_SIZE", "US_SIZE", "USA_SIZE", "EU_SIZE", "EUR_SIZE":
+			if number, ok := parseSizeNumber(value.Name); ok {
+				conversions[strings.SplitN(attr.ID, "_", 2)[0]] = number
+			}
+			continue
+		}
+
+		base, bound := attr.ID, ""
+		if strings.HasSuffix(base, "_FROM") {
+			base, bound = strings.TrimSuffix(base, "_FROM"), "from"
+		} else if strings.HasSuffix(base, "_TO") {
+			base, bound = strings.TrimSuffix(base, "_TO"), "to"
+		}
+
+		from, to, ok := chartValueCentimeters(value)
+		if !ok {
+			continue
+		}
+
+		current := measures[base]
+		switch bound {
+		case "from":
+			current.from = &from
+		case "to":
+			current.to = &to
+		default:
+			current.from, current.to = &from, &to
+		}
+		measures[base] = current
+	}
+
+	for base, measure := range measures {
+		value, ok := measure.value()
+		if !ok {
+			continue
+		}
+		if setSizeMeasure(&size, base, value, measureType) {
+			measured = true
+		}
+	}
+
+	// Labels written in a size system fill that conversion when the chart has no column for it
+	if labelSystem != "" {
+		if _, ok := conversions[labelSystem]; !ok {
+			if number, ok := parseSizeNumber(size.SizeEquivalence); ok {
+				conversions[labelSystem] = number
+			}
+		}
+	}
+
+	for system, number := range conversions {
+		switch system {
+		case "AR", "ARG":
+			if women {
+				size.SizeArgWomen = number
+			} else {
+				size.SizeArgMen = number
+			}
+		case "US", "USA":
+			size.SizeUSA = number
+		case "EU", "EUR":
+			size.SizeEUR = number
+		}
+	}
+
+	return size, measured
+}
+
+// setSizeMeasure sets the size field a chart measure maps to, in centimeters. It returns false for measures
+// Jopit has no field for.
+func setSizeMeasure(size *models.Size, base string, centimeters float64, measureType string) bool {
+	garment := measureType == meliClothingMeasure
+
+	switch base {
+	case "CHEST_CIRCUMFERENCE", "BUST_CIRCUMFERENCE":
+		if garment {
+			size.GarmentChestWidth = intPointer(centimeters / 2)
+		} else {
+			size.ChestCircumference = roundCentimeters(centimeters)
+		}
+	case "WAIST_CIRCUMFERENCE":
+		if garment {
+			size.GarmentWaistWidth = intPointer(centimeters / 2)
+		} else {
+			size.WaistCircumference = roundCentimeters(centimeters)
+		}
+	case "HIP_CIRCUMFERENCE":
+		if garment {
+			size.GarmentHipWidth = intPointer(centimeters / 2)
+		} else {
+			size.HipCircumference = roundCentimeters(centimeters)
+		}
+	case "HEIGHT", "BODY_HEIGHT":
+		size.Height = roundCentimeters(centimeters)
+	case "FOOT_LENGTH":
+		size.FootLengthCm = roundCentimeters(centimeters)
+	case "GARMENT_CHEST_WIDTH", "CHEST_WIDTH":
+		size.GarmentChestWidth = intPointer(centimeters)
+	case "GARMENT_WAIST_WIDTH", "WAIST_WIDTH":
+		size.GarmentWaistWidth = intPointer(centimeters)
+	case "GARMENT_HIP_WIDTH", "HIP_WIDTH":
+		size.GarmentHipWidth = intPointer(centimeters)
+	case "GARMENT_SHOULDER_WIDTH", "SHOULDER_WIDTH":
+		size.GarmentShoulderWidth = intPointer(centimeters)
+	case "GARMENT_LENGTH", "LENGTH":
+		size.GarmentLength = intPointer(centimeters)
+	default:
+		return false
+	}
+	return true
+}
+
+// chartValueCentimeters reads a chart value in centimeters, from its struct or else from its name. Names
+// can hold a range, a single measure is returned as both bounds.
+func chartValueCentimeters(value dto.MeliChartAttributeValue) (from float64, to float64, ok bool) {
+	if value.Struct != nil {
+		centimeters := toCentimeters(value.Struct.Number, value.Struct.Unit)
+		return centimeters, centimeters, true
+	}
+
+	match := measureRegex.FindStringSubmatch(value.Name)
+	if match == nil {
+		return 0, 0, false
+	}
+
+	from, err := strconv.ParseFloat(strings.ReplaceAll(match[1], ",", "."), 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	to = from
+	if match[2] != "" {
+		if parsed, err := strconv.ParseFloat(strings.ReplaceAll(match[2], ",", "."), 64); err == nil {
+			to = parsed
+		}
+	}
+
+	return toCentimeters(from, match[3]), toCentimeters(to, match[3]), true
+}
+
+// toCentimeters converts a measure, values without a known unit are taken as centimeters
+func toCentimeters(number float64, unit string) float64 {
+	switch strings.ToLower(strings.TrimSpace(unit)) {
+	case "mm":
+		return number / 10
+	case "m":
+		return number * 100
+	case "in", "inch", "inches", "pulgadas", `"`:
+		return number * 2.54
+	default:
+		return number
+	}
+}
+
+func roundCentimeters(centimeters float64) int {
+	return int(math.Round(centimeters))
+}
+
+func intPointer(centimeters float64) *int {
+	value := roundCentimeters(centimeters)
+	return &value
+}
+
+// parseSizeNumber reads a numeric size label, half sizes round up
+func parseSizeNumber(label string) (int, bool) {
+	number, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(label), ",", "."), 64)
+	if err != nil || number <= 0 {
+		return 0, false
+	}
+	return int(math.Round(number)), true
+}
+
+// sizeChartBodyPart tells the body part by the chart's domain and, for domains it doesn't know, by what the
+// rows measure
+func sizeChartBodyPart(sizeChart dto.MeliSizeChartResponse) string {
+	domain := sizeChart.DomainID
+	if index := strings.Index(domain, "-"); index >= 0 {
+		domain = domain[index+1:]
+	}
+	if bodyPart, ok := sizeChartDomains[domain]; ok {
+		return bodyPart
+	}
+
+	measures := map[string]bool{}
+	for _, row := range sizeChart.Rows {
+		for _, attr := range row.Attributes {
+			measures[strings.TrimSuffix(strings.TrimSuffix(attr.ID, "_FROM"), "_TO")] = true
+		}
+	}
+
+	switch {
+	case measures["FOOT_LENGTH"]:
+		return models.SizeGuideBodyPartFeet
+	case measures["CHEST_CIRCUMFERENCE"] || measures["BUST_CIRCUMFERENCE"] || measures["CHEST_WIDTH"] || measures["GARMENT_CHEST_WIDTH"]:
+		return models.SizeGuideBodyPartUpper
+	case measures["WAIST_CIRCUMFERENCE"] || measures["HIP_CIRCUMFERENCE"]:
+		return models.SizeGuideBodyPartLower
+	}
+	return models.SizeGuideBodyPartUpper
+}
+
+// isWomenSizeChart tells by the chart's GENDER attribute, charts without one are read as men's
+func isWomenSizeChart(sizeChart dto.MeliSizeChartResponse) bool {
+	for _, attr := range sizeChart.Attributes {
+		if attr.ID != "GENDER" || len(attr.Values) == 0 {
+			continue
+		}
+		switch normalizeName(attr.Values[0].Name) {
+		case "mujer", "femenino", "female", "woman", "women", "nena", "nina", "feminino":
+			return true
+		}
+	}
+	return false
+}
+
+// sizeChartLabelSystem is the size system the SIZE labels are written in: the chart's ConversionType or, for
+// footwear, the system of the chart's site
+func sizeChartLabelSystem(sizeChart dto.MeliSizeChartResponse, bodyPart string) string {
+	if conversion := strings.ToUpper(strings.TrimSpace(sizeChart.ConversionType)); conversion != "" {
+		return conversion
+	}
+
+	if bodyPart != models.SizeGuideBodyPartFeet {
+		return ""
+	}
+
+	// Argentinian shoes are labelled in AR sizes, other sites use systems Jopit has no field for
+	if sizeChart.SiteID == "MLA" {
+		return "AR"
+	}
+	return ""
+}
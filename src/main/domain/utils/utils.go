@@ -52,6 +52,10 @@ func ExtractFromCSV(fileHeader *multipart.FileHeader) ([]map[string]string, apie
 		return nil, apierrors.NewApiError("error reading content of csv file", "internal_server_error", http.StatusInternalServerError, apierrors.CauseList{})
 	}
 
+	if len(rows) == 0 {
+		return nil, apierrors.NewApiError("csv file is empty", "bad_request", http.StatusBadRequest, apierrors.CauseList{})
+	}
+
 	headers := rows[0]
 	var records []map[string]string
 	for _, row := range rows[1:] {
@@ -65,13 +69,28 @@ func ExtractFromCSV(fileHeader *multipart.FileHeader) ([]map[string]string, apie
 }
 
 // --- Transform with category mapping ---
-func Transform(records []map[string]string, config models.CompanyLayout, userID string) (string, []models.Item) {
-	var items []models.Item
+// Transform maps the records with the company layout, records without an external id or a name are reported as failed
+func Transform(records []map[string]string, config models.CompanyLayout, userID string, sourceType string) (string, []models.Item, []models.FailedItem) {
+	items := make([]models.Item, 0, len(records))
+	failedItems := make([]models.FailedItem, 0)
 
-	batchID := generateBatchID(6)
+	batchID := NewBatchID(sourceType)
 	now := time.Now()
 
-	for _, rec := range records {
+	for i, rec := range records {
+		externalID := rec[layoutField(config, "id")]
+		name := rec[layoutField(config, "name")]
+
+		if externalID == "" || name == "" {
+			failedItems = append(failedItems, models.FailedItem{
+				ExternalID:   externalID,
+				Title:        name,
+				FailureStage: models.EtlStageTransform,
+				ErrorMessage: fmt.Sprintf("record %d: id and name are required", i+1),
+			})
+			continue
+		}
+
 		externalCat := rec[config.CategoryMap["category_name"]]
 		mappedID := config.CategoryMap[externalCat]
 		mappedName := canonicalCategories[mappedID]
@@ -80,23 +99,22 @@ func Transform(records []map[string]string, config models.CompanyLayout, userID
 			ID:   mappedID,
 			Name: mappedName,
 		}
-		cat.SetIDs()
 
 		item := models.Item{
-			ID:          rec[config.CategoryMap["id"]],
+			ID:          ItemID(config.ShopID, sourceType, externalID),
 			ShopID:      config.ShopID,
 			UserID:      userID,
-			Name:        rec[config.CategoryMap["name"]],
-			Description: rec[config.CategoryMap["description"]],
+			Name:        name,
+			Description: rec[layoutField(config, "description")],
 			Status:      "active",
 			Category:    cat,
 			Delivery: models.Delivery{
-				Fragile: strings.ToLower(rec[config.CategoryMap["fragile"]]) == "true",
+				Fragile: strings.ToLower(rec[layoutField(config, "fragile")]) == "true",
 				Dimensions: models.Dimensions{
-					Weight: parseInt(rec[config.CategoryMap["weight"]]),
-					Length: parseInt(rec[config.CategoryMap["length"]]),
-					Height: parseInt(rec[config.CategoryMap["height"]]),
-					Width:  parseInt(rec[config.CategoryMap["width"]]),
+					Weight: parseInt(rec[layoutField(config, "weight")]),
+					Length: parseInt(rec[layoutField(config, "length")]),
+					Height: parseInt(rec[layoutField(config, "height")]),
+					Width:  parseInt(rec[layoutField(config, "width")]),
 				},
 			},
 			Variants: []models.Variant{
@@ -114,7 +132,7 @@ func Transform(records []map[string]string, config models.CompanyLayout, userID
 			},
 			Price: models.Price{
 				ShopID: config.ShopID,
-				Amount: float64(parseInt(rec[config.CategoryMap["price"]])),
+				Amount: float64(parseInt(rec[layoutField(config, "price")])),
 				Currency: models.Currency{
 					ID:               "ARS",
 					Symbol:           "$",
@@ -123,20 +141,28 @@ func Transform(records []map[string]string, config models.CompanyLayout, userID
 				},
 			},
 			Source: &models.Source{
-				SourceType: "csv",
-				ExternalID: rec[config.CategoryMap["id"]],
+				SourceType: sourceType,
+				ExternalID: externalID,
 				BatchID:    batchID,
 				ImportedAt: now,
 				EtlVersion: "1.0.0",
 				TransformMetadata: map[string]string{
-					"import_source": "csv",
+					"import_source": sourceType,
 					"config_id":     config.ID,
 				},
 			},
 		}
 		items = append(items, item)
 	}
-	return batchID, items
+	return batchID, items, failedItems
+}
+
+// layoutField returns the source column of an item field, layouts saved before field_map existed keep it in category_map
+func layoutField(config models.CompanyLayout, field string) string {
+	if column, ok := config.ItemMap[field]; ok {
+		return column
+	}
+	return config.CategoryMap[field]
 }
 
 func parseInt(s string) int {
@@ -0,0 +1,23 @@
+package clients
+
+import (
+	"context"
+
+	"github.com/jopitnow/go-jopit-toolkit/goutils/apierrors"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models"
+)
+
+type FetchApiClientMock struct {
+	HandleFetchAPI func(ctx context.Context, layout models.CompanyLayout) ([]map[string]string, apierrors.ApiError)
+}
+
+func NewFetchApiClientMock() FetchApiClientMock {
+	return FetchApiClientMock{}
+}
+
+func (mock FetchApiClientMock) FetchAPI(ctx context.Context, layout models.CompanyLayout) ([]map[string]string, apierrors.ApiError) {
+	if mock.HandleFetchAPI != nil {
+		return mock.HandleFetchAPI(ctx, layout)
+	}
+	return []map[string]string{}, nil
+}
@@ -0,0 +1,72 @@
+package clients
+
+import (
+	"context"
+
+	"github.com/jopitnow/go-jopit-toolkit/goutils/apierrors"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models/dto"
+)
+
+type ItemsClientMock struct {
+	HandleBulkCreateItems     func(ctx context.Context, items []models.Item) apierrors.ApiError
+	HandleBulkUpsertItems     func(ctx context.Context, items []models.Item) (*dto.BulkUpsertResponse, apierrors.ApiError)
+	HandleBulkDeleteItems     func(ctx context.Context, shopID string, batchID string) apierrors.ApiError
+	HandleBulkDeleteItemsByID func(ctx context.Context, shopID string, itemIDs []string) apierrors.ApiError
+	HandleGetItemsByShopID    func(ctx context.Context, shopID string) ([]models.Item, apierrors.ApiError)
+	HandleGetItemBySource     func(ctx context.Context, shopID string, sourceType string, externalID string) (*models.Item, apierrors.ApiError)
+	HandleGetCategories       func(ctx context.Context) ([]models.ItemCategory, apierrors.ApiError)
+}
+
+func NewItemsClientMock() ItemsClientMock {
+	return ItemsClientMock{}
+}
+
+func (mock ItemsClientMock) BulkCreateItems(ctx context.Context, items []models.Item) apierrors.ApiError {
+	if mock.HandleBulkCreateItems != nil {
+		return mock.HandleBulkCreateItems(ctx, items)
+	}
+	return nil
+}
+
+func (mock ItemsClientMock) BulkUpsertItems(ctx context.Context, items []models.Item) (*dto.BulkUpsertResponse, apierrors.ApiError) {
+	if mock.HandleBulkUpsertItems != nil {
+		return mock.HandleBulkUpsertItems(ctx, items)
+	}
+	return &dto.BulkUpsertResponse{}, nil
+}
+
+func (mock ItemsClientMock) BulkDeleteItems(ctx context.Context, shopID string, batchID string) apierrors.ApiError {
+	if mock.HandleBulkDeleteItems != nil {
+		return mock.HandleBulkDeleteItems(ctx, shopID, batchID)
+	}
+	return nil
+}
+
+func (mock ItemsClientMock) BulkDeleteItemsByID(ctx context.Context, shopID string, itemIDs []string) apierrors.ApiError {
+	if mock.HandleBulkDeleteItemsByID != nil {
+		return mock.HandleBulkDeleteItemsByID(ctx, shopID, itemIDs)
+	}
+	return nil
+}
+
+func (mock ItemsClientMock) GetItemsByShopID(ctx context.Context, shopID string) ([]models.Item, apierrors.ApiError) {
+	if mock.HandleGetItemsByShopID != nil {
+		return mock.HandleGetItemsByShopID(ctx, shopID)
+	}
+	return []models.Item{}, nil
+}
+
+func (mock ItemsClientMock) GetItemBySource(ctx context.Context, shopID string, sourceType string, externalID string) (*models.Item, apierrors.ApiError) {
+	if mock.HandleGetItemBySource != nil {
+		return mock.HandleGetItemBySource(ctx, shopID, sourceType, externalID)
+	}
+	return nil, nil
+}
+
+func (mock ItemsClientMock) GetCategories(ctx context.Context) ([]models.ItemCategory, apierrors.ApiError) {
+	if mock.HandleGetCategories != nil {
+		return mock.HandleGetCategories(ctx)
+	}
+	return []models.ItemCategory{}, nil
+}
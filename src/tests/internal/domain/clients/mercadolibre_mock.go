@@ -0,0 +1,95 @@
+package clients
+
+import (
+	"context"
+
+	"github.com/jopitnow/go-jopit-toolkit/goutils/apierrors"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models/dto"
+)
+
+type MercadoLibreClientMock struct {
+	HandleGetItem                    func(ctx context.Context, meliItemID string, accessToken string) (dto.MeliItemResponse, apierrors.ApiError)
+	HandleGetItems                   func(ctx context.Context, meliItemIDs []string, accessToken string) ([]dto.MeliMultigetResult, apierrors.ApiError)
+	HandleGetUserItems               func(ctx context.Context, meliUserID int64, accessToken string) (dto.MeliUserItemsSearchResponse, apierrors.ApiError)
+	HandleGetUserItemsWithPagination func(ctx context.Context, meliUserID int64, accessToken string, offset int, limit int) (dto.MeliUserItemsSearchResponse, apierrors.ApiError)
+	HandleScanUserItems              func(ctx context.Context, meliUserID int64, accessToken string, scrollID string, limit int) (dto.MeliUserItemsSearchResponse, apierrors.ApiError)
+	HandleSearchItems                func(ctx context.Context, filters dto.MercadoLibreSearchFilters, accessToken string) (dto.MeliSearchResponse, apierrors.ApiError)
+	HandleGetSizeChart               func(ctx context.Context, chartID string, accessToken string) (dto.MeliSizeChartResponse, apierrors.ApiError)
+	HandleGetItemDescription         func(ctx context.Context, meliItemID string, accessToken string) (dto.MeliItemDescription, apierrors.ApiError)
+	HandleGetItemPrices              func(ctx context.Context, meliItemID string, accessToken string) (dto.MeliItemPricesResponse, apierrors.ApiError)
+	HandleGetCategory                func(ctx context.Context, categoryID string) (dto.MeliCategoryResponse, apierrors.ApiError)
+}
+
+func NewMercadoLibreClientMock() MercadoLibreClientMock {
+	return MercadoLibreClientMock{}
+}
+
+func (mock MercadoLibreClientMock) GetItem(ctx context.Context, meliItemID string, accessToken string) (dto.MeliItemResponse, apierrors.ApiError) {
+	if mock.HandleGetItem != nil {
+		return mock.HandleGetItem(ctx, meliItemID, accessToken)
+	}
+	return dto.MeliItemResponse{ID: meliItemID}, nil
+}
+
+func (mock MercadoLibreClientMock) GetItems(ctx context.Context, meliItemIDs []string, accessToken string) ([]dto.MeliMultigetResult, apierrors.ApiError) {
+	if mock.HandleGetItems != nil {
+		return mock.HandleGetItems(ctx, meliItemIDs, accessToken)
+	}
+	return []dto.MeliMultigetResult{}, nil
+}
+
+func (mock MercadoLibreClientMock) GetUserItems(ctx context.Context, meliUserID int64, accessToken string) (dto.MeliUserItemsSearchResponse, apierrors.ApiError) {
+	if mock.HandleGetUserItems != nil {
+		return mock.HandleGetUserItems(ctx, meliUserID, accessToken)
+	}
+	return dto.MeliUserItemsSearchResponse{}, nil
+}
+
+func (mock MercadoLibreClientMock) GetUserItemsWithPagination(ctx context.Context, meliUserID int64, accessToken string, offset int, limit int) (dto.MeliUserItemsSearchResponse, apierrors.ApiError) {
+	if mock.HandleGetUserItemsWithPagination != nil {
+		return mock.HandleGetUserItemsWithPagination(ctx, meliUserID, accessToken, offset, limit)
+	}
+	return dto.MeliUserItemsSearchResponse{}, nil
+}
+
+func (mock MercadoLibreClientMock) ScanUserItems(ctx context.Context, meliUserID int64, accessToken string, scrollID string, limit int) (dto.MeliUserItemsSearchResponse, apierrors.ApiError) {
+	if mock.HandleScanUserItems != nil {
+		return mock.HandleScanUserItems(ctx, meliUserID, accessToken, scrollID, limit)
+	}
+	return dto.MeliUserItemsSearchResponse{}, nil
+}
+
+func (mock MercadoLibreClientMock) SearchItems(ctx context.Context, filters dto.MercadoLibreSearchFilters, accessToken string) (dto.MeliSearchResponse, apierrors.ApiError) {
+	if mock.HandleSearchItems != nil {
+		return mock.HandleSearchItems(ctx, filters, accessToken)
+	}
+	return dto.MeliSearchResponse{}, nil
+}
+
+func (mock MercadoLibreClientMock) GetSizeChart(ctx context.Context, chartID string, accessToken string) (dto.MeliSizeChartResponse, apierrors.ApiError) {
+	if mock.HandleGetSizeChart != nil {
+		return mock.HandleGetSizeChart(ctx, chartID, accessToken)
+	}
+	return dto.MeliSizeChartResponse{}, nil
+}
+
+func (mock MercadoLibreClientMock) GetItemDescription(ctx context.Context, meliItemID string, accessToken string) (dto.MeliItemDescription, apierrors.ApiError) {
+	if mock.HandleGetItemDescription != nil {
+		return mock.HandleGetItemDescription(ctx, meliItemID, accessToken)
+	}
+	return dto.MeliItemDescription{}, nil
+}
+
+func (mock MercadoLibreClientMock) GetItemPrices(ctx context.Context, meliItemID string, accessToken string) (dto.MeliItemPricesResponse, apierrors.ApiError) {
+	if mock.HandleGetItemPrices != nil {
+		return mock.HandleGetItemPrices(ctx, meliItemID, accessToken)
+	}
+	return dto.MeliItemPricesResponse{}, nil
+}
+
+func (mock MercadoLibreClientMock) GetCategory(ctx context.Context, categoryID string) (dto.MeliCategoryResponse, apierrors.ApiError) {
+	if mock.HandleGetCategory != nil {
+		return mock.HandleGetCategory(ctx, categoryID)
+	}
+	return dto.MeliCategoryResponse{}, nil
+}
@@ -0,0 +1,48 @@
+package clients
+
+import (
+	"context"
+
+	"github.com/jopitnow/go-jopit-toolkit/goutils/apierrors"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models/dto"
+)
+
+type MercadoLibreAuthClientMock struct {
+	HandleGetOAuthURL             func(ctx context.Context, siteID string) (models.MercadoLibreURL, apierrors.ApiError)
+	HandleGetOAuthCredentials     func(ctx context.Context, code string) (dto.MercadoLibreAuthResponse, apierrors.ApiError)
+	HandleRefreshOAuthCredentials func(ctx context.Context, refreshToken string) (dto.MercadoLibreAuthResponse, apierrors.ApiError)
+	HandleGetCurrentUser          func(ctx context.Context, accessToken string) (dto.MercadoLibreUserResponse, apierrors.ApiError)
+}
+
+func NewMercadoLibreAuthClientMock() MercadoLibreAuthClientMock {
+	return MercadoLibreAuthClientMock{}
+}
+
+func (mock MercadoLibreAuthClientMock) GetOAuthURL(ctx context.Context, siteID string) (models.MercadoLibreURL, apierrors.ApiError) {
+	if mock.HandleGetOAuthURL != nil {
+		return mock.HandleGetOAuthURL(ctx, siteID)
+	}
+	return models.MercadoLibreURL{}, nil
+}
+
+func (mock MercadoLibreAuthClientMock) GetOAuthCredentials(ctx context.Context, code string) (dto.MercadoLibreAuthResponse, apierrors.ApiError) {
+	if mock.HandleGetOAuthCredentials != nil {
+		return mock.HandleGetOAuthCredentials(ctx, code)
+	}
+	return dto.MercadoLibreAuthResponse{}, nil
+}
+
+func (mock MercadoLibreAuthClientMock) RefreshOAuthCredentials(ctx context.Context, refreshToken string) (dto.MercadoLibreAuthResponse, apierrors.ApiError) {
+	if mock.HandleRefreshOAuthCredentials != nil {
+		return mock.HandleRefreshOAuthCredentials(ctx, refreshToken)
+	}
+	return dto.MercadoLibreAuthResponse{}, nil
+}
+
+func (mock MercadoLibreAuthClientMock) GetCurrentUser(ctx context.Context, accessToken string) (dto.MercadoLibreUserResponse, apierrors.ApiError) {
+	if mock.HandleGetCurrentUser != nil {
+		return mock.HandleGetCurrentUser(ctx, accessToken)
+	}
+	return dto.MercadoLibreUserResponse{}, nil
+}
@@ -14,6 +14,8 @@ type PriceClientMock struct {
 	HandleModifyPrice      func(ctx context.Context, price *models.Price) apierrors.ApiError
 	HandleGetItemsPrices   func(ctx context.Context, itemsIDs []string) (models.Prices, apierrors.ApiError)
 	HandleDeletePrice      func(ctx context.Context, priceID string) apierrors.ApiError
+	HandleBulkUpsertPrices func(ctx context.Context, prices []models.Price) apierrors.ApiError
+	HandleBulkDeletePrices func(ctx context.Context, itemIDs []string) apierrors.ApiError
 }
 
 func NewPriceClientMock() PriceClientMock {
@@ -54,3 +56,17 @@ func (mock PriceClientMock) DeletePrice(ctx context.Context, priceID string) api
 	}
 	return nil
 }
+
+func (mock PriceClientMock) BulkUpsertPrices(ctx context.Context, prices []models.Price) apierrors.ApiError {
+	if mock.HandleBulkUpsertPrices != nil {
+		return mock.HandleBulkUpsertPrices(ctx, prices)
+	}
+	return nil
+}
+
+func (mock PriceClientMock) BulkDeletePrices(ctx context.Context, itemIDs []string) apierrors.ApiError {
+	if mock.HandleBulkDeletePrices != nil {
+		return mock.HandleBulkDeletePrices(ctx, itemIDs)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package artifacts
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/repositories"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeleteOlderThan(t *testing.T) {
+	ctx := context.Background()
+	basePath := t.TempDir()
+	store := repositories.NewLocalArtifactStore(basePath)
+
+	require.Nil(t, store.Save(ctx, "shop-1/old/snapshot.json", []byte("{}")))
+	require.Nil(t, store.Save(ctx, "shop-1/old/failed.json", []byte("[]")))
+	require.Nil(t, store.Save(ctx, "shop-1/mixed/snapshot.json", []byte("{}")))
+	require.Nil(t, store.Save(ctx, "shop-1/mixed/failed.json", []byte("[]")))
+	require.Nil(t, store.Save(ctx, "shop-2/new/snapshot.json", []byte("{}")))
+
+	old := time.Now().Add(-48 * time.Hour)
+	for _, path := range []string{"shop-1/old/snapshot.json", "shop-1/old/failed.json", "shop-1/mixed/snapshot.json"} {
+		require.NoError(t, os.Chtimes(filepath.Join(basePath, filepath.FromSlash(path)), old, old))
+	}
+
+	deleted, err := store.DeleteOlderThan(ctx, time.Now().Add(-24*time.Hour))
+	require.Nil(t, err)
+	assert.Equal(t, 1, deleted)
+
+	_, _, err = store.Open(ctx, "shop-1/old/snapshot.json")
+	require.NotNil(t, err)
+	assert.Equal(t, 404, err.Status())
+
+	// A batch with a recent artifact is kept whole
+	mixed, _, err := store.Open(ctx, "shop-1/mixed/snapshot.json")
+	require.Nil(t, err)
+	mixed.Close()
+
+	recent, _, err := store.Open(ctx, "shop-2/new/snapshot.json")
+	require.Nil(t, err)
+	recent.Close()
+}
+
+func TestDeleteOlderThanWithoutArtifacts(t *testing.T) {
+	store := repositories.NewLocalArtifactStore(filepath.Join(t.TempDir(), "missing"))
+
+	deleted, err := store.DeleteOlderThan(context.Background(), time.Now())
+	require.Nil(t, err)
+	assert.Equal(t, 0, deleted)
+}
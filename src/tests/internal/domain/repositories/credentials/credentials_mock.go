@@ -0,0 +1,63 @@
+package credentials
+
+import (
+	"context"
+
+	"github.com/jopitnow/go-jopit-toolkit/goutils/apierrors"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models"
+)
+
+type RepositoryMock struct {
+	HandleGetCredentialsByShopID     func(ctx context.Context, shopID string) (models.MercadoLibreCredential, apierrors.ApiError)
+	HandleGetCredentialsByUserID     func(ctx context.Context, userID string) (models.MercadoLibreCredential, apierrors.ApiError)
+	HandleGetCredentialsByMeliUserID func(ctx context.Context, meliUserID int64) (models.MercadoLibreCredential, apierrors.ApiError)
+	HandleCreateCredentials          func(ctx context.Context, credentials models.MercadoLibreCredential) apierrors.ApiError
+	HandleUpdateCredentials          func(ctx context.Context, credentials models.MercadoLibreCredential) apierrors.ApiError
+	HandleDeleteCredentials          func(ctx context.Context, userID string) apierrors.ApiError
+}
+
+func NewMercadoLibreCredentialsRepositoryMock() RepositoryMock {
+	return RepositoryMock{}
+}
+
+func (mock RepositoryMock) GetCredentialsByShopID(ctx context.Context, shopID string) (models.MercadoLibreCredential, apierrors.ApiError) {
+	if mock.HandleGetCredentialsByShopID != nil {
+		return mock.HandleGetCredentialsByShopID(ctx, shopID)
+	}
+	return models.MercadoLibreCredential{}, nil
+}
+
+func (mock RepositoryMock) GetCredentialsByUserID(ctx context.Context, userID string) (models.MercadoLibreCredential, apierrors.ApiError) {
+	if mock.HandleGetCredentialsByUserID != nil {
+		return mock.HandleGetCredentialsByUserID(ctx, userID)
+	}
+	return models.MercadoLibreCredential{}, nil
+}
+
+func (mock RepositoryMock) GetCredentialsByMeliUserID(ctx context.Context, meliUserID int64) (models.MercadoLibreCredential, apierrors.ApiError) {
+	if mock.HandleGetCredentialsByMeliUserID != nil {
+		return mock.HandleGetCredentialsByMeliUserID(ctx, meliUserID)
+	}
+	return models.MercadoLibreCredential{}, nil
+}
+
+func (mock RepositoryMock) CreateCredentials(ctx context.Context, credentials models.MercadoLibreCredential) apierrors.ApiError {
+	if mock.HandleCreateCredentials != nil {
+		return mock.HandleCreateCredentials(ctx, credentials)
+	}
+	return nil
+}
+
+func (mock RepositoryMock) UpdateCredentials(ctx context.Context, credentials models.MercadoLibreCredential) apierrors.ApiError {
+	if mock.HandleUpdateCredentials != nil {
+		return mock.HandleUpdateCredentials(ctx, credentials)
+	}
+	return nil
+}
+
+func (mock RepositoryMock) DeleteCredentials(ctx context.Context, userID string) apierrors.ApiError {
+	if mock.HandleDeleteCredentials != nil {
+		return mock.HandleDeleteCredentials(ctx, userID)
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package cursors
+
+import (
+	"context"
+
+	"github.com/jopitnow/go-jopit-toolkit/goutils/apierrors"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models"
+)
+
+type RepositoryMock struct {
+	HandleGetByShopID func(ctx context.Context, shopID string, sourceType string) (models.SyncCursor, apierrors.ApiError)
+	HandleSave        func(ctx context.Context, cursor models.SyncCursor) apierrors.ApiError
+}
+
+func NewSyncCursorsRepositoryMock() RepositoryMock {
+	return RepositoryMock{}
+}
+
+func (mock RepositoryMock) GetByShopID(ctx context.Context, shopID string, sourceType string) (models.SyncCursor, apierrors.ApiError) {
+	if mock.HandleGetByShopID != nil {
+		return mock.HandleGetByShopID(ctx, shopID, sourceType)
+	}
+	return models.SyncCursor{}, apierrors.NewApiError("sync cursor not found", "not_found", 404, apierrors.CauseList{})
+}
+
+func (mock RepositoryMock) Save(ctx context.Context, cursor models.SyncCursor) apierrors.ApiError {
+	if mock.HandleSave != nil {
+		return mock.HandleSave(ctx, cursor)
+	}
+	return nil
+}
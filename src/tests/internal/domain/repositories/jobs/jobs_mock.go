@@ -0,0 +1,72 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/jopitnow/go-jopit-toolkit/goutils/apierrors"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models"
+)
+
+type RepositoryMock struct {
+	HandleGet           func(ctx context.Context, jobID string) (models.EtlJob, apierrors.ApiError)
+	HandleGetByUserID   func(ctx context.Context, userID string) ([]models.EtlJob, apierrors.ApiError)
+	HandleCreate        func(ctx context.Context, job models.EtlJob) (string, apierrors.ApiError)
+	HandleUpdate        func(ctx context.Context, job models.EtlJob) apierrors.ApiError
+	HandleRequestCancel func(ctx context.Context, jobID string) apierrors.ApiError
+	HandleHeartbeat     func(ctx context.Context, jobIDs []string) apierrors.ApiError
+	HandleFailStale     func(ctx context.Context, before time.Time, reason string) (int64, apierrors.ApiError)
+}
+
+func NewJobsRepositoryMock() RepositoryMock {
+	return RepositoryMock{}
+}
+
+func (mock RepositoryMock) Get(ctx context.Context, jobID string) (models.EtlJob, apierrors.ApiError) {
+	if mock.HandleGet != nil {
+		return mock.HandleGet(ctx, jobID)
+	}
+	return models.EtlJob{}, nil
+}
+
+func (mock RepositoryMock) GetByUserID(ctx context.Context, userID string) ([]models.EtlJob, apierrors.ApiError) {
+	if mock.HandleGetByUserID != nil {
+		return mock.HandleGetByUserID(ctx, userID)
+	}
+	return []models.EtlJob{}, nil
+}
+
+func (mock RepositoryMock) Create(ctx context.Context, job models.EtlJob) (string, apierrors.ApiError) {
+	if mock.HandleCreate != nil {
+		return mock.HandleCreate(ctx, job)
+	}
+	return "job-1", nil
+}
+
+func (mock RepositoryMock) Update(ctx context.Context, job models.EtlJob) apierrors.ApiError {
+	if mock.HandleUpdate != nil {
+		return mock.HandleUpdate(ctx, job)
+	}
+	return nil
+}
+
+func (mock RepositoryMock) RequestCancel(ctx context.Context, jobID string) apierrors.ApiError {
+	if mock.HandleRequestCancel != nil {
+		return mock.HandleRequestCancel(ctx, jobID)
+	}
+	return nil
+}
+
+func (mock RepositoryMock) Heartbeat(ctx context.Context, jobIDs []string) apierrors.ApiError {
+	if mock.HandleHeartbeat != nil {
+		return mock.HandleHeartbeat(ctx, jobIDs)
+	}
+	return nil
+}
+
+func (mock RepositoryMock) FailStale(ctx context.Context, before time.Time, reason string) (int64, apierrors.ApiError) {
+	if mock.HandleFailStale != nil {
+		return mock.HandleFailStale(ctx, before, reason)
+	}
+	return 0, nil
+}
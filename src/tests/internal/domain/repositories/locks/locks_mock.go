@@ -0,0 +1,32 @@
+package locks
+
+import (
+	"context"
+	"time"
+
+	"github.com/jopitnow/go-jopit-toolkit/goutils/apierrors"
+)
+
+// RepositoryMock grants every lock that is not held
+type RepositoryMock struct {
+	Held map[string]string
+}
+
+func NewLocksRepositoryMock() *RepositoryMock {
+	return &RepositoryMock{Held: map[string]string{}}
+}
+
+func (mock *RepositoryMock) Acquire(ctx context.Context, key string, owner string, ttl time.Duration) (bool, apierrors.ApiError) {
+	if current, ok := mock.Held[key]; ok && current != owner {
+		return false, nil
+	}
+	mock.Held[key] = owner
+	return true, nil
+}
+
+func (mock *RepositoryMock) Release(ctx context.Context, key string, owner string) apierrors.ApiError {
+	if mock.Held[key] == owner {
+		delete(mock.Held, key)
+	}
+	return nil
+}
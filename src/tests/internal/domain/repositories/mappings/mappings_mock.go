@@ -0,0 +1,55 @@
+package mappings
+
+import (
+	"context"
+
+	"github.com/jopitnow/go-jopit-toolkit/goutils/apierrors"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models"
+)
+
+type RepositoryMock struct {
+	HandleGet          func(ctx context.Context, mappingID string) (models.CategoryMapping, apierrors.ApiError)
+	HandleGetByShopIDs func(ctx context.Context, shopIDs []string) ([]models.CategoryMapping, apierrors.ApiError)
+	HandleCreate       func(ctx context.Context, mapping models.CategoryMapping) (string, apierrors.ApiError)
+	HandleUpdate       func(ctx context.Context, mapping models.CategoryMapping) apierrors.ApiError
+	HandleDelete       func(ctx context.Context, mappingID string) apierrors.ApiError
+}
+
+func NewCategoryMappingsRepositoryMock() RepositoryMock {
+	return RepositoryMock{}
+}
+
+func (mock RepositoryMock) Get(ctx context.Context, mappingID string) (models.CategoryMapping, apierrors.ApiError) {
+	if mock.HandleGet != nil {
+		return mock.HandleGet(ctx, mappingID)
+	}
+	return models.CategoryMapping{}, nil
+}
+
+func (mock RepositoryMock) GetByShopIDs(ctx context.Context, shopIDs []string) ([]models.CategoryMapping, apierrors.ApiError) {
+	if mock.HandleGetByShopIDs != nil {
+		return mock.HandleGetByShopIDs(ctx, shopIDs)
+	}
+	return []models.CategoryMapping{}, nil
+}
+
+func (mock RepositoryMock) Create(ctx context.Context, mapping models.CategoryMapping) (string, apierrors.ApiError) {
+	if mock.HandleCreate != nil {
+		return mock.HandleCreate(ctx, mapping)
+	}
+	return "mapping-1", nil
+}
+
+func (mock RepositoryMock) Update(ctx context.Context, mapping models.CategoryMapping) apierrors.ApiError {
+	if mock.HandleUpdate != nil {
+		return mock.HandleUpdate(ctx, mapping)
+	}
+	return nil
+}
+
+func (mock RepositoryMock) Delete(ctx context.Context, mappingID string) apierrors.ApiError {
+	if mock.HandleDelete != nil {
+		return mock.HandleDelete(ctx, mappingID)
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package sizecharts
+
+import (
+	"context"
+
+	"github.com/jopitnow/go-jopit-toolkit/goutils/apierrors"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models/dto"
+)
+
+type RepositoryMock struct {
+	HandleGet  func(ctx context.Context, chartID string) (dto.MeliCachedSizeChart, apierrors.ApiError)
+	HandleSave func(ctx context.Context, chart dto.MeliCachedSizeChart) apierrors.ApiError
+}
+
+func NewSizeChartsRepositoryMock() RepositoryMock {
+	return RepositoryMock{}
+}
+
+func (mock RepositoryMock) Get(ctx context.Context, chartID string) (dto.MeliCachedSizeChart, apierrors.ApiError) {
+	if mock.HandleGet != nil {
+		return mock.HandleGet(ctx, chartID)
+	}
+	return dto.MeliCachedSizeChart{}, apierrors.NewApiError("size chart not found", "not_found", 404, apierrors.CauseList{})
+}
+
+func (mock RepositoryMock) Save(ctx context.Context, chart dto.MeliCachedSizeChart) apierrors.ApiError {
+	if mock.HandleSave != nil {
+		return mock.HandleSave(ctx, chart)
+	}
+	return nil
+}
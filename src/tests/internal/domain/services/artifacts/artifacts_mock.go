@@ -0,0 +1,42 @@
+package artifacts
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+
+	"github.com/jopitnow/go-jopit-toolkit/goutils/apierrors"
+)
+
+// ServiceMock keeps the artifacts in memory, by batch and kind
+type ServiceMock struct {
+	Artifacts map[string][]byte
+}
+
+func NewArtifactsServiceMock() *ServiceMock {
+	return &ServiceMock{Artifacts: map[string][]byte{}}
+}
+
+func (mock *ServiceMock) Save(ctx context.Context, shopID string, batchID string, kind string, value interface{}) apierrors.ApiError {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return apierrors.NewApiError(err.Error(), "internal_server_error", 500, apierrors.CauseList{})
+	}
+	mock.Artifacts[batchID+"/"+kind] = raw
+	return nil
+}
+
+func (mock *ServiceMock) Open(ctx context.Context, batchID string, kind string) (io.ReadCloser, int64, apierrors.ApiError) {
+	return nil, 0, apierrors.NewApiError("not implemented", "internal_server_error", 500, apierrors.CauseList{})
+}
+
+func (mock *ServiceMock) Load(ctx context.Context, shopID string, batchID string, kind string, value interface{}) apierrors.ApiError {
+	raw, ok := mock.Artifacts[batchID+"/"+kind]
+	if !ok {
+		return apierrors.NewApiError("artifact not found", "not_found", 404, apierrors.CauseList{})
+	}
+	if err := json.Unmarshal(raw, value); err != nil {
+		return apierrors.NewApiError(err.Error(), "internal_server_error", 500, apierrors.CauseList{})
+	}
+	return nil
+}
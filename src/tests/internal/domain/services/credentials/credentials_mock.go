@@ -0,0 +1,48 @@
+package credentials
+
+import (
+	"context"
+
+	"github.com/jopitnow/go-jopit-toolkit/goutils/apierrors"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models/dto"
+)
+
+type ServiceMock struct {
+	HandleGetCredentialsByUserID     func(ctx context.Context, userID string) (models.MercadoLibreCredential, apierrors.ApiError)
+	HandleGetCredentialsByMeliUserID func(ctx context.Context, meliUserID int64) (models.MercadoLibreCredential, apierrors.ApiError)
+}
+
+func NewMercadoLibreCredentialsServiceMock() ServiceMock {
+	return ServiceMock{}
+}
+
+func (mock ServiceMock) GetCredentialsByShopID(ctx context.Context, shopID string) (models.MercadoLibreCredential, apierrors.ApiError) {
+	return models.MercadoLibreCredential{ShopID: shopID}, nil
+}
+
+func (mock ServiceMock) GetCredentialsByUserID(ctx context.Context, userID string) (models.MercadoLibreCredential, apierrors.ApiError) {
+	if mock.HandleGetCredentialsByUserID != nil {
+		return mock.HandleGetCredentialsByUserID(ctx, userID)
+	}
+	return models.MercadoLibreCredential{UserID: userID}, nil
+}
+
+func (mock ServiceMock) GetCredentialsByMeliUserID(ctx context.Context, meliUserID int64) (models.MercadoLibreCredential, apierrors.ApiError) {
+	if mock.HandleGetCredentialsByMeliUserID != nil {
+		return mock.HandleGetCredentialsByMeliUserID(ctx, meliUserID)
+	}
+	return models.MercadoLibreCredential{UserIDMeli: meliUserID}, nil
+}
+
+func (mock ServiceMock) GetOAuthURL(ctx context.Context, siteID string) (models.MercadoLibreURL, apierrors.ApiError) {
+	return models.MercadoLibreURL{}, nil
+}
+
+func (mock ServiceMock) CreateOAuthCredentials(ctx context.Context, input dto.MercadoLibreAuthRedirectDTO) apierrors.ApiError {
+	return nil
+}
+
+func (mock ServiceMock) DeleteCredentials(ctx context.Context, userID string) apierrors.ApiError {
+	return nil
+}
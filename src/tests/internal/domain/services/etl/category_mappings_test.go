@@ -0,0 +1,56 @@
+package etl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jopitnow/go-jopit-toolkit/goutils/apierrors"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models/dto"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/services"
+	"github.com/jopitnow/jopit-api-etl/src/tests/internal/domain/clients"
+	"github.com/jopitnow/jopit-api-etl/src/tests/internal/domain/repositories/mappings"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	clothingID = "65f1a0c2e4b0a1b2c3d4e5f6"
+	shirtsID   = "65f1a0c2e4b0a1b2c3d4e5f7"
+	shoesID    = "65f1a0c2e4b0a1b2c3d4e5f8"
+)
+
+func TestCreateGlobalCategoryMapping_ValidatesTheJopitCategory(t *testing.T) {
+	itemsClient := clients.ItemsClientMock{
+		HandleGetCategories: func(ctx context.Context) ([]models.ItemCategory, apierrors.ApiError) {
+			return []models.ItemCategory{
+				{ID: clothingID, Name: "Ropa", Subcategory: &models.Subcategory{ID: shirtsID, Name: "Remeras"}},
+			}, nil
+		},
+	}
+
+	tests := []struct {
+		name   string
+		input  dto.CategoryMappingRequest
+		status int
+	}{
+		{name: "category and subcategory", input: dto.CategoryMappingRequest{MeliCategoryID: "MLA1", CategoryID: clothingID, CategoryName: "Ropa", SubcategoryID: shirtsID, SubcategoryName: "Remeras"}},
+		{name: "category only", input: dto.CategoryMappingRequest{MeliCategoryID: "MLA1", CategoryID: clothingID, CategoryName: "Ropa"}},
+		{name: "unknown category", input: dto.CategoryMappingRequest{MeliCategoryID: "MLA1", CategoryID: shoesID, CategoryName: "Calzado"}, status: 400},
+		{name: "subcategory of another category", input: dto.CategoryMappingRequest{MeliCategoryID: "MLA1", CategoryID: clothingID, CategoryName: "Ropa", SubcategoryID: shoesID, SubcategoryName: "Zapatillas"}, status: 400},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := services.NewCategoryMappingsService(mappings.RepositoryMock{}, nil, itemsClient, nil)
+
+			_, err := service.CreateGlobal(context.Background(), tt.input)
+			if tt.status == 0 {
+				assert.Nil(t, err)
+				return
+			}
+			if assert.NotNil(t, err) {
+				assert.Equal(t, tt.status, err.Status())
+			}
+		})
+	}
+}
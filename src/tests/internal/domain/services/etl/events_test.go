@@ -0,0 +1,169 @@
+package etl
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jopitnow/go-jopit-toolkit/goauth"
+	"github.com/jopitnow/go-jopit-toolkit/goutils/apierrors"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models/dto"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/services"
+	"github.com/jopitnow/jopit-api-etl/src/tests/internal/domain/clients"
+	"github.com/jopitnow/jopit-api-etl/src/tests/internal/domain/repositories/jobs"
+	"github.com/jopitnow/jopit-api-etl/src/tests/internal/domain/repositories/locks"
+	"github.com/jopitnow/jopit-api-etl/src/tests/internal/domain/services/artifacts"
+	"github.com/jopitnow/jopit-api-etl/src/tests/internal/domain/services/layouts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubscribe_StreamsTheEventsOfAJobInOrder(t *testing.T) {
+	ctx := context.WithValue(context.Background(), goauth.FirebaseUserID, "user-1")
+
+	// The first job holds the only worker, so the second one is subscribed to before it starts
+	release := make(chan struct{})
+	fetchClient := clients.FetchApiClientMock{
+		HandleFetchAPI: func(ctx context.Context, layout models.CompanyLayout) ([]map[string]string, apierrors.ApiError) {
+			<-release
+			return []map[string]string{{"sku": "A1", "title": "Remera"}, {"sku": "A2", "title": "Buzo"}}, nil
+		},
+	}
+	store := newJobsStore()
+	service := newApiJobsService(store, fetchClient, 1)
+
+	_, err := service.EnqueueScheduled(ctx, models.EtlSchedule{ShopID: "shop-1", UserID: "user-1", Source: models.EtlScheduleSourceApi})
+	require.Nil(t, err)
+	job, err := service.EnqueueScheduled(ctx, models.EtlSchedule{ShopID: "shop-1", UserID: "user-1", Source: models.EtlScheduleSourceApi})
+	require.Nil(t, err)
+
+	_, events, unsubscribe, err := service.Subscribe(ctx, job.ID)
+	require.Nil(t, err)
+	defer unsubscribe()
+	close(release)
+
+	received := make([]string, 0)
+	for event := range untilFinished(t, events) {
+		require.Equal(t, job.ID, event.JobID)
+		switch event.Type {
+		case models.EtlJobEventItem:
+			received = append(received, fmt.Sprintf("%s %s %s %d/%d", event.Stage, event.ExternalID, event.ItemStatus, event.Current, event.Total))
+		case models.EtlJobEventFinished:
+			received = append(received, fmt.Sprintf("finished %s", event.Job.Status))
+		default:
+			received = append(received, fmt.Sprintf("%s %s", event.Type, event.Stage))
+		}
+	}
+
+	assert.Equal(t, []string{
+		"stage extract",
+		"stage transform",
+		"transform A1 transformed 1/2",
+		"transform A2 transformed 2/2",
+		"stage load",
+		"load A1 loaded 1/2",
+		"load A2 loaded 2/2",
+		"finished completed",
+	}, received)
+}
+
+// jobsStore keeps the jobs in memory, so what the jobs service saves is what it reads back
+type jobsStore struct {
+	mu   sync.Mutex
+	jobs map[string]models.EtlJob
+}
+
+func newJobsStore() *jobsStore {
+	return &jobsStore{jobs: map[string]models.EtlJob{}}
+}
+
+func (s *jobsStore) get(jobID string) models.EtlJob {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.jobs[jobID]
+}
+
+func (s *jobsStore) repository() jobs.RepositoryMock {
+	return jobs.RepositoryMock{
+		HandleGet: func(ctx context.Context, jobID string) (models.EtlJob, apierrors.ApiError) {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			job, ok := s.jobs[jobID]
+			if !ok {
+				return models.EtlJob{}, apierrors.NewApiError("etl job not found", "not_found", 404, apierrors.CauseList{})
+			}
+			return job, nil
+		},
+		HandleCreate: func(ctx context.Context, job models.EtlJob) (string, apierrors.ApiError) {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			job.ID = fmt.Sprintf("job-%d", len(s.jobs)+1)
+			s.jobs[job.ID] = job
+			return job.ID, nil
+		},
+		HandleUpdate: func(ctx context.Context, job models.EtlJob) apierrors.ApiError {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			// Like the repository, finished jobs are never written again and a cancellation requested meanwhile is kept
+			if current := s.jobs[job.ID]; current.IsFinished() {
+				return apierrors.NewApiError("etl job not found", "not_found", 404, apierrors.CauseList{})
+			}
+			job.CancelRequested = job.CancelRequested || s.jobs[job.ID].CancelRequested
+			s.jobs[job.ID] = job
+			return nil
+		},
+		HandleRequestCancel: func(ctx context.Context, jobID string) apierrors.ApiError {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			job := s.jobs[jobID]
+			job.CancelRequested = true
+			s.jobs[jobID] = job
+			return nil
+		},
+	}
+}
+
+// newApiJobsService runs the jobs of the store as imports of the shop's API
+func newApiJobsService(store *jobsStore, fetchClient clients.FetchApiClientMock, workers int) services.EtlJobsService {
+	itemsClient := clients.ItemsClientMock{
+		HandleBulkUpsertItems: func(ctx context.Context, items []models.Item) (*dto.BulkUpsertResponse, apierrors.ApiError) {
+			return &dto.BulkUpsertResponse{CreatedCount: int64(len(items))}, nil
+		},
+	}
+	companyLayouts := layouts.ServiceMock{
+		HandleGetByShopID: func(ctx context.Context, shopID string) (models.CompanyLayout, apierrors.ApiError) {
+			return models.CompanyLayout{ShopID: shopID, ItemMap: map[string]string{"id": "sku", "name": "title"}}, nil
+		},
+	}
+	locksRepository := locks.NewLocksRepositoryMock()
+
+	etlService := services.NewEtlService(fetchClient, itemsClient, clients.PriceClientMock{}, nil, nil, companyLayouts, nil, artifacts.NewArtifactsServiceMock(), locksRepository, nil, nil, nil, nil)
+	return services.NewEtlJobsService(store.repository(), locksRepository, etlService, nil, workers, 2)
+}
+
+// untilFinished relays the events of a job up to its finished event, failing the test if it never comes
+func untilFinished(t *testing.T, events <-chan models.EtlJobEvent) <-chan models.EtlJobEvent {
+	relayed := make(chan models.EtlJobEvent)
+	timeout := time.After(5 * time.Second)
+
+	go func() {
+		defer close(relayed)
+		for {
+			select {
+			case event := <-events:
+				relayed <- event
+				if event.Type == models.EtlJobEventFinished {
+					return
+				}
+			case <-timeout:
+				t.Error("etl job did not finish")
+				return
+			}
+		}
+	}()
+
+	return relayed
+}
@@ -0,0 +1,215 @@
+package etl
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jopitnow/go-jopit-toolkit/goauth"
+	"github.com/jopitnow/go-jopit-toolkit/goutils/apierrors"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/services"
+	"github.com/jopitnow/jopit-api-etl/src/tests/internal/domain/clients"
+	"github.com/jopitnow/jopit-api-etl/src/tests/internal/domain/repositories/jobs"
+	"github.com/jopitnow/jopit-api-etl/src/tests/internal/domain/repositories/locks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewEtlJobsService_FailsStaleJobs(t *testing.T) {
+	var staleBefore time.Time
+	var created models.EtlJob
+
+	repository := jobs.RepositoryMock{
+		HandleFailStale: func(ctx context.Context, before time.Time, reason string) (int64, apierrors.ApiError) {
+			staleBefore = before
+			return 2, nil
+		},
+		HandleCreate: func(ctx context.Context, job models.EtlJob) (string, apierrors.ApiError) {
+			created = job
+			return "job-1", nil
+		},
+	}
+
+	// No workers, the queued job stays queued
+	service := services.NewEtlJobsService(repository, locks.NewLocksRepositoryMock(), nil, nil, 0, 1)
+
+	// Jobs whose heartbeat is a few minutes old were lost, whichever replica held them
+	assert.WithinDuration(t, time.Now().Add(-5*time.Minute), staleBefore, time.Minute)
+
+	_, err := service.EnqueueScheduled(context.Background(), models.EtlSchedule{ShopID: "shop-1", UserID: "user-1"})
+	assert.Nil(t, err)
+	assert.False(t, created.HeartbeatAt.IsZero())
+	assert.True(t, created.HeartbeatAt.After(staleBefore))
+}
+
+func TestCancel_QueuedJobNeverStarts(t *testing.T) {
+	ctx := context.WithValue(context.Background(), goauth.FirebaseUserID, "user-1")
+
+	// The first job holds the only worker, the second one waits in the queue
+	release := make(chan struct{})
+	var fetches atomic.Int32
+	fetchClient := clients.FetchApiClientMock{
+		HandleFetchAPI: func(ctx context.Context, layout models.CompanyLayout) ([]map[string]string, apierrors.ApiError) {
+			fetches.Add(1)
+			<-release
+			return []map[string]string{{"sku": "A1", "title": "Remera"}}, nil
+		},
+	}
+	store := newJobsStore()
+	service := newApiJobsService(store, fetchClient, 1)
+
+	_, err := service.EnqueueScheduled(ctx, models.EtlSchedule{ShopID: "shop-1", UserID: "user-1", Source: models.EtlScheduleSourceApi})
+	require.Nil(t, err)
+	queued, err := service.EnqueueScheduled(ctx, models.EtlSchedule{ShopID: "shop-2", UserID: "user-1", Source: models.EtlScheduleSourceApi})
+	require.Nil(t, err)
+
+	_, events, unsubscribe, err := service.Subscribe(ctx, queued.ID)
+	require.Nil(t, err)
+	defer unsubscribe()
+
+	cancelled, err := service.Cancel(ctx, queued.ID)
+	require.Nil(t, err)
+	assert.True(t, cancelled.CancelRequested)
+	close(release)
+
+	var finished models.EtlJob
+	for event := range untilFinished(t, events) {
+		// A cancelled queued job goes straight to finished, it never reports a stage
+		require.Equal(t, models.EtlJobEventFinished, event.Type)
+		finished = *event.Job
+	}
+
+	assert.Equal(t, models.EtlJobStatusCancelled, finished.Status)
+	assert.Nil(t, finished.StartedAt)
+	assert.Equal(t, models.EtlJobStatusCancelled, store.get(queued.ID).Status)
+	assert.Equal(t, int32(1), fetches.Load())
+}
+
+func TestCancel_RunningJobEndsCancelledNotFailed(t *testing.T) {
+	ctx := context.WithValue(context.Background(), goauth.FirebaseUserID, "user-1")
+
+	// The fetch runs until the job is cancelled, then fails like a call whose context was cancelled
+	started := make(chan struct{})
+	fetchClient := clients.FetchApiClientMock{
+		HandleFetchAPI: func(ctx context.Context, layout models.CompanyLayout) ([]map[string]string, apierrors.ApiError) {
+			close(started)
+			<-ctx.Done()
+			return nil, apierrors.NewApiError(ctx.Err().Error(), "internal_server_error", 500, apierrors.CauseList{})
+		},
+	}
+	store := newJobsStore()
+	service := newApiJobsService(store, fetchClient, 1)
+
+	running, err := service.EnqueueScheduled(ctx, models.EtlSchedule{ShopID: "shop-1", UserID: "user-1", Source: models.EtlScheduleSourceApi})
+	require.Nil(t, err)
+
+	_, events, unsubscribe, err := service.Subscribe(ctx, running.ID)
+	require.Nil(t, err)
+	defer unsubscribe()
+	<-started
+
+	_, err = service.Cancel(ctx, running.ID)
+	require.Nil(t, err)
+
+	var finished models.EtlJob
+	for event := range untilFinished(t, events) {
+		if event.Type == models.EtlJobEventFinished {
+			finished = *event.Job
+		}
+	}
+
+	assert.Equal(t, models.EtlJobStatusCancelled, finished.Status)
+	assert.NotNil(t, finished.StartedAt)
+	assert.Empty(t, finished.Error)
+
+	// A finished job cannot be cancelled again
+	_, err = service.Cancel(ctx, running.ID)
+	require.NotNil(t, err)
+	assert.Equal(t, 409, err.Status())
+}
+
+func TestRunJob_CapsTheListedItems(t *testing.T) {
+	ctx := context.WithValue(context.Background(), goauth.FirebaseUserID, "user-1")
+
+	// Items without a title fail the transform
+	fetchClient := clients.FetchApiClientMock{
+		HandleFetchAPI: func(ctx context.Context, layout models.CompanyLayout) ([]map[string]string, apierrors.ApiError) {
+			records := make([]map[string]string, 0, 270)
+			for i := 0; i < 150; i++ {
+				records = append(records, map[string]string{"sku": fmt.Sprintf("A%d", i), "title": "Remera"})
+			}
+			for i := 0; i < 120; i++ {
+				records = append(records, map[string]string{"sku": fmt.Sprintf("B%d", i)})
+			}
+			return records, nil
+		},
+	}
+	store := newJobsStore()
+	service := newApiJobsService(store, fetchClient, 1)
+
+	job, err := service.EnqueueScheduled(ctx, models.EtlSchedule{ShopID: "shop-1", UserID: "user-1", Source: models.EtlScheduleSourceApi})
+	require.Nil(t, err)
+
+	// Item events overflow the subscriber buffer, the stored record tells when the job finished
+	require.Eventually(t, func() bool {
+		finished := store.get(job.ID)
+		return finished.IsFinished()
+	}, 5*time.Second, 10*time.Millisecond)
+
+	finished := store.get(job.ID)
+	assert.Equal(t, models.EtlJobStatusCompleted, finished.Status)
+	assert.Equal(t, 270, finished.ProcessedItems)
+	assert.Equal(t, 120, finished.FailureCount)
+	assert.Len(t, finished.LoadedItems, 100)
+	assert.Len(t, finished.FailedItems, 100)
+}
+
+func TestRunJob_StopsWhenTheJobWasTakenAway(t *testing.T) {
+	ctx := context.WithValue(context.Background(), goauth.FirebaseUserID, "user-1")
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	fetchClient := clients.FetchApiClientMock{
+		HandleFetchAPI: func(ctx context.Context, layout models.CompanyLayout) ([]map[string]string, apierrors.ApiError) {
+			close(started)
+			<-release
+			return []map[string]string{{"sku": "A1", "title": "Remera"}}, nil
+		},
+	}
+	store := newJobsStore()
+	service := newApiJobsService(store, fetchClient, 1)
+
+	running, err := service.EnqueueScheduled(ctx, models.EtlSchedule{ShopID: "shop-1", UserID: "user-1", Source: models.EtlScheduleSourceApi})
+	require.Nil(t, err)
+
+	_, events, unsubscribe, err := service.Subscribe(ctx, running.ID)
+	require.Nil(t, err)
+	defer unsubscribe()
+	<-started
+
+	// Another replica failed the job as stale while it was extracting
+	store.mu.Lock()
+	stale := store.jobs[running.ID]
+	stale.Status = models.EtlJobStatusFailed
+	stale.Error = "etl job lost by a replica that stopped, please start it again"
+	store.jobs[running.ID] = stale
+	store.mu.Unlock()
+	close(release)
+
+	var finished models.EtlJob
+	for event := range untilFinished(t, events) {
+		if event.Type == models.EtlJobEventFinished {
+			finished = *event.Job
+		}
+	}
+
+	assert.Equal(t, models.EtlJobStatusFailed, finished.Status)
+	assert.Contains(t, finished.Error, "finished by another replica")
+
+	// The record keeps what the other replica wrote
+	assert.Equal(t, stale.Error, store.get(running.ID).Error)
+	assert.Equal(t, 0, store.get(running.ID).ProcessedItems)
+}
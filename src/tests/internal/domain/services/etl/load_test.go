@@ -0,0 +1,29 @@
+package etl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/services"
+	"github.com/jopitnow/jopit-api-etl/src/tests/internal/domain/clients"
+	"github.com/jopitnow/jopit-api-etl/src/tests/internal/domain/repositories/locks"
+	"github.com/jopitnow/jopit-api-etl/src/tests/internal/domain/services/artifacts"
+	"github.com/jopitnow/jopit-api-etl/src/tests/internal/domain/services/layouts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadApi_ConflictsWithRunningJob(t *testing.T) {
+	ctx := services.WithShopID(context.Background(), "shop-1")
+
+	locksRepository := locks.NewLocksRepositoryMock()
+	locksRepository.Held["shop:shop-1"] = "job:other"
+
+	service := services.NewEtlService(nil, clients.ItemsClientMock{}, clients.PriceClientMock{}, nil, nil, layouts.ServiceMock{}, nil, artifacts.NewArtifactsServiceMock(), locksRepository, nil, nil, nil, nil)
+
+	result, err := service.LoadApi(ctx)
+	require.NotNil(t, err)
+	assert.Equal(t, 409, err.Status())
+	assert.Nil(t, result)
+	assert.Equal(t, "job:other", locksRepository.Held["shop:shop-1"])
+}
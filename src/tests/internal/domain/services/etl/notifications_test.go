@@ -0,0 +1,94 @@
+package etl
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jopitnow/go-jopit-toolkit/goutils/apierrors"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models/dto"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/services"
+	clientsMock "github.com/jopitnow/jopit-api-etl/src/tests/internal/domain/clients"
+	credentialsRepository "github.com/jopitnow/jopit-api-etl/src/tests/internal/domain/repositories/credentials"
+	"github.com/jopitnow/jopit-api-etl/src/tests/internal/domain/services/credentials"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReceiveMeliNotification_RequiresTheApplication(t *testing.T) {
+	service := services.NewMercadoLibreNotificationsService(credentials.ServiceMock{}, nil, "123", 0, 1)
+
+	for name, applicationID := range map[string]int64{"other application": 456, "no application": 0} {
+		t.Run(name, func(t *testing.T) {
+			err := service.Receive(context.Background(), dto.MeliNotification{Resource: "/items/MLA1", UserID: 1, Topic: services.MeliNotificationTopicItems, ApplicationID: applicationID})
+			require.NotNil(t, err)
+			assert.Equal(t, http.StatusForbidden, err.Status())
+		})
+	}
+
+	err := service.Receive(context.Background(), dto.MeliNotification{Resource: "/items/MLA1", UserID: 1, Topic: services.MeliNotificationTopicItems, ApplicationID: 123})
+	assert.Nil(t, err)
+}
+
+func TestReceiveMeliNotification_AcknowledgesUnknownSellers(t *testing.T) {
+	credentialsService := credentials.ServiceMock{
+		HandleGetCredentialsByMeliUserID: func(ctx context.Context, meliUserID int64) (models.MercadoLibreCredential, apierrors.ApiError) {
+			return models.MercadoLibreCredential{}, apierrors.NewApiError("credentials not found", "not_found", http.StatusNotFound, apierrors.CauseList{})
+		},
+	}
+	// With no queue room, an item that was queued would fail the notification
+	service := services.NewMercadoLibreNotificationsService(credentialsService, nil, "123", 0, 0)
+
+	err := service.Receive(context.Background(), dto.MeliNotification{Resource: "/items/MLA1", UserID: 1, Topic: services.MeliNotificationTopicItems, ApplicationID: 123})
+	assert.Nil(t, err)
+}
+
+// syncRecorder captures the credentials each notified item is synced with
+type syncRecorder struct {
+	services.EtlService
+	synced chan models.MercadoLibreCredential
+}
+
+func (recorder syncRecorder) SyncMercadoLibreItem(ctx context.Context, credentials models.MercadoLibreCredential, meliItemID string) apierrors.ApiError {
+	recorder.synced <- credentials
+	return nil
+}
+
+func TestReceiveMeliNotification_SyncsWithRefreshedCredentials(t *testing.T) {
+	expired := models.MercadoLibreCredential{
+		UserID:       "user-1",
+		ShopID:       "shop-1",
+		UserIDMeli:   1,
+		AccessToken:  "expired-token",
+		RefreshToken: "refresh-token",
+		ExpiresIn:    21600,
+		UpdatedAt:    time.Now().UTC().Add(-7 * time.Hour),
+	}
+	repository := credentialsRepository.RepositoryMock{
+		HandleGetCredentialsByMeliUserID: func(ctx context.Context, meliUserID int64) (models.MercadoLibreCredential, apierrors.ApiError) {
+			return expired, nil
+		},
+	}
+	authClient := clientsMock.MercadoLibreAuthClientMock{
+		HandleRefreshOAuthCredentials: func(ctx context.Context, refreshToken string) (dto.MercadoLibreAuthResponse, apierrors.ApiError) {
+			assert.Equal(t, "refresh-token", refreshToken)
+			return dto.MercadoLibreAuthResponse{AccessToken: "fresh-token", RefreshToken: "next-refresh-token", ExpiresIn: 21600, UserID: 1}, nil
+		},
+	}
+	credentialsService := services.NewMercadoLibreCredentialsService(repository, clientsMock.NewShopClientMock(), authClient)
+	recorder := syncRecorder{synced: make(chan models.MercadoLibreCredential, 1)}
+	service := services.NewMercadoLibreNotificationsService(credentialsService, recorder, "123", 1, 1)
+
+	err := service.Receive(context.Background(), dto.MeliNotification{Resource: "/items/MLA1", UserID: 1, Topic: services.MeliNotificationTopicItems, ApplicationID: 123})
+	require.Nil(t, err)
+
+	select {
+	case synced := <-recorder.synced:
+		assert.Equal(t, "fresh-token", synced.AccessToken)
+		assert.Equal(t, "user-1", synced.UserID)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the notified item was not synced")
+	}
+}
@@ -0,0 +1,218 @@
+package etl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jopitnow/go-jopit-toolkit/goutils/apierrors"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models/dto"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/services"
+	"github.com/jopitnow/jopit-api-etl/src/tests/internal/domain/clients"
+	"github.com/jopitnow/jopit-api-etl/src/tests/internal/domain/repositories/locks"
+	"github.com/jopitnow/jopit-api-etl/src/tests/internal/domain/services/artifacts"
+	"github.com/jopitnow/jopit-api-etl/src/tests/internal/domain/services/layouts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRollbackBatch_KeepsItemsThatExistedBeforeTheBatch(t *testing.T) {
+	ctx := services.WithShopID(context.Background(), "shop-1")
+
+	artifactsService := artifacts.NewArtifactsServiceMock()
+	require.Nil(t, artifactsService.Save(ctx, "shop-1", "new", models.EtlArtifactSnapshot, models.EtlBatchSnapshot{
+		BatchID:    "new",
+		ShopID:     "shop-1",
+		SourceType: models.MeliSourceType,
+		Created:    []string{"MLA2"},
+		Updated: []models.Item{
+			// Imported before items carried a batch id
+			{ID: "item-1", ShopID: "shop-1", Name: "Remera", Price: models.Price{Amount: 100}, Source: &models.Source{SourceType: models.MeliSourceType, ExternalID: "MLA1"}},
+		},
+	}))
+
+	var upserted []models.Item
+	var deletedItems, deletedPrices []string
+	batchDeleted := false
+
+	itemsClient := clients.ItemsClientMock{
+		HandleGetItemsByShopID: func(ctx context.Context, shopID string) ([]models.Item, apierrors.ApiError) {
+			return []models.Item{
+				{ID: "item-1", ShopID: "shop-1", Name: "Remera nueva", Source: &models.Source{SourceType: models.MeliSourceType, ExternalID: "MLA1", BatchID: "new"}},
+				{ID: "item-2", ShopID: "shop-1", Name: "Buzo", Source: &models.Source{SourceType: models.MeliSourceType, ExternalID: "MLA2", BatchID: "new"}},
+			}, nil
+		},
+		HandleBulkUpsertItems: func(ctx context.Context, items []models.Item) (*dto.BulkUpsertResponse, apierrors.ApiError) {
+			upserted = append(upserted, items...)
+			return &dto.BulkUpsertResponse{}, nil
+		},
+		HandleBulkDeleteItems: func(ctx context.Context, shopID string, batchID string) apierrors.ApiError {
+			batchDeleted = true
+			return nil
+		},
+		HandleBulkDeleteItemsByID: func(ctx context.Context, shopID string, itemIDs []string) apierrors.ApiError {
+			deletedItems = append(deletedItems, itemIDs...)
+			return nil
+		},
+	}
+	pricesClient := clients.PriceClientMock{
+		HandleBulkDeletePrices: func(ctx context.Context, itemIDs []string) apierrors.ApiError {
+			deletedPrices = append(deletedPrices, itemIDs...)
+			return nil
+		},
+	}
+
+	service := services.NewEtlService(nil, itemsClient, pricesClient, nil, nil, nil, nil, artifactsService, locks.NewLocksRepositoryMock(), nil, nil, nil, nil)
+
+	result, err := service.RollbackBatch(ctx, "new")
+	require.Nil(t, err)
+
+	assert.Equal(t, []string{"MLA1"}, result.Restored)
+	assert.Equal(t, []string{"MLA2"}, result.Deleted)
+	assert.Empty(t, result.Skipped)
+
+	require.Len(t, upserted, 1)
+	assert.Equal(t, "Remera", upserted[0].Name)
+	assert.NotEqual(t, "new", upserted[0].Source.BatchID)
+
+	assert.False(t, batchDeleted, "the batch delete would also remove the restored item")
+	assert.Equal(t, []string{"item-2"}, deletedItems)
+	assert.Equal(t, []string{"item-2"}, deletedPrices)
+}
+
+func TestRollbackBatch_ConflictsWithRunningJob(t *testing.T) {
+	ctx := services.WithShopID(context.Background(), "shop-1")
+
+	artifactsService := artifacts.NewArtifactsServiceMock()
+	require.Nil(t, artifactsService.Save(ctx, "shop-1", "new", models.EtlArtifactSnapshot, models.EtlBatchSnapshot{BatchID: "new", ShopID: "shop-1"}))
+
+	locksRepository := locks.NewLocksRepositoryMock()
+	locksRepository.Held["shop:shop-1"] = "job:other"
+
+	service := services.NewEtlService(nil, clients.ItemsClientMock{}, clients.PriceClientMock{}, nil, nil, nil, nil, artifactsService, locksRepository, nil, nil, nil, nil)
+
+	_, err := service.RollbackBatch(ctx, "new")
+	require.NotNil(t, err)
+	assert.Equal(t, 409, err.Status())
+}
+
+func TestDeleteBatch_ConflictsWithRunningJob(t *testing.T) {
+	ctx := services.WithShopID(context.Background(), "shop-1")
+
+	locksRepository := locks.NewLocksRepositoryMock()
+	locksRepository.Held["shop:shop-1"] = "job:other"
+
+	batchDeleted := false
+	itemsClient := clients.ItemsClientMock{
+		HandleBulkDeleteItems: func(ctx context.Context, shopID string, batchID string) apierrors.ApiError {
+			batchDeleted = true
+			return nil
+		},
+	}
+
+	service := services.NewEtlService(nil, itemsClient, clients.PriceClientMock{}, nil, nil, nil, nil, artifacts.NewArtifactsServiceMock(), locksRepository, nil, nil, nil, nil)
+
+	err := service.DeleteBatch(ctx, "new")
+	require.NotNil(t, err)
+	assert.Equal(t, 409, err.Status())
+	assert.False(t, batchDeleted)
+}
+
+func TestDeleteBatch_DeletesThePricesOfTheBatchItems(t *testing.T) {
+	ctx := services.WithShopID(context.Background(), "shop-1")
+
+	var calls []string
+	var deletedPrices []string
+	itemsClient := clients.ItemsClientMock{
+		HandleGetItemsByShopID: func(ctx context.Context, shopID string) ([]models.Item, apierrors.ApiError) {
+			return []models.Item{
+				{ID: "item-1", ShopID: "shop-1", Source: &models.Source{SourceType: models.MeliSourceType, ExternalID: "MLA1", BatchID: "new"}},
+				{ID: "item-2", ShopID: "shop-1", Source: &models.Source{SourceType: models.MeliSourceType, ExternalID: "MLA2", BatchID: "old"}},
+				{ID: "item-3", ShopID: "shop-1"},
+			}, nil
+		},
+		HandleBulkDeleteItems: func(ctx context.Context, shopID string, batchID string) apierrors.ApiError {
+			assert.Equal(t, "new", batchID)
+			calls = append(calls, "items")
+			return nil
+		},
+	}
+	pricesClient := clients.PriceClientMock{
+		HandleBulkDeletePrices: func(ctx context.Context, itemIDs []string) apierrors.ApiError {
+			deletedPrices = append(deletedPrices, itemIDs...)
+			calls = append(calls, "prices")
+			return nil
+		},
+	}
+
+	service := services.NewEtlService(nil, itemsClient, pricesClient, nil, nil, nil, nil, artifacts.NewArtifactsServiceMock(), locks.NewLocksRepositoryMock(), nil, nil, nil, nil)
+
+	err := service.DeleteBatch(ctx, "new")
+	require.Nil(t, err)
+
+	assert.Equal(t, []string{"item-1"}, deletedPrices)
+	assert.Equal(t, []string{"prices", "items"}, calls)
+}
+
+func TestRollbackBatch_RestoresThePricesTheBatchOverwrote(t *testing.T) {
+	ctx := services.WithShopID(context.Background(), "shop-1")
+
+	itemID := "item-1"
+	current := []models.Item{
+		{ID: itemID, ShopID: "shop-1", Name: "Remera", Source: &models.Source{SourceType: models.ApiSourceType, ExternalID: "A1", BatchID: "old"}},
+	}
+	prices := map[string]models.Price{
+		itemID: {ItemID: itemID, ShopID: "shop-1", Amount: 100, Currency: models.Currency{ID: "ARS"}},
+	}
+
+	itemsClient := clients.ItemsClientMock{
+		HandleGetItemsByShopID: func(ctx context.Context, shopID string) ([]models.Item, apierrors.ApiError) {
+			return current, nil
+		},
+		HandleBulkUpsertItems: func(ctx context.Context, items []models.Item) (*dto.BulkUpsertResponse, apierrors.ApiError) {
+			current = items
+			return &dto.BulkUpsertResponse{UpdatedCount: int64(len(items))}, nil
+		},
+	}
+	pricesClient := clients.PriceClientMock{
+		HandleGetItemsPrices: func(ctx context.Context, itemIDs []string) (models.Prices, apierrors.ApiError) {
+			found := models.Prices{}
+			for _, id := range itemIDs {
+				if price, ok := prices[id]; ok {
+					found.Prices = append(found.Prices, price)
+				}
+			}
+			return found, nil
+		},
+		HandleBulkUpsertPrices: func(ctx context.Context, upserted []models.Price) apierrors.ApiError {
+			for _, price := range upserted {
+				prices[price.ItemID] = price
+			}
+			return nil
+		},
+	}
+	fetchClient := clients.FetchApiClientMock{
+		HandleFetchAPI: func(ctx context.Context, layout models.CompanyLayout) ([]map[string]string, apierrors.ApiError) {
+			return []map[string]string{{"sku": "A1", "title": "Remera nueva", "amount": "150"}}, nil
+		},
+	}
+	companyLayouts := layouts.ServiceMock{
+		HandleGetByShopID: func(ctx context.Context, shopID string) (models.CompanyLayout, apierrors.ApiError) {
+			return models.CompanyLayout{ShopID: shopID, ItemMap: map[string]string{"id": "sku", "name": "title", "price": "amount"}}, nil
+		},
+	}
+
+	service := services.NewEtlService(fetchClient, itemsClient, pricesClient, nil, nil, companyLayouts, nil, artifacts.NewArtifactsServiceMock(), locks.NewLocksRepositoryMock(), nil, nil, nil, nil)
+
+	loaded, err := service.LoadApi(ctx)
+	require.Nil(t, err)
+	// The run overwrote the item's price
+	prices[itemID] = models.Price{ItemID: itemID, ShopID: "shop-1", Amount: 150, Currency: models.Currency{ID: "ARS"}}
+
+	result, err := service.RollbackBatch(ctx, loaded.BatchID)
+	require.Nil(t, err)
+
+	assert.Equal(t, []string{"A1"}, result.Restored)
+	assert.Equal(t, "Remera", current[0].Name)
+	assert.Equal(t, float64(100), prices[itemID].Amount)
+}
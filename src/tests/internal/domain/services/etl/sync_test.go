@@ -0,0 +1,25 @@
+package etl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/services"
+	"github.com/jopitnow/jopit-api-etl/src/tests/internal/domain/clients"
+	"github.com/jopitnow/jopit-api-etl/src/tests/internal/domain/repositories/locks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyncMercadoLibreItem_ConflictsWithRunningJob(t *testing.T) {
+	locksRepository := locks.NewLocksRepositoryMock()
+	locksRepository.Held["shop:shop-1"] = "job:other"
+
+	service := services.NewEtlService(nil, clients.ItemsClientMock{}, clients.PriceClientMock{}, nil, nil, nil, nil, nil, locksRepository, nil, nil, nil, nil)
+
+	err := service.SyncMercadoLibreItem(context.Background(), models.MercadoLibreCredential{ShopID: "shop-1", UserIDMeli: 1}, "MLA1")
+	require.NotNil(t, err)
+	assert.Equal(t, 409, err.Status())
+	assert.Equal(t, "job:other", locksRepository.Held["shop:shop-1"])
+}
@@ -0,0 +1,44 @@
+package layouts
+
+import (
+	"context"
+
+	"github.com/jopitnow/go-jopit-toolkit/goutils/apierrors"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models/dto"
+)
+
+type ServiceMock struct {
+	HandleGetByShopID func(ctx context.Context, shopID string) (models.CompanyLayout, apierrors.ApiError)
+}
+
+func NewCompanyLayoutServiceMock() ServiceMock {
+	return ServiceMock{}
+}
+
+func (mock ServiceMock) Get(ctx context.Context, companyLayoutID string) (models.CompanyLayout, apierrors.ApiError) {
+	return models.CompanyLayout{}, nil
+}
+
+func (mock ServiceMock) GetByShopID(ctx context.Context, shopID string) (models.CompanyLayout, apierrors.ApiError) {
+	if mock.HandleGetByShopID != nil {
+		return mock.HandleGetByShopID(ctx, shopID)
+	}
+	return models.CompanyLayout{ShopID: shopID}, nil
+}
+
+func (mock ServiceMock) GetAllCompanyLayout(ctx context.Context) ([]models.CompanyLayout, apierrors.ApiError) {
+	return []models.CompanyLayout{}, nil
+}
+
+func (mock ServiceMock) Create(ctx context.Context, input dto.CompanyLayoutRequest) apierrors.ApiError {
+	return nil
+}
+
+func (mock ServiceMock) Update(ctx context.Context, input dto.CompanyLayoutRequest) apierrors.ApiError {
+	return nil
+}
+
+func (mock ServiceMock) Delete(ctx context.Context) apierrors.ApiError {
+	return nil
+}
@@ -0,0 +1,192 @@
+package mercadolibre
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jopitnow/go-jopit-toolkit/goutils/apierrors"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models/dto"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/services"
+	"github.com/jopitnow/jopit-api-etl/src/tests/internal/domain/clients"
+	"github.com/jopitnow/jopit-api-etl/src/tests/internal/domain/services/credentials"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var seller = models.MercadoLibreCredential{UserIDMeli: 1, AccessToken: "APP_USR-1"}
+
+func TestGetUserItemsDetailsWithPagination_SearchMode(t *testing.T) {
+	tests := []struct {
+		name        string
+		total       int
+		offsetCalls int
+		scanCalls   int
+	}{
+		{name: "offset paging up to the limit", total: 1000, offsetCalls: 10, scanCalls: 0},
+		{name: "scan search past the limit", total: 1001, offsetCalls: 1, scanCalls: 12},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ids := itemIDs(tt.total)
+			var offsets []int
+			var scrollIDs []string
+
+			meliClient := clients.MercadoLibreClientMock{
+				HandleGetUserItemsWithPagination: func(ctx context.Context, meliUserID int64, accessToken string, offset int, limit int) (dto.MeliUserItemsSearchResponse, apierrors.ApiError) {
+					offsets = append(offsets, offset)
+					return searchPage(ids[min(offset, len(ids)):min(offset+limit, len(ids))], tt.total, ""), nil
+				},
+				HandleScanUserItems: func(ctx context.Context, meliUserID int64, accessToken string, scrollID string, limit int) (dto.MeliUserItemsSearchResponse, apierrors.ApiError) {
+					scrollIDs = append(scrollIDs, scrollID)
+					start := len(scrollIDs) - 1
+					if start*limit >= len(ids) {
+						return searchPage(nil, tt.total, ""), nil
+					}
+					// Scan pages can repeat the last id of the previous page
+					page := ids[max(start*limit-1, 0):min((start+1)*limit, len(ids))]
+					return searchPage(page, tt.total, fmt.Sprintf("scroll-%d", start+1)), nil
+				},
+				HandleGetItems: multiget(nil, nil),
+			}
+			service := services.NewMercadoLibreService(meliClient, nil, 1, 4)
+
+			items, failedItems, err := service.GetUserItemsDetailsWithPagination(context.Background(), seller, 100)
+			require.Nil(t, err)
+
+			assert.Empty(t, failedItems)
+			require.Len(t, items, tt.total)
+			for i, item := range items {
+				assert.Equal(t, ids[i], item.ID)
+			}
+			assert.Len(t, offsets, tt.offsetCalls)
+			assert.Len(t, scrollIDs, tt.scanCalls)
+			if tt.scanCalls > 0 {
+				assert.Equal(t, []string{"", "scroll-1", "scroll-2"}, scrollIDs[:3])
+			}
+		})
+	}
+}
+
+func TestGetUserItemsDetailsWithPagination_PartialMultigetFailures(t *testing.T) {
+	// 45 ids are fetched in chunks of 20, 20 and 5
+	ids := itemIDs(45)
+
+	tests := []struct {
+		name         string
+		failedChunks map[string]bool
+		missing      map[string]bool
+		items        int
+		failed       map[string]int
+		status       int
+	}{
+		{name: "every chunk succeeds", items: 45, failed: map[string]int{}},
+		{name: "a chunk fails", failedChunks: map[string]bool{"MLA21": true}, items: 25, failed: map[string]int{"MercadoLibre multiget failed": 20}},
+		{name: "an item is missing", missing: map[string]bool{"MLA45": true}, items: 44, failed: map[string]int{"item not found": 1}},
+		{name: "a chunk fails and an item is missing", failedChunks: map[string]bool{"MLA1": true}, missing: map[string]bool{"MLA45": true}, items: 24, failed: map[string]int{"MercadoLibre multiget failed": 20, "item not found": 1}},
+		{name: "every chunk fails", failedChunks: map[string]bool{"MLA1": true, "MLA21": true, "MLA41": true}, status: http.StatusBadGateway},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			meliClient := clients.MercadoLibreClientMock{
+				HandleGetUserItemsWithPagination: func(ctx context.Context, meliUserID int64, accessToken string, offset int, limit int) (dto.MeliUserItemsSearchResponse, apierrors.ApiError) {
+					return searchPage(ids[min(offset, len(ids)):min(offset+limit, len(ids))], len(ids), ""), nil
+				},
+				HandleGetItems: multiget(tt.failedChunks, tt.missing),
+			}
+			service := services.NewMercadoLibreService(meliClient, nil, 1, 2)
+
+			items, failedItems, err := service.GetUserItemsDetailsWithPagination(context.Background(), seller, 50)
+			if tt.status != 0 {
+				require.NotNil(t, err)
+				assert.Equal(t, tt.status, err.Status())
+				return
+			}
+			require.Nil(t, err)
+
+			assert.Len(t, items, tt.items)
+			failed := map[string]int{}
+			for _, item := range failedItems {
+				assert.Equal(t, models.EtlStageExtract, item.FailureStage)
+				assert.NotEmpty(t, item.ExternalID)
+				failed[item.ErrorMessage]++
+			}
+			assert.Equal(t, tt.failed, failed)
+
+			// Every id comes back once, as an item or as a failure
+			assert.Len(t, ids, len(items)+len(failedItems))
+		})
+	}
+}
+
+func TestRenewCredentials(t *testing.T) {
+	tests := []struct {
+		name      string
+		updatedAt time.Time
+		token     string
+	}{
+		{name: "token still valid", updatedAt: time.Now().UTC().Add(-1 * time.Hour), token: "APP_USR-1"},
+		{name: "token about to expire", updatedAt: time.Now().UTC().Add(-5*time.Hour - 30*time.Minute), token: "APP_USR-2"},
+		{name: "token expired", updatedAt: time.Now().UTC().Add(-7 * time.Hour), token: "APP_USR-2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			credentialsService := credentials.ServiceMock{
+				HandleGetCredentialsByUserID: func(ctx context.Context, userID string) (models.MercadoLibreCredential, apierrors.ApiError) {
+					assert.Equal(t, "user-1", userID)
+					return models.MercadoLibreCredential{UserID: userID, AccessToken: "APP_USR-2", ExpiresIn: 21600, UpdatedAt: time.Now().UTC()}, nil
+				},
+			}
+			service := services.NewMercadoLibreService(clients.MercadoLibreClientMock{}, credentialsService, 1, 1)
+
+			renewed, err := service.RenewCredentials(context.Background(), models.MercadoLibreCredential{UserID: "user-1", AccessToken: "APP_USR-1", ExpiresIn: 21600, UpdatedAt: tt.updatedAt})
+			require.Nil(t, err)
+
+			assert.Equal(t, tt.token, renewed.AccessToken)
+		})
+	}
+}
+
+func itemIDs(count int) []string {
+	ids := make([]string, count)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("MLA%d", i+1)
+	}
+	return ids
+}
+
+func searchPage(ids []string, total int, scrollID string) dto.MeliUserItemsSearchResponse {
+	page := dto.MeliUserItemsSearchResponse{Results: ids, ScrollID: scrollID}
+	page.Paging.Total = total
+	return page
+}
+
+// multiget answers the multiget calls with every item found, except the chunks holding an id of failedChunks,
+// which fail, and the ids of missing, which MercadoLibre does not return
+func multiget(failedChunks map[string]bool, missing map[string]bool) func(ctx context.Context, meliItemIDs []string, accessToken string) ([]dto.MeliMultigetResult, apierrors.ApiError) {
+	var mu sync.Mutex
+	return func(ctx context.Context, meliItemIDs []string, accessToken string) ([]dto.MeliMultigetResult, apierrors.ApiError) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		results := make([]dto.MeliMultigetResult, 0, len(meliItemIDs))
+		for _, id := range meliItemIDs {
+			if failedChunks[id] {
+				return nil, apierrors.NewApiError("MercadoLibre multiget failed", "bad_gateway", http.StatusBadGateway, apierrors.CauseList{})
+			}
+			if missing[id] {
+				results = append(results, dto.MeliMultigetResult{ID: id, Code: http.StatusNotFound, Message: "item not found"})
+				continue
+			}
+			results = append(results, dto.MeliMultigetResult{ID: id, Code: http.StatusOK, Item: dto.MeliItemResponse{ID: id}})
+		}
+		return results, nil
+	}
+}
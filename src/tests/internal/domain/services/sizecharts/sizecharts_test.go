@@ -0,0 +1,90 @@
+package sizecharts
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jopitnow/go-jopit-toolkit/goutils/apierrors"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models/dto"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/services"
+	"github.com/jopitnow/jopit-api-etl/src/tests/internal/domain/clients"
+	"github.com/jopitnow/jopit-api-etl/src/tests/internal/domain/repositories/sizecharts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sizeChartsTTL = 24 * time.Hour
+
+func TestGetSizeChart_ReadsThroughTheCache(t *testing.T) {
+	cachedChart := dto.MeliSizeChartResponse{ID: "123", Names: map[string]string{"MLA": "cached"}}
+	fetchedChart := dto.MeliSizeChartResponse{ID: "123", Names: map[string]string{"MLA": "fetched"}}
+	meliDown := apierrors.NewApiError("MercadoLibre is down", "bad_gateway", http.StatusBadGateway, apierrors.CauseList{})
+
+	tests := []struct {
+		name     string
+		cachedAt time.Duration // age of the cached chart, 0 when there is none
+		readErr  apierrors.ApiError
+		fetchErr apierrors.ApiError
+		saveErr  apierrors.ApiError
+		expected string
+		fetched  bool
+		saved    bool
+		status   int
+	}{
+		{name: "fresh entry", cachedAt: time.Hour, expected: "cached"},
+		{name: "stale entry is refreshed", cachedAt: 25 * time.Hour, expected: "fetched", fetched: true, saved: true},
+		{name: "stale entry when MercadoLibre fails", cachedAt: 25 * time.Hour, fetchErr: meliDown, expected: "cached", fetched: true},
+		{name: "missing entry is fetched", expected: "fetched", fetched: true, saved: true},
+		{name: "missing entry when MercadoLibre fails", fetchErr: meliDown, fetched: true, status: http.StatusBadGateway},
+		{name: "failed cache write", saveErr: meliDown, expected: "fetched", fetched: true, saved: true},
+		{name: "failed cache read", readErr: apierrors.NewApiError("mongo is down", "internal_server_error", http.StatusInternalServerError, apierrors.CauseList{}), status: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetched, saved := false, false
+
+			repository := sizecharts.RepositoryMock{
+				HandleGet: func(ctx context.Context, chartID string) (dto.MeliCachedSizeChart, apierrors.ApiError) {
+					if tt.readErr != nil {
+						return dto.MeliCachedSizeChart{}, tt.readErr
+					}
+					if tt.cachedAt == 0 {
+						return dto.MeliCachedSizeChart{}, apierrors.NewApiError("size chart not found", "not_found", http.StatusNotFound, apierrors.CauseList{})
+					}
+					return dto.MeliCachedSizeChart{ID: chartID, Chart: cachedChart, FetchedAt: time.Now().UTC().Add(-tt.cachedAt)}, nil
+				},
+				HandleSave: func(ctx context.Context, chart dto.MeliCachedSizeChart) apierrors.ApiError {
+					saved = true
+					assert.Equal(t, fetchedChart, chart.Chart)
+					assert.WithinDuration(t, time.Now(), chart.FetchedAt, time.Minute)
+					return tt.saveErr
+				},
+			}
+			meliClient := clients.MercadoLibreClientMock{
+				HandleGetSizeChart: func(ctx context.Context, chartID string, accessToken string) (dto.MeliSizeChartResponse, apierrors.ApiError) {
+					fetched = true
+					if tt.fetchErr != nil {
+						return dto.MeliSizeChartResponse{}, tt.fetchErr
+					}
+					return fetchedChart, nil
+				},
+			}
+			service := services.NewMeliSizeChartsService(repository, meliClient, sizeChartsTTL)
+
+			chart, err := service.GetSizeChart(context.Background(), "123", "APP_USR-1")
+
+			assert.Equal(t, tt.fetched, fetched)
+			assert.Equal(t, tt.saved, saved)
+			if tt.status != 0 {
+				require.NotNil(t, err)
+				assert.Equal(t, tt.status, err.Status())
+				return
+			}
+			require.Nil(t, err)
+			assert.Equal(t, tt.expected, chart.Names["MLA"])
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewBatchID(t *testing.T) {
+	first := utils.NewBatchID(models.MeliSourceType)
+	second := utils.NewBatchID(models.MeliSourceType)
+
+	assert.True(t, strings.HasPrefix(first, "meli-"))
+	assert.NotEqual(t, first, second)
+}
+
+func TestSnapshotBatch(t *testing.T) {
+	existing := []models.Item{
+		{ID: "item-1", Name: "Remera", Source: &models.Source{SourceType: models.MeliSourceType, ExternalID: "MLA1", BatchID: "old"}},
+		{ID: "item-2", Name: "Buzo", Source: &models.Source{SourceType: models.CsvSourceType, ExternalID: "MLA2", BatchID: "old"}},
+	}
+
+	items := []models.Item{
+		{Name: "Remera oversize", Source: &models.Source{SourceType: models.MeliSourceType, ExternalID: "MLA1", BatchID: "new"}},
+		{Name: "Buzo", Source: &models.Source{SourceType: models.MeliSourceType, ExternalID: "MLA2", BatchID: "new"}},
+	}
+
+	snapshot := utils.SnapshotBatch("new", "shop-1", models.MeliSourceType, existing, items)
+
+	assert.Equal(t, "new", snapshot.BatchID)
+	assert.Equal(t, []string{"MLA2"}, snapshot.Created)
+	assert.Len(t, snapshot.Updated, 1)
+	assert.Equal(t, "Remera", snapshot.Updated[0].Name)
+	assert.Equal(t, "old", snapshot.Updated[0].Source.BatchID)
+}
+
+func TestPlanRollback(t *testing.T) {
+	snapshot := models.EtlBatchSnapshot{
+		BatchID:    "new",
+		SourceType: models.MeliSourceType,
+		Created:    []string{"MLA3", "MLA4", "MLA5"},
+		Updated: []models.Item{
+			{ID: "item-1", Name: "Remera", Source: &models.Source{SourceType: models.MeliSourceType, ExternalID: "MLA1", BatchID: "old"}},
+			{ID: "item-2", Name: "Buzo", Source: &models.Source{SourceType: models.MeliSourceType, ExternalID: "MLA2", BatchID: "old"}},
+		},
+	}
+
+	current := []models.Item{
+		{ID: "item-1", Source: &models.Source{SourceType: models.MeliSourceType, ExternalID: "MLA1", BatchID: "new"}},
+		{ID: "item-2", Source: &models.Source{SourceType: models.MeliSourceType, ExternalID: "MLA2", BatchID: "later"}},
+		{ID: "item-3", Source: &models.Source{SourceType: models.MeliSourceType, ExternalID: "MLA3", BatchID: "new"}},
+		{ID: "item-4", Source: &models.Source{SourceType: models.MeliSourceType, ExternalID: "MLA4", BatchID: "later"}},
+	}
+
+	restore, deleted, skipped := utils.PlanRollback(snapshot, current)
+
+	assert.Len(t, restore, 1)
+	assert.Equal(t, "Remera", restore[0].Name)
+	assert.Equal(t, []string{"MLA3"}, deleted)
+	assert.Equal(t, []string{"MLA2", "MLA4"}, skipped)
+}
+
+func TestPlanRollbackRestoresItemsWithoutBatch(t *testing.T) {
+	snapshot := models.EtlBatchSnapshot{
+		BatchID:    "new",
+		SourceType: models.MeliSourceType,
+		Updated: []models.Item{
+			{ID: "item-1", Name: "Remera", Source: &models.Source{SourceType: models.MeliSourceType, ExternalID: "MLA1"}},
+		},
+	}
+	current := []models.Item{
+		{ID: "item-1", Source: &models.Source{SourceType: models.MeliSourceType, ExternalID: "MLA1", BatchID: "new"}},
+	}
+
+	restore, deleted, skipped := utils.PlanRollback(snapshot, current)
+
+	assert.Len(t, restore, 1)
+	assert.Equal(t, utils.RollbackBatchID("new"), restore[0].Source.BatchID)
+	assert.Empty(t, snapshot.Updated[0].Source.BatchID)
+	assert.Empty(t, deleted)
+	assert.Empty(t, skipped)
+}
@@ -0,0 +1,79 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models/dto"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveCategoryMapping(t *testing.T) {
+	mappings := []models.CategoryMapping{
+		{ID: "shop-domain", ShopID: "shop-1", MeliDomainID: "MLA-T_SHIRTS", CategoryID: "c1", CategoryName: "Remeras"},
+		{ID: "global-category", MeliCategoryID: "MLA109027", CategoryID: "c2", CategoryName: "Ropa"},
+		{ID: "global-domain", MeliDomainID: "MLA-SNEAKERS", CategoryID: "c3", CategoryName: "Calzado"},
+	}
+
+	mapping, ok := utils.ResolveCategoryMapping(mappings, "MLA109027", "MLA-T_SHIRTS")
+	assert.True(t, ok)
+	assert.Equal(t, "shop-domain", mapping.ID)
+
+	mapping, ok = utils.ResolveCategoryMapping(mappings, "MLA109027", "MLA-SHIRTS")
+	assert.True(t, ok)
+	assert.Equal(t, "global-category", mapping.ID)
+
+	mapping, ok = utils.ResolveCategoryMapping(mappings, "MLA1", "MLA-SNEAKERS")
+	assert.True(t, ok)
+	assert.Equal(t, "global-domain", mapping.ID)
+
+	_, ok = utils.ResolveCategoryMapping(mappings, "MLA1", "MLA-HATS")
+	assert.False(t, ok)
+}
+
+func TestResolveCategoryMappingOtherSite(t *testing.T) {
+	mappings := []models.CategoryMapping{
+		{ID: "global-domain", MeliDomainID: "MLA-SNEAKERS", CategoryID: "c1", CategoryName: "Calzado"},
+		{ID: "global-domain-mlu", MeliDomainID: "MLU-T_SHIRTS", CategoryID: "c2", CategoryName: "Remeras"},
+		{ID: "global-domain-mla", MeliDomainID: "MLA-T_SHIRTS", CategoryID: "c3", CategoryName: "Ropa"},
+	}
+
+	// Uruguayan sneakers use the Argentinian mapping of the domain
+	mapping, ok := utils.ResolveCategoryMapping(mappings, "MLU1", "MLU-SNEAKERS")
+	assert.True(t, ok)
+	assert.Equal(t, "global-domain", mapping.ID)
+
+	// The site's own mapping wins over another site's
+	mapping, ok = utils.ResolveCategoryMapping(mappings, "MLA1", "MLA-T_SHIRTS")
+	assert.True(t, ok)
+	assert.Equal(t, "global-domain-mla", mapping.ID)
+
+	_, ok = utils.ResolveCategoryMapping(mappings, "MLU1", "MLU-SNEAKERS_BOOTS")
+	assert.False(t, ok)
+}
+
+func TestTransformMeliItemCategory(t *testing.T) {
+	meliItem := dto.MeliItemResponse{ID: "MLA1", Title: "Zapatilla", CategoryID: "MLA109027", DomainID: "MLA-SNEAKERS"}
+	config := utils.MeliTransformConfig{
+		ShopID: "shop-1",
+		CategoryMappings: []models.CategoryMapping{
+			{MeliCategoryID: "MLA109027", CategoryID: "64b7f0c2a1b2c3d4e5f60718", CategoryName: "Calzado", SubcategoryID: "64b7f0c2a1b2c3d4e5f60719", SubcategoryName: "Zapatillas"},
+		},
+	}
+
+	item, warnings := utils.TransformMeliItemToJopitItem(meliItem, nil, config)
+
+	assert.Empty(t, warnings)
+	assert.Equal(t, "64b7f0c2a1b2c3d4e5f60718", item.Category.ID)
+	assert.Equal(t, "Calzado", item.Category.Name)
+	assert.Equal(t, "64b7f0c2a1b2c3d4e5f60719", item.Category.Subcategory.ID)
+
+	config.CategoryMappings = nil
+	item, warnings = utils.TransformMeliItemToJopitItem(meliItem, nil, config)
+
+	assert.Len(t, warnings, 1)
+	assert.Equal(t, models.TransformWarningUnmappedCategory, warnings[0].Code)
+	assert.Equal(t, "MLA1", warnings[0].ExternalID)
+	assert.Equal(t, models.ItemCategory{}, item.Category)
+}
@@ -0,0 +1,96 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func meliSourcedItem(categoryID string, domainID string) models.Item {
+	return models.Item{Source: &models.Source{
+		SourceType:        models.MeliSourceType,
+		TransformMetadata: map[string]string{"original_category_id": categoryID, "original_domain_id": domainID},
+	}}
+}
+
+func TestCategoryUsages(t *testing.T) {
+	items := []models.Item{
+		meliSourcedItem("MLA2", "MLA-PANTS"),
+		meliSourcedItem("MLA1", "MLA-T_SHIRTS"),
+		meliSourcedItem("MLA1", "MLA-T_SHIRTS"),
+		{Source: &models.Source{SourceType: models.CsvSourceType}},
+		{},
+	}
+
+	usages := utils.CategoryUsages(items)
+
+	assert.Equal(t, []utils.CategoryUsage{
+		{CategoryID: "MLA1", DomainID: "MLA-T_SHIRTS", ItemsCount: 2},
+		{CategoryID: "MLA2", DomainID: "MLA-PANTS", ItemsCount: 1},
+	}, usages)
+}
+
+func TestSuggestCategoryMappingFromAncestor(t *testing.T) {
+	category := models.MeliCategory{ID: "MLA3", PathFromRoot: []models.MeliCategoryNode{
+		{ID: "MLA1", Name: "Ropa y Accesorios"},
+		{ID: "MLA2", Name: "Remeras"},
+		{ID: "MLA3", Name: "Musculosas"},
+	}}
+	mappings := []models.CategoryMapping{
+		{MeliCategoryID: "MLA1", CategoryID: "c1", CategoryName: "Ropa"},
+		{MeliCategoryID: "MLA2", CategoryID: "c1", CategoryName: "Ropa", SubcategoryID: "s1", SubcategoryName: "Remeras"},
+	}
+	usage := utils.CategoryUsage{CategoryID: "MLA3", DomainID: "MLA-TANK_TOPS", ItemsCount: 4}
+
+	suggestion := utils.SuggestCategoryMapping(usage, category, nil, mappings, nil)
+
+	assert.Equal(t, []string{"Ropa y Accesorios", "Remeras", "Musculosas"}, suggestion.MeliCategoryPath)
+	assert.Equal(t, 4, suggestion.ItemsCount)
+	assert.Equal(t, "MLA3", suggestion.Suggested.MeliCategoryID)
+	assert.Equal(t, "s1", suggestion.Suggested.SubcategoryID)
+	assert.Equal(t, 0.9, suggestion.Confidence)
+}
+
+func TestSuggestCategoryMappingFromDomain(t *testing.T) {
+	usages := []utils.CategoryUsage{
+		{CategoryID: "MLA10", DomainID: "MLA-SNEAKERS", ItemsCount: 3},
+		{CategoryID: "MLA11", DomainID: "MLA-SNEAKERS", ItemsCount: 1},
+		{CategoryID: "MLA12", DomainID: "MLA-SNEAKERS", ItemsCount: 1},
+	}
+	mappings := []models.CategoryMapping{
+		{MeliCategoryID: "MLA11", CategoryID: "c2", CategoryName: "Calzado", SubcategoryID: "s2", SubcategoryName: "Zapatillas"},
+	}
+
+	suggestion := utils.SuggestCategoryMapping(usages[0], models.MeliCategory{ID: "MLA10"}, usages, mappings, nil)
+
+	assert.Equal(t, "c2", suggestion.Suggested.CategoryID)
+	assert.Equal(t, "Zapatillas", suggestion.Suggested.SubcategoryName)
+	assert.Equal(t, 0.75, suggestion.Confidence)
+}
+
+func TestSuggestCategoryMappingFromName(t *testing.T) {
+	jopitCategories := []models.ItemCategory{
+		{ID: "c1", Name: "Ropa"},
+		{ID: "c1", Name: "Ropa", Subcategory: &models.Subcategory{ID: "s1", Name: "Pantalón"}},
+		{ID: "c2", Name: "Calzado"},
+	}
+	category := models.MeliCategory{ID: "MLA20", PathFromRoot: []models.MeliCategoryNode{
+		{ID: "MLA1", Name: "Ropa y Accesorios"},
+		{ID: "MLA20", Name: "Pantalones"},
+	}}
+	usage := utils.CategoryUsage{CategoryID: "MLA20", DomainID: "MLA-PANTS", ItemsCount: 1}
+
+	suggestion := utils.SuggestCategoryMapping(usage, category, []utils.CategoryUsage{usage}, nil, jopitCategories)
+
+	assert.Equal(t, "s1", suggestion.Suggested.SubcategoryID)
+	assert.Equal(t, 0.58, suggestion.Confidence)
+	assert.Contains(t, suggestion.Reason, "Ropa > Pantalón")
+
+	unrelated := models.MeliCategory{ID: "MLA30", PathFromRoot: []models.MeliCategoryNode{{ID: "MLA30", Name: "Juguetes"}}}
+	suggestion = utils.SuggestCategoryMapping(utils.CategoryUsage{CategoryID: "MLA30", DomainID: "MLA-TOYS"}, unrelated, nil, nil, jopitCategories)
+
+	assert.Nil(t, suggestion.Suggested)
+	assert.Zero(t, suggestion.Confidence)
+}
@@ -0,0 +1,93 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models/dto"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func colorAttribute(valueID string, valueName string) dto.MeliAttribute {
+	attr := dto.MeliAttribute{ID: "COLOR", ValueName: valueName}
+	if valueID != "" {
+		attr.ValueID = &valueID
+	}
+	return attr
+}
+
+func TestColorResolver(t *testing.T) {
+	resolver := utils.NewColorResolver(map[string]string{"Verde Benetton": "0a5", "Negro": "#111111"})
+
+	hex, known := resolver.Resolve(colorAttribute("52055", "Blanco nieve"))
+	assert.True(t, known)
+	assert.Equal(t, "#FFFFFF", hex)
+
+	hex, known = resolver.Resolve(colorAttribute("", "Azul Marino"))
+	assert.True(t, known)
+	assert.Equal(t, "#000080", hex)
+
+	hex, known = resolver.Resolve(colorAttribute("", "verde benettón"))
+	assert.True(t, known)
+	assert.Equal(t, "#00AA55", hex)
+
+	// The shop's dictionary wins over MercadoLibre's value IDs
+	hex, known = resolver.Resolve(colorAttribute("52049", "Negro"))
+	assert.True(t, known)
+	assert.Equal(t, "#111111", hex)
+
+	hex, known = resolver.ResolveName("Grises con vivos blancos")
+	assert.True(t, known)
+	assert.Equal(t, "#808080", hex)
+
+	withMetadata := colorAttribute("999", "Tornasolado")
+	withMetadata.Values = []dto.MeliAttributeValue{{Name: "Tornasolado", Metadata: map[string]interface{}{"rgb": "7f00ff"}}}
+	hex, known = resolver.Resolve(withMetadata)
+	assert.True(t, known)
+	assert.Equal(t, "#7F00FF", hex)
+
+	hex, known = resolver.Resolve(colorAttribute("", "Tornasolado"))
+	assert.False(t, known)
+	assert.Empty(t, hex)
+}
+
+func TestNormalizeHexColor(t *testing.T) {
+	hex, ok := utils.NormalizeHexColor("#abc")
+	assert.True(t, ok)
+	assert.Equal(t, "#AABBCC", hex)
+
+	_, ok = utils.NormalizeHexColor("#abcd")
+	assert.False(t, ok)
+}
+
+func TestTransformMeliItemReportsUnknownColors(t *testing.T) {
+	meliItem := dto.MeliItemResponse{
+		ID:    "MLA1",
+		Title: "Remera",
+		Variations: []dto.MeliVariation{
+			{AttributeCombinations: []dto.MeliAttribute{colorAttribute("52049", "Negro"), {ID: "SIZE", ValueName: "M"}}},
+			{AttributeCombinations: []dto.MeliAttribute{colorAttribute("", "Tornasolado"), {ID: "SIZE", ValueName: "M"}}},
+		},
+	}
+
+	item, warnings := utils.TransformMeliItemToJopitItem(meliItem, nil, utils.MeliTransformConfig{
+		ShopID:   "shop-1",
+		Settings: models.EtlSettings{ColorHexes: map[string]string{"Negro": "#111"}},
+	})
+
+	hexes := map[string]string{}
+	for _, variant := range item.Variants {
+		hexes[variant.ColorName] = variant.ColorHex
+	}
+	assert.Equal(t, map[string]string{"Negro": "#111111", "Tornasolado": ""}, hexes)
+
+	var colorWarnings []models.TransformWarning
+	for _, warning := range warnings {
+		if warning.Code == models.TransformWarningUnknownColor {
+			colorWarnings = append(colorWarnings, warning)
+		}
+	}
+	assert.Len(t, colorWarnings, 1)
+	assert.Contains(t, colorWarnings[0].Message, "Tornasolado")
+}
@@ -0,0 +1,89 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCronInvalid(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "5-1 * * * *", "*/0 * * * *", "a * * * *"} {
+		_, err := utils.ParseCron(expr)
+		assert.Error(t, err, expr)
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	from := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC) // Monday
+
+	cases := map[string]time.Time{
+		"*/15 * * * *":   time.Date(2024, 1, 15, 10, 45, 0, 0, time.UTC),
+		"0 3 * * *":      time.Date(2024, 1, 16, 3, 0, 0, 0, time.UTC),
+		"30 10 * * *":    time.Date(2024, 1, 16, 10, 30, 0, 0, time.UTC),
+		"0 9-17/4 * * *": time.Date(2024, 1, 15, 13, 0, 0, 0, time.UTC),
+		"0 0 * * 0":      time.Date(2024, 1, 21, 0, 0, 0, 0, time.UTC),
+		"0 0 * * 7":      time.Date(2024, 1, 21, 0, 0, 0, 0, time.UTC),
+		"0 0 1 * *":      time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+		"0 0 29 2 *":     time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC),
+		"0 0 1 * 3":      time.Date(2024, 1, 17, 0, 0, 0, 0, time.UTC),
+		"@daily":         time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC),
+	}
+
+	for expr, expected := range cases {
+		schedule, err := utils.ParseCron(expr)
+		assert.NoError(t, err, expr)
+		assert.Equal(t, expected, schedule.Next(from), expr)
+	}
+}
+
+func TestCronNextNeverMatches(t *testing.T) {
+	schedule, err := utils.ParseCron("0 0 30 2 *")
+	assert.NoError(t, err)
+	assert.True(t, schedule.Next(time.Now()).IsZero())
+}
+
+func TestNextScheduleRun(t *testing.T) {
+	from := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+
+	next, err := utils.NextScheduleRun("", 60, from)
+	assert.NoError(t, err)
+	assert.Equal(t, from.Add(time.Hour), next)
+
+	next, err = utils.NextScheduleRun("0 * * * *", 0, from)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 1, 15, 11, 0, 0, 0, time.UTC), next)
+
+	_, err = utils.NextScheduleRun("", 0, from)
+	assert.Error(t, err)
+
+	_, err = utils.NextScheduleRun("0 0 30 2 *", 0, from)
+	assert.Error(t, err)
+}
+
+func TestScheduleMinGap(t *testing.T) {
+	tests := []struct {
+		cron     string
+		interval int
+		want     time.Duration
+	}{
+		{cron: "* * * * *", want: time.Minute},
+		{cron: "*/15 * * * *", want: 15 * time.Minute},
+		{cron: "0,50 * * * *", want: 10 * time.Minute},
+		{cron: "0 9,17 * * 1-5", want: 8 * time.Hour},
+		{cron: "50 23 * * *", want: 24 * time.Hour},
+		{cron: "50 23,0 * * *", want: time.Hour},
+		{cron: "@hourly", want: time.Hour},
+		{interval: 30, want: 30 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		gap, err := utils.ScheduleMinGap(tt.cron, tt.interval)
+		assert.NoError(t, err, tt.cron)
+		assert.Equal(t, tt.want, gap, tt.cron)
+	}
+
+	_, err := utils.ScheduleMinGap("61 * * * *", 0)
+	assert.Error(t, err)
+}
@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models/dto"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCleanDescription(t *testing.T) {
+	html := "<p>Remera de algod&oacute;n&nbsp;100%</p><ul><li>Talles S a XL</li><li>Lavar  a mano</li></ul><br/><br/><br/>"
+
+	assert.Equal(t, "Remera de algodón 100%\n- Talles S a XL\n- Lavar a mano", utils.CleanDescription(html))
+	assert.Equal(t, "Línea 1\nLínea 2", utils.CleanDescription("  Línea 1 \r\n   Línea 2  "))
+	assert.Empty(t, utils.CleanDescription(" <p> </p> "))
+}
+
+func TestTransformMeliItemDescription(t *testing.T) {
+	meliItem := dto.MeliItemResponse{
+		ID:    "MLA1",
+		Title: "Remera",
+		Attributes: []dto.MeliAttribute{
+			{ID: "BRAND", ValueName: "Jopit"},
+		},
+	}
+
+	item, _ := utils.TransformMeliItemToJopitItem(meliItem, nil, utils.MeliTransformConfig{ShopID: "shop-1", UserID: "user-1", BatchID: "batch-1"})
+	assert.Equal(t, "Jopit - Remera", item.Description)
+
+	meliItem.Description = &dto.MeliItemDescription{PlainText: "  "}
+	item, _ = utils.TransformMeliItemToJopitItem(meliItem, nil, utils.MeliTransformConfig{ShopID: "shop-1", UserID: "user-1", BatchID: "batch-1"})
+	assert.Equal(t, "Jopit - Remera", item.Description)
+
+	meliItem.Description = &dto.MeliItemDescription{PlainText: "Remera de algodón.\nTalles S a XL."}
+	item, _ = utils.TransformMeliItemToJopitItem(meliItem, nil, utils.MeliTransformConfig{ShopID: "shop-1", UserID: "user-1", BatchID: "batch-1"})
+	assert.Equal(t, "Remera de algodón.\nTalles S a XL.", item.Description)
+}
@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models/dto"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestItemID(t *testing.T) {
+	id := utils.ItemID("shop-1", models.MeliSourceType, "MLA1")
+
+	assert.Len(t, id, 24)
+	assert.Equal(t, id, utils.ItemID("shop-1", models.MeliSourceType, "MLA1"))
+	assert.NotEqual(t, id, utils.ItemID("shop-2", models.MeliSourceType, "MLA1"))
+	assert.NotEqual(t, id, utils.ItemID("shop-1", models.CsvSourceType, "MLA1"))
+}
+
+func TestTransformMeliItemIsStableAcrossRuns(t *testing.T) {
+	meliItem := dto.MeliItemResponse{ID: "MLA1", Title: "Remera", DomainID: "MLA-T_SHIRTS"}
+	config := utils.MeliTransformConfig{ShopID: "shop-1"}
+
+	first, _ := utils.TransformMeliItemToJopitItem(meliItem, nil, config)
+	second, _ := utils.TransformMeliItemToJopitItem(meliItem, nil, config)
+
+	assert.Equal(t, utils.ItemID("shop-1", models.MeliSourceType, "MLA1"), first.ID)
+	assert.Equal(t, first.ID, second.ID)
+}
+
+func TestReconcileItemIdentity(t *testing.T) {
+	existing := []models.Item{
+		{ID: "legacy-id", Source: &models.Source{SourceType: models.MeliSourceType, ExternalID: "MLA1"}},
+		{ID: "csv-id", Source: &models.Source{SourceType: models.CsvSourceType, ExternalID: "MLA2"}},
+	}
+	items := []models.Item{
+		{ID: "derived-1", Source: &models.Source{SourceType: models.MeliSourceType, ExternalID: "MLA1"}},
+		{ID: "derived-2", Source: &models.Source{SourceType: models.MeliSourceType, ExternalID: "MLA2"}},
+	}
+
+	utils.ReconcileItemIdentity(existing, items)
+
+	assert.Equal(t, "legacy-id", items[0].ID)
+	assert.Equal(t, "derived-2", items[1].ID)
+}
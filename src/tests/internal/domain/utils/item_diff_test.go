@@ -0,0 +1,115 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models/dto"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffItems(t *testing.T) {
+	existing := []models.Item{
+		{
+			ID:       "item-1",
+			Name:     "Remera",
+			Category: models.ItemCategory{ID: "cat-1", Name: "Remeras"},
+			Variants: []models.Variant{},
+			Source:   &models.Source{SourceType: models.MeliSourceType, ExternalID: "MLA1", BatchID: "old"},
+		},
+		{
+			ID:     "item-2",
+			Name:   "Buzo",
+			Source: &models.Source{SourceType: models.MeliSourceType, ExternalID: "MLA2"},
+		},
+	}
+
+	transformed := []models.Item{
+		{
+			Name:     "Remera",
+			Category: models.ItemCategory{Name: "Remeras"},
+			Source:   &models.Source{SourceType: models.MeliSourceType, ExternalID: "MLA1", BatchID: "new"},
+		},
+		{
+			Name:   "Buzo oversize",
+			Source: &models.Source{SourceType: models.MeliSourceType, ExternalID: "MLA2"},
+		},
+		{
+			Name:   "Campera",
+			Source: &models.Source{SourceType: models.MeliSourceType, ExternalID: "MLA3"},
+		},
+	}
+
+	diffs := utils.DiffItems(existing, transformed)
+
+	assert.Len(t, diffs, 3)
+
+	assert.Equal(t, models.ItemDiffUnchanged, diffs[0].Action)
+	assert.Equal(t, "item-1", diffs[0].ItemID)
+	assert.Empty(t, diffs[0].Changes)
+
+	assert.Equal(t, models.ItemDiffUpdate, diffs[1].Action)
+	assert.Equal(t, "item-2", diffs[1].ItemID)
+	assert.Len(t, diffs[1].Changes, 1)
+	assert.Equal(t, "name", diffs[1].Changes[0].Field)
+	assert.Equal(t, "Buzo", diffs[1].Changes[0].Before)
+	assert.Equal(t, "Buzo oversize", diffs[1].Changes[0].After)
+
+	assert.Equal(t, models.ItemDiffCreate, diffs[2].Action)
+	assert.Empty(t, diffs[2].ItemID)
+}
+
+func TestDiffItems_SameMeliItemTransformedTwiceIsUnchanged(t *testing.T) {
+	black, white, red := "52049", "52055", "51993"
+	variation := func(id int64, colorID *string, colorName string, size string) dto.MeliVariation {
+		return dto.MeliVariation{
+			ID:                    id,
+			AvailableQuantity:     2,
+			AttributeCombinations: []dto.MeliAttribute{{ID: "COLOR", ValueID: colorID, ValueName: colorName}, {ID: "SIZE", ValueName: size}},
+		}
+	}
+
+	meliItem := dto.MeliItemResponse{
+		ID:    "MLA1",
+		Title: "Remera",
+		Price: 15000,
+		Variations: []dto.MeliVariation{
+			variation(1, &black, "Negro", "M"),
+			variation(2, &white, "Blanco", "M"),
+			variation(3, &red, "Rojo", "M"),
+			variation(4, &black, "Negro", "L"),
+			variation(5, &white, "Blanco", "L"),
+		},
+	}
+	config := utils.MeliTransformConfig{ShopID: "shop-1", UserID: "user-1", BatchID: "batch-1"}
+
+	existing, _ := utils.TransformMeliItemToJopitItem(meliItem, nil, config)
+	// Items come from the items API without their price
+	existing.Price = models.Price{}
+
+	for i := 0; i < 20; i++ {
+		transformed, _ := utils.TransformMeliItemToJopitItem(meliItem, nil, config)
+
+		diffs := utils.DiffItems([]models.Item{existing}, []models.Item{transformed})
+
+		assert.Len(t, diffs, 1)
+		assert.Equal(t, models.ItemDiffUnchanged, diffs[0].Action)
+		assert.Empty(t, diffs[0].Changes)
+	}
+
+	assert.Equal(t, []string{"52049", "52055", "51993"}, []string{existing.Variants[0].ColorID, existing.Variants[1].ColorID, existing.Variants[2].ColorID})
+	assert.True(t, existing.Variants[0].IsMain)
+}
+
+func TestDiffItems_ReportsPriceChangesOfItemsWithAKnownPrice(t *testing.T) {
+	source := &models.Source{SourceType: models.MeliSourceType, ExternalID: "MLA1"}
+	existing := models.Item{ID: "item-1", Name: "Remera", Price: models.Price{Amount: 100, Currency: models.Currency{ID: "ARS"}}, Source: source}
+	transformed := models.Item{Name: "Remera", Price: models.Price{Amount: 120, Currency: models.Currency{ID: "ARS"}}, Source: source}
+
+	diffs := utils.DiffItems([]models.Item{existing}, []models.Item{transformed})
+
+	assert.Equal(t, models.ItemDiffUpdate, diffs[0].Action)
+	assert.Len(t, diffs[0].Changes, 1)
+	assert.Equal(t, "price", diffs[0].Changes[0].Field)
+}
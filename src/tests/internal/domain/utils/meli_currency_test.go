@@ -0,0 +1,110 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models/dto"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransformMeliItemCurrency(t *testing.T) {
+	tests := []struct {
+		name     string
+		meliItem dto.MeliItemResponse
+		want     models.Currency
+	}{
+		{
+			name:     "uruguayan pesos",
+			meliItem: dto.MeliItemResponse{ID: "MLU1", SiteID: "MLU", Price: 1290, CurrencyID: "UYU"},
+			want:     models.Currency{ID: "UYU", Symbol: "$", DecimalDivider: ",", ThousandsDivider: "."},
+		},
+		{
+			name:     "dollars in uruguay",
+			meliItem: dto.MeliItemResponse{ID: "MLU2", SiteID: "MLU", Price: 35, CurrencyID: "USD"},
+			want:     models.Currency{ID: "USD", Symbol: "U$S", DecimalDivider: ".", ThousandsDivider: ","},
+		},
+		{
+			name:     "site currency when missing",
+			meliItem: dto.MeliItemResponse{ID: "MLC1", SiteID: "MLC", Price: 19990},
+			want:     models.Currency{ID: "CLP", Symbol: "$", DecimalDivider: ",", ThousandsDivider: "."},
+		},
+		{
+			name:     "unknown currency",
+			meliItem: dto.MeliItemResponse{ID: "MLA1", SiteID: "MLA", Price: 10, CurrencyID: "XYZ"},
+			want:     models.Currency{ID: "XYZ", Symbol: "XYZ", DecimalDivider: ".", ThousandsDivider: ","},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item, warnings := utils.TransformMeliItemToJopitItem(tt.meliItem, nil, utils.MeliTransformConfig{ShopID: "shop-1", UserID: "user-1", BatchID: "batch-1"})
+
+			assert.Equal(t, tt.want, item.Price.Currency)
+			assert.Equal(t, tt.meliItem.Price, item.Price.Amount)
+			assert.Equal(t, tt.want.ID, item.Source.TransformMetadata["original_currency"])
+			assert.NotContains(t, item.Source.TransformMetadata, "fx_rate")
+			assert.NotContains(t, warningCodes(warnings), models.TransformWarningMissingFxRate)
+		})
+	}
+}
+
+func TestTransformMeliItemSellerSite(t *testing.T) {
+	meliItem := dto.MeliItemResponse{ID: "MLU1", Title: "Remera", Price: 1290}
+
+	item, _ := utils.TransformMeliItemToJopitItem(meliItem, nil, utils.MeliTransformConfig{ShopID: "shop-1", UserID: "user-1", BatchID: "batch-1", SiteID: "MLU"})
+
+	assert.Equal(t, "UYU", item.Price.Currency.ID)
+	assert.Equal(t, "MLU", item.Source.TransformMetadata["original_site_id"])
+}
+
+func TestTransformMeliItemConvertsToBaseCurrency(t *testing.T) {
+	fxRates, err := utils.NewStaticFxRates(utils.StaticFxRates{Base: "USD", Rates: map[string]float64{"UYU": 40, "CLP": 950}})
+	require.NoError(t, err)
+
+	originalPrice := 1600.0
+	meliItem := dto.MeliItemResponse{
+		ID:            "MLU1",
+		SiteID:        "MLU",
+		Title:         "Remera",
+		Price:         1290,
+		OriginalPrice: &originalPrice,
+		CurrencyID:    "UYU",
+	}
+	config := utils.MeliTransformConfig{
+		ShopID:   "shop-1",
+		UserID:   "user-1",
+		BatchID:  "batch-1",
+		Settings: models.EtlSettings{BaseCurrency: "CLP"},
+		FxRates:  fxRates,
+	}
+
+	item, warnings := utils.TransformMeliItemToJopitItem(meliItem, nil, config)
+
+	assert.NotContains(t, warningCodes(warnings), models.TransformWarningMissingFxRate)
+	assert.Equal(t, "CLP", item.Price.Currency.ID)
+	assert.Equal(t, 30638.0, item.Price.Amount)
+	assert.Equal(t, 38000.0, *item.Price.ListAmount)
+	assert.Equal(t, "UYU", item.Source.TransformMetadata["original_currency"])
+	assert.Equal(t, "1290", item.Source.TransformMetadata["original_amount"])
+	assert.Equal(t, "1600", item.Source.TransformMetadata["original_list_amount"])
+	assert.Equal(t, "23.75", item.Source.TransformMetadata["fx_rate"])
+
+	// Without a rate the item keeps its currency and says why
+	meliItem.CurrencyID = "ARS"
+	item, warnings = utils.TransformMeliItemToJopitItem(meliItem, nil, config)
+
+	assert.Equal(t, "ARS", item.Price.Currency.ID)
+	assert.Equal(t, 1290.0, item.Price.Amount)
+	assert.Contains(t, warningCodes(warnings), models.TransformWarningMissingFxRate)
+}
+
+func warningCodes(warnings []models.TransformWarning) []string {
+	codes := make([]string, 0, len(warnings))
+	for _, warning := range warnings {
+		codes = append(codes, warning.Code)
+	}
+	return codes
+}
@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models/dto"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransformMeliItemPackageDimensions(t *testing.T) {
+	meliItem := dto.MeliItemResponse{
+		ID:    "MLA1",
+		Title: "Remera",
+		Attributes: []dto.MeliAttribute{
+			{ID: "SELLER_PACKAGE_WEIGHT", ValueName: "230 g", Values: []dto.MeliAttributeValue{{Name: "230 g", Struct: map[string]interface{}{"number": 0.23, "unit": "kg"}}}},
+			{ID: "SELLER_PACKAGE_LENGTH", ValueName: "3 cm"},
+			{ID: "SELLER_PACKAGE_HEIGHT", ValueName: "360 mm"},
+			{ID: "SELLER_PACKAGE_WIDTH", ValueName: "11,5 in"},
+		},
+	}
+
+	item, _ := utils.TransformMeliItemToJopitItem(meliItem, nil, utils.MeliTransformConfig{ShopID: "shop-1", UserID: "user-1", BatchID: "batch-1"})
+
+	assert.Equal(t, models.Dimensions{Weight: 230, Length: 3, Height: 36, Width: 30}, item.Delivery.Dimensions)
+	assert.Equal(t, utils.PackageDimensionsSource, item.Source.TransformMetadata["package_dimensions"])
+	assert.Empty(t, item.Source.TransformMetadata["package_dimensions_defaulted"])
+}
+
+func TestTransformMeliItemPackageDimensionsFallbacks(t *testing.T) {
+	meliItem := dto.MeliItemResponse{
+		ID:    "MLA1",
+		Title: "Remera",
+		Attributes: []dto.MeliAttribute{
+			{ID: "SELLER_PACKAGE_WEIGHT", ValueName: "1.2 kg"},
+		},
+		Shipping: dto.MeliShipping{Dimensions: "10x20x30,800"},
+	}
+
+	item, _ := utils.TransformMeliItemToJopitItem(meliItem, nil, utils.MeliTransformConfig{ShopID: "shop-1", UserID: "user-1", BatchID: "batch-1"})
+
+	assert.Equal(t, models.Dimensions{Weight: 1200, Length: 30, Height: 10, Width: 20}, item.Delivery.Dimensions)
+	assert.Equal(t, utils.PackageDimensionsSource, item.Source.TransformMetadata["package_dimensions"])
+
+	meliItem.Shipping = dto.MeliShipping{}
+	settings := models.EtlSettings{DefaultDimensions: &models.Dimensions{Weight: 300, Length: 40, Height: 8, Width: 30}}
+	item, _ = utils.TransformMeliItemToJopitItem(meliItem, nil, utils.MeliTransformConfig{ShopID: "shop-1", UserID: "user-1", BatchID: "batch-1", Settings: settings})
+
+	assert.Equal(t, models.Dimensions{Weight: 1200, Length: 40, Height: 8, Width: 30}, item.Delivery.Dimensions)
+	assert.Equal(t, utils.PackageDimensionsPartial, item.Source.TransformMetadata["package_dimensions"])
+	assert.Equal(t, "length,height,width", item.Source.TransformMetadata["package_dimensions_defaulted"])
+	assert.Equal(t, "shop", item.Source.TransformMetadata["package_dimensions_defaults"])
+
+	meliItem.Attributes = nil
+	item, _ = utils.TransformMeliItemToJopitItem(meliItem, nil, utils.MeliTransformConfig{ShopID: "shop-1", UserID: "user-1", BatchID: "batch-1"})
+
+	assert.Equal(t, models.Dimensions{Weight: 500, Length: 30, Height: 5, Width: 25}, item.Delivery.Dimensions)
+	assert.Equal(t, utils.PackageDimensionsDefaulted, item.Source.TransformMetadata["package_dimensions"])
+	assert.Equal(t, "jopit", item.Source.TransformMetadata["package_dimensions_defaults"])
+}
@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models/dto"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransformMeliItemSalePrice(t *testing.T) {
+	originalPrice := 20000.0
+	startTime := "2026-03-01T03:00:00Z"
+	endTime := "2026-03-15T02:59:59Z"
+
+	meliItem := dto.MeliItemResponse{
+		ID:            "MLA1",
+		Title:         "Remera",
+		Price:         15000,
+		BasePrice:     15000,
+		OriginalPrice: &originalPrice,
+		CurrencyID:    "ARS",
+		DealIDs:       []string{"MLA-DEAL-1"},
+		SalePrices: []dto.MeliItemPrice{
+			{ID: "1", Type: "standard", Amount: 20000},
+			{ID: "2", Type: "promotion", Amount: 15000, Conditions: dto.MeliPriceConditions{ContextRestrictions: []string{"user_type_loyal"}}},
+			{
+				ID:         "3",
+				Type:       "promotion",
+				Amount:     15000,
+				Conditions: dto.MeliPriceConditions{StartTime: &startTime, EndTime: &endTime},
+				Metadata:   map[string]interface{}{"promotion_id": "P-MLA123", "promotion_type": "deal"},
+			},
+		},
+	}
+
+	item, _ := utils.TransformMeliItemToJopitItem(meliItem, nil, utils.MeliTransformConfig{ShopID: "shop-1", UserID: "user-1", BatchID: "batch-1"})
+
+	assert.Equal(t, 15000.0, item.Price.Amount)
+	assert.Equal(t, 20000.0, *item.Price.ListAmount)
+	assert.Equal(t, "P-MLA123", item.Price.Promotion.ID)
+	assert.Equal(t, "deal", item.Price.Promotion.Type)
+	assert.Equal(t, time.Date(2026, 3, 1, 3, 0, 0, 0, time.UTC), *item.Price.Promotion.StartsAt)
+	assert.Equal(t, time.Date(2026, 3, 15, 2, 59, 59, 0, time.UTC), *item.Price.Promotion.EndsAt)
+
+	// Without the item's prices the deal is kept without a window
+	meliItem.SalePrices = nil
+	item, _ = utils.TransformMeliItemToJopitItem(meliItem, nil, utils.MeliTransformConfig{ShopID: "shop-1", UserID: "user-1", BatchID: "batch-1"})
+
+	assert.Equal(t, "MLA-DEAL-1", item.Price.Promotion.ID)
+	assert.Nil(t, item.Price.Promotion.StartsAt)
+}
+
+func TestTransformMeliItemRegularPrice(t *testing.T) {
+	meliItem := dto.MeliItemResponse{ID: "MLA1", Title: "Remera", Price: 15000, BasePrice: 15000, CurrencyID: "ARS"}
+
+	item, _ := utils.TransformMeliItemToJopitItem(meliItem, nil, utils.MeliTransformConfig{ShopID: "shop-1", UserID: "user-1", BatchID: "batch-1"})
+
+	assert.Equal(t, 15000.0, item.Price.Amount)
+	assert.Equal(t, "shop-1", item.Price.ShopID)
+	assert.Nil(t, item.Price.ListAmount)
+	assert.Nil(t, item.Price.Promotion)
+}
+
+func TestIsMeliItemDiscounted(t *testing.T) {
+	higher := 20000.0
+	same := 15000.0
+
+	tests := []struct {
+		name     string
+		meliItem dto.MeliItemResponse
+		want     bool
+	}{
+		{name: "original price above price", meliItem: dto.MeliItemResponse{Price: 15000, OriginalPrice: &higher}, want: true},
+		{name: "base price above price", meliItem: dto.MeliItemResponse{Price: 15000, BasePrice: 20000}, want: true},
+		{name: "original price equal to price", meliItem: dto.MeliItemResponse{Price: 15000, OriginalPrice: &same, BasePrice: 15000}, want: false},
+		{name: "regular price", meliItem: dto.MeliItemResponse{Price: 15000, BasePrice: 15000}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, utils.IsMeliItemDiscounted(tt.meliItem))
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models/dto"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsMeliItemUpdated(t *testing.T) {
+	assert.True(t, utils.IsMeliItemUpdated("2024-01-15T10:30:00.000Z", ""))
+	assert.True(t, utils.IsMeliItemUpdated("2024-01-15T10:30:00.000Z", "2024-01-14T10:30:00.000Z"))
+	assert.False(t, utils.IsMeliItemUpdated("2024-01-15T10:30:00.000Z", "2024-01-15T10:30:00.000Z"))
+	assert.False(t, utils.IsMeliItemUpdated("2024-01-14T10:30:00.000Z", "2024-01-15T10:30:00.000Z"))
+	assert.True(t, utils.IsMeliItemUpdated("not-a-date", "other"))
+}
+
+func TestFilterUpdatedMeliItems(t *testing.T) {
+	items := []dto.MeliItemResponse{
+		{ID: "MLA1", LastUpdated: "2024-01-15T10:30:00.000Z"},
+		{ID: "MLA2", LastUpdated: "2024-01-10T10:30:00.000Z"},
+		{ID: "MLA3", LastUpdated: "2024-01-10T10:30:00.000Z"},
+	}
+	synced := map[string]string{
+		"MLA1": "2024-01-10T10:30:00.000Z",
+		"MLA2": "2024-01-10T10:30:00.000Z",
+	}
+
+	updated, skipped := utils.FilterUpdatedMeliItems(items, synced)
+
+	assert.Equal(t, 1, skipped)
+	assert.Len(t, updated, 2)
+	assert.Equal(t, "MLA1", updated[0].ID)
+	assert.Equal(t, "MLA3", updated[1].ID)
+}
+
+func TestMergeSyncedItems(t *testing.T) {
+	items := []dto.MeliItemResponse{
+		{ID: "MLA1", LastUpdated: "2024-01-15T10:30:00.000Z"},
+		{ID: "MLA2", LastUpdated: "2024-01-15T10:30:00.000Z"},
+		{ID: "MLA3", LastUpdated: "2024-01-15T10:30:00.000Z"},
+	}
+	synced := map[string]string{
+		"MLA2": "2024-01-10T10:30:00.000Z",
+		"MLA9": "2024-01-10T10:30:00.000Z",
+	}
+
+	merged := utils.MergeSyncedItems(items, synced, []string{"MLA1"})
+
+	assert.Equal(t, map[string]string{
+		"MLA1": "2024-01-15T10:30:00.000Z",
+		"MLA2": "2024-01-10T10:30:00.000Z",
+	}, merged)
+}
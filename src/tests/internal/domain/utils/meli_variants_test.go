@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models/dto"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransformMeliItemVariationSKUAndPrice(t *testing.T) {
+	colorID := "52049"
+	inventoryID := "INV-M"
+	customField := "REM-NEG-L"
+	size := func(label string) dto.MeliAttribute { return dto.MeliAttribute{ID: "SIZE", ValueName: label} }
+	color := dto.MeliAttribute{ID: "COLOR", ValueID: &colorID, ValueName: "Negro"}
+
+	meliItem := dto.MeliItemResponse{
+		ID:    "MLA1",
+		Title: "Remera",
+		Price: 15000,
+		Variations: []dto.MeliVariation{
+			{
+				ID:                    1,
+				Price:                 15000,
+				AttributeCombinations: []dto.MeliAttribute{color, size("M")},
+				AvailableQuantity:     3,
+				InventoryID:           &inventoryID,
+				UserProductID:         "MLAU1",
+				Attributes:            []dto.MeliAttribute{{ID: "SELLER_SKU", ValueName: " REM-NEG-M "}},
+			},
+			{
+				ID:                    2,
+				Price:                 16500,
+				AttributeCombinations: []dto.MeliAttribute{color, size("L")},
+				AvailableQuantity:     1,
+				SellerCustomField:     &customField,
+				UserProductID:         "MLAU2",
+			},
+		},
+	}
+
+	item, _ := utils.TransformMeliItemToJopitItem(meliItem, nil, utils.MeliTransformConfig{ShopID: "shop-1", UserID: "user-1", BatchID: "batch-1"})
+
+	large := 16500.0
+	assert.Len(t, item.Variants, 1)
+	assert.Equal(t, []models.SizeStock{
+		{SizeLabel: "M", Stock: 3, SKU: "REM-NEG-M", InventoryID: "INV-M"},
+		{SizeLabel: "L", Stock: 1, SKU: "REM-NEG-L", Price: &large},
+	}, item.Variants[0].SizeStock)
+	assert.Equal(t, "REM-NEG-M", item.Source.ExternalSKU)
+}
+
+func TestTransformMeliItemSKUFromItemAttribute(t *testing.T) {
+	meliItem := dto.MeliItemResponse{
+		ID:         "MLA1",
+		Title:      "Remera",
+		Attributes: []dto.MeliAttribute{{ID: "SELLER_SKU", ValueName: "REM-001"}},
+	}
+
+	item, _ := utils.TransformMeliItemToJopitItem(meliItem, nil, utils.MeliTransformConfig{ShopID: "shop-1", UserID: "user-1", BatchID: "batch-1"})
+
+	assert.Equal(t, "REM-001", item.Source.ExternalSKU)
+}
@@ -0,0 +1,149 @@
+package utils
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		value string
+		want  time.Duration
+		found bool
+	}{
+		{name: "seconds", value: "3", want: 3 * time.Second, found: true},
+		{name: "seconds with spaces", value: " 10 ", want: 10 * time.Second, found: true},
+		{name: "zero seconds", value: "0", want: 0, found: true},
+		{name: "http date", value: now.Add(90 * time.Second).Format(http.TimeFormat), want: 90 * time.Second, found: true},
+		{name: "http date in the past", value: now.Add(-time.Minute).Format(http.TimeFormat), want: 0, found: true},
+		{name: "missing", value: "", found: false},
+		{name: "negative seconds", value: "-5", found: false},
+		{name: "garbage", value: "soon", found: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found := utils.ParseRetryAfter(tt.value, now)
+
+			assert.Equal(t, tt.found, found)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	baseDelay := 500 * time.Millisecond
+	maxDelay := 10 * time.Second
+
+	tests := []struct {
+		name       string
+		retryAfter string
+		attempt    int
+		min        time.Duration
+		max        time.Duration
+		ok         bool
+	}{
+		{name: "retry after seconds", retryAfter: "4", min: 4 * time.Second, max: 4 * time.Second, ok: true},
+		{name: "retry after date", retryAfter: now.Add(2 * time.Second).Format(http.TimeFormat), min: 2 * time.Second, max: 2 * time.Second, ok: true},
+		{name: "retry after at max delay", retryAfter: "10", min: maxDelay, max: maxDelay, ok: true},
+		{name: "retry after above max delay", retryAfter: "60", min: time.Minute, max: time.Minute, ok: false},
+		{name: "first backoff", attempt: 0, min: baseDelay / 2, max: baseDelay, ok: true},
+		{name: "third backoff", attempt: 2, min: 4 * baseDelay / 2, max: 4 * baseDelay, ok: true},
+		{name: "backoff capped at max delay", attempt: 10, min: maxDelay / 2, max: maxDelay, ok: true},
+		{name: "unparsable retry after backs off", retryAfter: "soon", attempt: 1, min: baseDelay, max: 2 * baseDelay, ok: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The backoff is jittered, every run must land within its bounds
+			for i := 0; i < 20; i++ {
+				delay, ok := utils.RetryDelay(tt.retryAfter, now, tt.attempt, baseDelay, maxDelay)
+
+				assert.Equal(t, tt.ok, ok)
+				assert.GreaterOrEqual(t, delay, tt.min)
+				assert.LessOrEqual(t, delay, tt.max)
+			}
+		})
+	}
+}
+
+func TestIsRetryableStatus(t *testing.T) {
+	tests := []struct {
+		status int
+		want   bool
+	}{
+		{status: 0, want: true},
+		{status: http.StatusTooManyRequests, want: true},
+		{status: http.StatusInternalServerError, want: true},
+		{status: http.StatusBadGateway, want: true},
+		{status: http.StatusServiceUnavailable, want: true},
+		{status: http.StatusOK, want: false},
+		{status: http.StatusBadRequest, want: false},
+		{status: http.StatusUnauthorized, want: false},
+		{status: http.StatusNotFound, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			assert.Equal(t, tt.want, utils.IsRetryableStatus(tt.status))
+		})
+	}
+}
+
+func TestTokenBucket(t *testing.T) {
+	t.Run("no limit", func(t *testing.T) {
+		bucket := utils.NewTokenBucket(0)
+
+		assert.Nil(t, bucket)
+		assert.Zero(t, bucket.Reserve())
+		bucket.Pause(time.Second)
+		assert.Zero(t, bucket.Reserve())
+	})
+
+	t.Run("burst of one second", func(t *testing.T) {
+		bucket := utils.NewTokenBucket(10)
+
+		for i := 0; i < 10; i++ {
+			assert.Zero(t, bucket.Reserve(), "call %d is within the burst", i)
+		}
+
+		// The eleventh call waits for the next token, a tenth of a second away
+		delay := bucket.Reserve()
+		assert.Greater(t, delay, 80*time.Millisecond)
+		assert.LessOrEqual(t, delay, 100*time.Millisecond)
+	})
+
+	t.Run("refill", func(t *testing.T) {
+		bucket := utils.NewTokenBucket(100)
+		for i := 0; i < 100; i++ {
+			bucket.Reserve()
+		}
+
+		// 30ms refill at least 3 tokens
+		time.Sleep(30 * time.Millisecond)
+		for i := 0; i < 3; i++ {
+			assert.Zero(t, bucket.Reserve(), "call %d uses a refilled token", i)
+		}
+	})
+
+	t.Run("pause", func(t *testing.T) {
+		bucket := utils.NewTokenBucket(100)
+
+		bucket.Pause(200 * time.Millisecond)
+		delay := bucket.Reserve()
+		assert.Greater(t, delay, 150*time.Millisecond)
+		assert.LessOrEqual(t, delay, 200*time.Millisecond)
+
+		// A shorter pause does not cut the current one
+		bucket.Pause(10 * time.Millisecond)
+		assert.Greater(t, bucket.Reserve(), 150*time.Millisecond)
+	})
+}
@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models/dto"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite the golden files with the current output")
+
+// TestMapSizeGuideGolden maps every chart under testdata/size_charts and compares the guide with its
+// .golden.json file. Run with -update to rewrite the golden files after an intended change.
+func TestMapSizeGuideGolden(t *testing.T) {
+	fixtures, err := filepath.Glob(filepath.Join("testdata", "size_charts", "*.json"))
+	require.NoError(t, err)
+	require.NotEmpty(t, fixtures)
+
+	for _, fixture := range fixtures {
+		if strings.HasSuffix(fixture, ".golden.json") {
+			continue
+		}
+
+		t.Run(strings.TrimSuffix(filepath.Base(fixture), ".json"), func(t *testing.T) {
+			raw, err := os.ReadFile(fixture)
+			require.NoError(t, err)
+
+			var chart dto.MeliSizeChartResponse
+			require.NoError(t, json.Unmarshal(raw, &chart))
+
+			got, err := json.MarshalIndent(utils.MapSizeGuide(chart), "", "  ")
+			require.NoError(t, err)
+
+			golden := strings.TrimSuffix(fixture, ".json") + ".golden.json"
+			if *updateGolden {
+				require.NoError(t, os.WriteFile(golden, append(got, '\n'), 0o644))
+			}
+
+			want, err := os.ReadFile(golden)
+			require.NoError(t, err)
+			assert.JSONEq(t, string(want), string(got))
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package utils
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/models"
+	"github.com/jopitnow/jopit-api-etl/src/main/domain/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransform(t *testing.T) {
+	layout := models.CompanyLayout{
+		ID:     "layout-1",
+		ShopID: "shop-1",
+		ItemMap: map[string]string{
+			"id":    "sku",
+			"name":  "title",
+			"price": "amount",
+		},
+	}
+
+	records := []map[string]string{
+		{"sku": "A1", "title": "Remera", "amount": "1500"},
+		{"sku": "A2", "title": ""},
+	}
+
+	batchID, items, failed := utils.Transform(records, layout, "user-1", models.ApiSourceType)
+
+	assert.True(t, strings.HasPrefix(batchID, "api-"))
+	assert.Len(t, items, 1)
+	assert.Equal(t, utils.ItemID("shop-1", models.ApiSourceType, "A1"), items[0].ID)
+	assert.Equal(t, "Remera", items[0].Name)
+	assert.Equal(t, "user-1", items[0].UserID)
+	assert.Equal(t, float64(1500), items[0].Price.Amount)
+	assert.Equal(t, models.ApiSourceType, items[0].Source.SourceType)
+	assert.Equal(t, "A1", items[0].Source.ExternalID)
+	assert.Equal(t, batchID, items[0].Source.BatchID)
+
+	assert.Len(t, failed, 1)
+	assert.Equal(t, "A2", failed[0].ExternalID)
+	assert.Equal(t, models.EtlStageTransform, failed[0].FailureStage)
+}
+
+// csvFileHeader uploads content as a multipart csv file, as the load handlers receive it
+func csvFileHeader(t *testing.T, content string) *multipart.FileHeader {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("file", "items.csv")
+	require.NoError(t, err)
+	_, err = part.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	form, err := multipart.NewReader(body, writer.Boundary()).ReadForm(1 << 20)
+	require.NoError(t, err)
+	return form.File["file"][0]
+}
+
+func TestExtractFromCSV(t *testing.T) {
+	records, err := utils.ExtractFromCSV(csvFileHeader(t, "sku,title\nA1,Remera\n"))
+
+	require.Nil(t, err)
+	assert.Equal(t, []map[string]string{{"sku": "A1", "title": "Remera"}}, records)
+}
+
+func TestExtractFromCSV_EmptyFile(t *testing.T) {
+	records, err := utils.ExtractFromCSV(csvFileHeader(t, ""))
+
+	require.NotNil(t, err)
+	assert.Equal(t, http.StatusBadRequest, err.Status())
+	assert.Nil(t, records)
+}